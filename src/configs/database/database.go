@@ -3,7 +3,13 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"gin/src/entities/attachments"
+	"gin/src/entities/audits"
 	"gin/src/entities/auth"
+	"gin/src/entities/oauth"
+	"gin/src/entities/organizations"
+	"gin/src/entities/roles"
+	"gin/src/entities/uploads"
 	"gin/src/entities/users"
 	"os"
 	"time"
@@ -83,8 +89,32 @@ func ResetDBUsingGorm(db *gorm.DB) {
 	fmt.Println("⚠️ Dropping all tables....")
 	err := db.Migrator().DropTable(
 		&users.User{},
+		&users.DataExport{},
 		&auth.AccessToken{},
 		&auth.RefreshToken{},
+		&auth.Session{},
+		&auth.PasswordResetToken{},
+		&auth.PasswordHistory{},
+		&auth.MagicLinkToken{},
+		&auth.TwoFactor{},
+		&auth.RecoveryCode{},
+		&auth.LoginAttempt{},
+		&auth.LoginEvent{},
+		&auth.PersonalAccessToken{},
+		&auth.ExternalIdentity{},
+		&auth.OIDCState{},
+		&oauth.Client{},
+		&oauth.AuthorizationCode{},
+		&audits.AuditLog{},
+		&organizations.Organization{},
+		&organizations.Membership{},
+		&organizations.Invitation{},
+		&uploads.Upload{},
+		&attachments.Attachment{},
+		&roles.Role{},
+		&roles.Permission{},
+		&roles.RolePermission{},
+		&roles.UserRole{},
 	)
 	if err != nil {
 		fmt.Println("❌ Failed to drop tables: %w", err)
@@ -95,8 +125,32 @@ func ResetDBUsingGorm(db *gorm.DB) {
 	fmt.Println("🔧 Migrating tables....")
 	err = db.AutoMigrate(
 		&users.User{},
+		&users.DataExport{},
 		&auth.AccessToken{},
 		&auth.RefreshToken{},
+		&auth.Session{},
+		&auth.PasswordResetToken{},
+		&auth.PasswordHistory{},
+		&auth.MagicLinkToken{},
+		&auth.TwoFactor{},
+		&auth.RecoveryCode{},
+		&auth.LoginAttempt{},
+		&auth.LoginEvent{},
+		&auth.PersonalAccessToken{},
+		&auth.ExternalIdentity{},
+		&auth.OIDCState{},
+		&oauth.Client{},
+		&oauth.AuthorizationCode{},
+		&audits.AuditLog{},
+		&organizations.Organization{},
+		&organizations.Membership{},
+		&organizations.Invitation{},
+		&uploads.Upload{},
+		&attachments.Attachment{},
+		&roles.Role{},
+		&roles.Permission{},
+		&roles.RolePermission{},
+		&roles.UserRole{},
 	)
 	if err != nil {
 		fmt.Println("❌ Failed to migrate tables: %w", err)
@@ -139,7 +193,7 @@ func ResetDB(db *sql.DB) {
 	fmt.Println("=== START RESET DB NATIVE MIGRATION ===")
 
 	// Drop tables
-	for _, name := range []string{"access_tokens", "refresh_tokens", "users"} {
+	for _, name := range []string{"access_tokens", "refresh_tokens", "sessions", "password_reset_tokens", "password_histories", "data_exports", "magic_link_tokens", "two_factors", "recovery_codes", "login_attempts", "login_events", "personal_access_tokens", "external_identities", "oidc_states", "oauth_authorization_codes", "oauth_clients", "audit_logs", "attachments", "uploads", "invitations", "memberships", "organizations", "user_roles", "role_permissions", "permissions", "roles", "users"} {
 		dropSQL := fmt.Sprintf(`DROP TABLE IF EXISTS "%s" CASCADE;`, name)
 		if _, err := db.Exec(dropSQL); err != nil {
 			fmt.Println("❌ Drop failed: %w", err)
@@ -149,8 +203,32 @@ func ResetDB(db *sql.DB) {
 	// Create tables
 	createQueries := []string{
 		GenerateCreateTableSQL("users", users.User{}),
+		GenerateCreateTableSQL("data_exports", users.DataExport{}),
 		GenerateCreateTableSQL("access_tokens", auth.AccessToken{}),
 		GenerateCreateTableSQL("refresh_tokens", auth.RefreshToken{}),
+		GenerateCreateTableSQL("sessions", auth.Session{}),
+		GenerateCreateTableSQL("password_reset_tokens", auth.PasswordResetToken{}),
+		GenerateCreateTableSQL("password_histories", auth.PasswordHistory{}),
+		GenerateCreateTableSQL("magic_link_tokens", auth.MagicLinkToken{}),
+		GenerateCreateTableSQL("two_factors", auth.TwoFactor{}),
+		GenerateCreateTableSQL("recovery_codes", auth.RecoveryCode{}),
+		GenerateCreateTableSQL("login_attempts", auth.LoginAttempt{}),
+		GenerateCreateTableSQL("login_events", auth.LoginEvent{}),
+		GenerateCreateTableSQL("personal_access_tokens", auth.PersonalAccessToken{}),
+		GenerateCreateTableSQL("external_identities", auth.ExternalIdentity{}),
+		GenerateCreateTableSQL("oidc_states", auth.OIDCState{}),
+		GenerateCreateTableSQL("oauth_clients", oauth.Client{}),
+		GenerateCreateTableSQL("oauth_authorization_codes", oauth.AuthorizationCode{}),
+		GenerateCreateTableSQL("audit_logs", audits.AuditLog{}),
+		GenerateCreateTableSQL("organizations", organizations.Organization{}),
+		GenerateCreateTableSQL("memberships", organizations.Membership{}),
+		GenerateCreateTableSQL("invitations", organizations.Invitation{}),
+		GenerateCreateTableSQL("uploads", uploads.Upload{}),
+		GenerateCreateTableSQL("attachments", attachments.Attachment{}),
+		GenerateCreateTableSQL("roles", roles.Role{}),
+		GenerateCreateTableSQL("permissions", roles.Permission{}),
+		GenerateCreateTableSQL("role_permissions", roles.RolePermission{}),
+		GenerateCreateTableSQL("user_roles", roles.UserRole{}),
 	}
 
 	for _, q := range createQueries {
@@ -0,0 +1,22 @@
+package security
+
+import "time"
+
+type AccountConfig struct {
+	DeletionGracePeriod time.Duration
+	PurgeInterval       time.Duration
+	ExportTTL           time.Duration
+}
+
+// LoadAccountConfig reads the account deletion and data export settings from the environment variables.
+// A deleted account is anonymised after ACCOUNT_DELETION_GRACE_PERIOD (default 720h), signing in
+// before that cancels the deletion. ACCOUNT_PURGE_INTERVAL is how often due accounts and expired
+// exports are purged (default 1h). DATA_EXPORT_TTL is how long a finished export can be
+// downloaded through its signed /files link (default 24h).
+func LoadAccountConfig() AccountConfig {
+	return AccountConfig{
+		DeletionGracePeriod: durationFromEnv("ACCOUNT_DELETION_GRACE_PERIOD", 30*24*time.Hour),
+		PurgeInterval:       durationFromEnv("ACCOUNT_PURGE_INTERVAL", time.Hour),
+		ExportTTL:           durationFromEnv("DATA_EXPORT_TTL", 24*time.Hour),
+	}
+}
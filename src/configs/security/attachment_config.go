@@ -0,0 +1,46 @@
+package security
+
+import (
+	"os"
+	"strings"
+)
+
+type AttachmentConfig struct {
+	MaxBytes     int64
+	AllowedTypes []string
+}
+
+// LoadAttachmentConfig reads the attachment settings from the environment variables.
+// ATTACHMENT_MAX_BYTES is the largest file accepted (default 25MB). ATTACHMENT_ALLOWED_TYPES is
+// a comma separated list of content types, checked against the sniffed content rather than
+// the type the client sends (default images, PDF, plain text and zip). HTML and scripts are
+// left out on purpose, they would run on the origin of the API.
+func LoadAttachmentConfig() AttachmentConfig {
+	allowed := "image/jpeg,image/png,image/gif,image/webp,application/pdf,text/plain,application/zip"
+	if value := os.Getenv("ATTACHMENT_ALLOWED_TYPES"); value != "" {
+		allowed = value
+	}
+
+	config := AttachmentConfig{
+		MaxBytes: int64(intFromEnv("ATTACHMENT_MAX_BYTES", 25<<20)),
+	}
+	for _, contentType := range strings.Split(allowed, ",") {
+		if contentType = strings.ToLower(strings.TrimSpace(contentType)); contentType != "" {
+			config.AllowedTypes = append(config.AllowedTypes, contentType)
+		}
+	}
+	return config
+}
+
+// Allows reports whether files of the content type may be attached, parameters such as
+// "; charset=utf-8" are ignored.
+func (cfg AttachmentConfig) Allows(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, allowed := range cfg.AllowedTypes {
+		if allowed == mediaType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package security
+
+type AvatarConfig struct {
+	MaxBytes  int64
+	MaxWidth  int
+	MaxHeight int
+}
+
+// LoadAvatarConfig reads the avatar upload limits from the environment variables.
+// AVATAR_MAX_BYTES is the largest accepted file (default 5MB), AVATAR_MAX_WIDTH and
+// AVATAR_MAX_HEIGHT the largest accepted dimensions in pixels (default 4096).
+func LoadAvatarConfig() AvatarConfig {
+	return AvatarConfig{
+		MaxBytes:  int64(intFromEnv("AVATAR_MAX_BYTES", 5<<20)),
+		MaxWidth:  intFromEnv("AVATAR_MAX_WIDTH", 4096),
+		MaxHeight: intFromEnv("AVATAR_MAX_HEIGHT", 4096),
+	}
+}
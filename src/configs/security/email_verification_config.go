@@ -0,0 +1,57 @@
+package security
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	EnforceVerificationNone   = "none"
+	EnforceVerificationLogin  = "login"
+	EnforceVerificationRoutes = "routes"
+)
+
+type EmailVerificationConfig struct {
+	Enforce        string
+	LinkTTL        time.Duration
+	VerifyURL      string
+	ChangeURL      string
+	ResendInterval time.Duration
+}
+
+// LoadEmailVerificationConfig reads the email verification settings from the environment variables.
+// EMAIL_VERIFICATION_ENFORCE controls what an unverified user is blocked from:
+//   - none (default): nothing is blocked.
+//   - login: Login is refused until the email is verified.
+//   - routes: only the routes guarded by middleware.RequireVerifiedEmail are refused.
+//
+// EMAIL_VERIFICATION_TTL is the lifetime of a verification link (default 24h),
+// EMAIL_VERIFICATION_URL is the page of the client app that receives the link parameters and
+// EMAIL_VERIFICATION_RESEND_INTERVAL throttles resending per address (default 1m).
+// EMAIL_CHANGE_URL is the page that receives the link confirming a new email address, it
+// uses the same lifetime as the verification link.
+func LoadEmailVerificationConfig() EmailVerificationConfig {
+	enforce := strings.ToLower(os.Getenv("EMAIL_VERIFICATION_ENFORCE"))
+	if enforce != EnforceVerificationLogin && enforce != EnforceVerificationRoutes {
+		enforce = EnforceVerificationNone
+	}
+
+	verifyURL := os.Getenv("EMAIL_VERIFICATION_URL")
+	if verifyURL == "" {
+		verifyURL = "http://localhost:3000/verify-email"
+	}
+
+	changeURL := os.Getenv("EMAIL_CHANGE_URL")
+	if changeURL == "" {
+		changeURL = "http://localhost:3000/confirm-email-change"
+	}
+
+	return EmailVerificationConfig{
+		Enforce:        enforce,
+		LinkTTL:        durationFromEnv("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		VerifyURL:      verifyURL,
+		ChangeURL:      changeURL,
+		ResendInterval: durationFromEnv("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute),
+	}
+}
@@ -0,0 +1,25 @@
+package security
+
+import "time"
+
+type FileConfig struct {
+	CacheMaxAge     time.Duration
+	SignedURLTTL    time.Duration
+	JanitorInterval time.Duration
+	JanitorGrace    time.Duration
+}
+
+// LoadFileConfig reads the file serving settings from the environment variables.
+// FILE_CACHE_MAX_AGE is how long clients may cache public files (default 24h), uploads get a
+// new key so a replaced avatar is fetched at once. FILE_SIGNED_URL_TTL is how long a signed
+// link to a private file works (default 15m). FILE_JANITOR_INTERVAL is how often stored files
+// no row references are removed (default 24h); files younger than FILE_JANITOR_GRACE (default
+// 1h) are kept, their row may not be written yet.
+func LoadFileConfig() FileConfig {
+	return FileConfig{
+		CacheMaxAge:     durationFromEnv("FILE_CACHE_MAX_AGE", 24*time.Hour),
+		SignedURLTTL:    durationFromEnv("FILE_SIGNED_URL_TTL", 15*time.Minute),
+		JanitorInterval: durationFromEnv("FILE_JANITOR_INTERVAL", 24*time.Hour),
+		JanitorGrace:    durationFromEnv("FILE_JANITOR_GRACE", time.Hour),
+	}
+}
@@ -0,0 +1,15 @@
+package security
+
+import "time"
+
+type ImpersonationConfig struct {
+	TokenTTL time.Duration
+}
+
+// LoadImpersonationConfig reads the admin impersonation settings from the environment variables.
+// IMPERSONATION_TTL is the lifetime of an impersonation access token (default 15m), it cannot be refreshed.
+func LoadImpersonationConfig() ImpersonationConfig {
+	return ImpersonationConfig{
+		TokenTTL: durationFromEnv("IMPERSONATION_TTL", 15*time.Minute),
+	}
+}
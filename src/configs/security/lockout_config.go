@@ -0,0 +1,70 @@
+package security
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+type LockoutConfig struct {
+	MaxAccountFailures int
+	MaxIPFailures      int
+	LockoutDuration    time.Duration
+	BackoffBase        time.Duration
+	BackoffMax         time.Duration
+	FailureWindow      time.Duration
+	UnlockInterval     time.Duration
+}
+
+// LoadLockoutConfig reads the brute-force protection settings from the environment variables.
+// After LOGIN_MAX_ATTEMPTS failed logins of one account (default 5) or LOGIN_MAX_ATTEMPTS_PER_IP
+// failed logins from one IP (default 20) the key is locked for LOGIN_LOCKOUT_DURATION (default 15m).
+// Before that every failure doubles the wait before the next attempt, starting at
+// LOGIN_BACKOFF_BASE (default 1s) up to LOGIN_BACKOFF_MAX (default 1m). Failures older than
+// LOGIN_FAILURE_WINDOW (default 15m) are forgotten. Every LOGIN_UNLOCK_INTERVAL (default 1m)
+// expired locks are released and the account owners are told their account is unlocked.
+func LoadLockoutConfig() LockoutConfig {
+	return LockoutConfig{
+		MaxAccountFailures: intFromEnv("LOGIN_MAX_ATTEMPTS", 5),
+		MaxIPFailures:      intFromEnv("LOGIN_MAX_ATTEMPTS_PER_IP", 20),
+		LockoutDuration:    durationFromEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		BackoffBase:        durationFromEnv("LOGIN_BACKOFF_BASE", time.Second),
+		BackoffMax:         durationFromEnv("LOGIN_BACKOFF_MAX", time.Minute),
+		FailureWindow:      durationFromEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		UnlockInterval:     durationFromEnv("LOGIN_UNLOCK_INTERVAL", time.Minute),
+	}
+}
+
+// Backoff returns how long a key with the given number of failures has to wait
+// before the next attempt: BackoffBase doubled for every failure after the first.
+func (cfg LockoutConfig) Backoff(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+
+	wait := cfg.BackoffBase
+	for i := 1; i < failures && wait < cfg.BackoffMax; i++ {
+		wait *= 2
+	}
+	if wait > cfg.BackoffMax {
+		wait = cfg.BackoffMax
+	}
+	return wait
+}
+
+// intFromEnv parses the environment variable with the given key as a positive integer.
+// It returns the fallback if the variable is empty or invalid.
+func intFromEnv(key string, fallback int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value <= 0 {
+		fmt.Printf("invalid number for %s: %q, using %d\n", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
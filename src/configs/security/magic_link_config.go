@@ -0,0 +1,29 @@
+package security
+
+import (
+	"os"
+	"time"
+)
+
+type MagicLinkConfig struct {
+	TokenTTL       time.Duration
+	LoginURL       string
+	ResendInterval time.Duration
+}
+
+// LoadMagicLinkConfig reads the passwordless login settings from the environment variables.
+// MAGIC_LINK_TTL is the lifetime of a login link (default 15m), MAGIC_LINK_URL is the page of the
+// client app that receives the link parameters and MAGIC_LINK_RESEND_INTERVAL throttles sending
+// links per address (default 1m).
+func LoadMagicLinkConfig() MagicLinkConfig {
+	loginURL := os.Getenv("MAGIC_LINK_URL")
+	if loginURL == "" {
+		loginURL = "http://localhost:3000/login/magic-link"
+	}
+
+	return MagicLinkConfig{
+		TokenTTL:       durationFromEnv("MAGIC_LINK_TTL", 15*time.Minute),
+		LoginURL:       loginURL,
+		ResendInterval: durationFromEnv("MAGIC_LINK_RESEND_INTERVAL", time.Minute),
+	}
+}
@@ -0,0 +1,28 @@
+package security
+
+import (
+	"os"
+	"time"
+)
+
+type MFAConfig struct {
+	Issuer            string
+	ChallengeTTL      time.Duration
+	RecoveryCodeCount int
+}
+
+// LoadMFAConfig reads the two-factor authentication settings from the environment variables.
+// APP_NAME is shown as the issuer in authenticator apps and MFA_CHALLENGE_TTL is the lifetime
+// of the challenge token returned by the password step of the login (default 5m).
+func LoadMFAConfig() MFAConfig {
+	issuer := os.Getenv("APP_NAME")
+	if issuer == "" {
+		issuer = "go-rest"
+	}
+
+	return MFAConfig{
+		Issuer:            issuer,
+		ChallengeTTL:      durationFromEnv("MFA_CHALLENGE_TTL", 5*time.Minute),
+		RecoveryCodeCount: 10,
+	}
+}
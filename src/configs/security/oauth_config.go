@@ -0,0 +1,17 @@
+package security
+
+import "time"
+
+type OAuthConfig struct {
+	CodeTTL time.Duration
+}
+
+// LoadOAuthConfig reads the OAuth2 server settings from the environment variables.
+// OAUTH_CODE_TTL is the lifetime of an authorization code (default 5m). Access and refresh
+// lifetimes of OAuth tokens follow the token config of the web client type, and client
+// credentials tokens use the access lifetime of the service client type.
+func LoadOAuthConfig() OAuthConfig {
+	return OAuthConfig{
+		CodeTTL: durationFromEnv("OAUTH_CODE_TTL", 5*time.Minute),
+	}
+}
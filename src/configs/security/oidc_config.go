@@ -0,0 +1,56 @@
+package security
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+	StateTTL  time.Duration
+}
+
+// LoadOIDCConfig reads the external OpenID Connect providers from the environment variables.
+// OIDC_PROVIDERS is a comma separated list of provider names, and every provider is configured with
+// OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET, OIDC_<NAME>_REDIRECT_URL
+// and optionally OIDC_<NAME>_SCOPES (space separated, default "openid email profile").
+// Providers without issuer or client id are skipped. OIDC_STATE_TTL is how long a login attempt
+// may take at the provider (default 10m).
+func LoadOIDCConfig() OIDCConfig {
+	config := OIDCConfig{
+		StateTTL: durationFromEnv("OIDC_STATE_TTL", 10*time.Minute),
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			continue
+		}
+		config.Providers = append(config.Providers, provider)
+	}
+
+	return config
+}
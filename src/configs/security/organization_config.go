@@ -0,0 +1,27 @@
+package security
+
+import (
+	"os"
+	"time"
+)
+
+type OrganizationConfig struct {
+	InvitationTTL       time.Duration
+	InvitationAcceptURL string
+}
+
+// LoadOrganizationConfig reads the organization invitation settings from the environment variables.
+// ORGANIZATION_INVITATION_TTL is how long an invitation can be accepted (default 168h) and
+// ORGANIZATION_INVITATION_URL is the page of the client app that receives the token as the
+// "token" query parameter.
+func LoadOrganizationConfig() OrganizationConfig {
+	acceptURL := os.Getenv("ORGANIZATION_INVITATION_URL")
+	if acceptURL == "" {
+		acceptURL = "http://localhost:3000/invitations/accept"
+	}
+
+	return OrganizationConfig{
+		InvitationTTL:       durationFromEnv("ORGANIZATION_INVITATION_TTL", 7*24*time.Hour),
+		InvitationAcceptURL: acceptURL,
+	}
+}
@@ -0,0 +1,40 @@
+package security
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+)
+
+type PasswordHashConfig struct {
+	Algorithm         string
+	BcryptCost        int
+	Argon2Memory      int // KiB
+	Argon2Iterations  int
+	Argon2Parallelism int
+}
+
+// LoadPasswordHashConfig reads the password hashing settings from the environment variables.
+// PASSWORD_HASH_ALGORITHM selects the algorithm for new hashes, argon2id (default) or bcrypt.
+// PASSWORD_BCRYPT_COST is the bcrypt cost (default 10), PASSWORD_ARGON2_MEMORY the argon2id
+// memory in KiB (default 65536), PASSWORD_ARGON2_ITERATIONS its time cost (default 3) and
+// PASSWORD_ARGON2_PARALLELISM its threads (default 2). Hashes made with another algorithm or
+// other costs are upgraded on the next successful login.
+func LoadPasswordHashConfig() PasswordHashConfig {
+	algorithm := strings.ToLower(os.Getenv("PASSWORD_HASH_ALGORITHM"))
+	if algorithm != HashBcrypt {
+		algorithm = HashArgon2id
+	}
+
+	return PasswordHashConfig{
+		Algorithm:         algorithm,
+		BcryptCost:        intFromEnv("PASSWORD_BCRYPT_COST", 10),
+		Argon2Memory:      intFromEnv("PASSWORD_ARGON2_MEMORY", 64*1024),
+		Argon2Iterations:  intFromEnv("PASSWORD_ARGON2_ITERATIONS", 3),
+		Argon2Parallelism: intFromEnv("PASSWORD_ARGON2_PARALLELISM", 2),
+	}
+}
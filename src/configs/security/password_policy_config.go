@@ -0,0 +1,31 @@
+package security
+
+import "os"
+
+type PasswordPolicyConfig struct {
+	MinLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	HistorySize      int
+	BreachedListPath string
+}
+
+// LoadPasswordPolicyConfig reads the password policy from the environment variables.
+// PASSWORD_MIN_LENGTH is the minimum length (default 8). PASSWORD_REQUIRE_UPPERCASE,
+// PASSWORD_REQUIRE_LOWERCASE, PASSWORD_REQUIRE_DIGIT and PASSWORD_REQUIRE_SYMBOL enable the
+// character classes when set to "true". A new password may not match the current one or any of
+// the last PASSWORD_HISTORY passwords (default 5). PASSWORD_BREACHED_LIST points to a local list
+// of breached SHA-1 hashes, the check is skipped when it is empty.
+func LoadPasswordPolicyConfig() PasswordPolicyConfig {
+	return PasswordPolicyConfig{
+		MinLength:        intFromEnv("PASSWORD_MIN_LENGTH", 8),
+		RequireUppercase: os.Getenv("PASSWORD_REQUIRE_UPPERCASE") == "true",
+		RequireLowercase: os.Getenv("PASSWORD_REQUIRE_LOWERCASE") == "true",
+		RequireDigit:     os.Getenv("PASSWORD_REQUIRE_DIGIT") == "true",
+		RequireSymbol:    os.Getenv("PASSWORD_REQUIRE_SYMBOL") == "true",
+		HistorySize:      intFromEnv("PASSWORD_HISTORY", 5),
+		BreachedListPath: os.Getenv("PASSWORD_BREACHED_LIST"),
+	}
+}
@@ -0,0 +1,26 @@
+package security
+
+import (
+	"os"
+	"time"
+)
+
+type PasswordResetConfig struct {
+	TokenTTL time.Duration
+	ResetURL string
+}
+
+// LoadPasswordResetConfig reads the password reset settings from the environment variables.
+// PASSWORD_RESET_TTL is the lifetime of a reset token (default 60m) and PASSWORD_RESET_URL
+// is the page of the client app that receives the token as the "token" query parameter.
+func LoadPasswordResetConfig() PasswordResetConfig {
+	resetURL := os.Getenv("PASSWORD_RESET_URL")
+	if resetURL == "" {
+		resetURL = "http://localhost:3000/reset-password"
+	}
+
+	return PasswordResetConfig{
+		TokenTTL: durationFromEnv("PASSWORD_RESET_TTL", time.Hour),
+		ResetURL: resetURL,
+	}
+}
@@ -0,0 +1,80 @@
+package security
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	ClientWeb     = "web"
+	ClientMobile  = "mobile"
+	ClientService = "service"
+)
+
+type TokenLifetime struct {
+	Access  time.Duration
+	Refresh time.Duration
+}
+
+type TokenConfig struct {
+	Lifetimes         map[string]TokenLifetime
+	RememberMeRefresh time.Duration
+	SlidingSession    bool
+	MaxSessionAge     time.Duration
+}
+
+// LoadTokenConfig reads the token lifetimes from the environment variables and returns
+// a TokenConfig instance. Every client type (web, mobile, service) has its own access and
+// refresh lifetime, e.g. ACCESS_TOKEN_TTL_WEB=50m and REFRESH_TOKEN_TTL_WEB=168h.
+// Durations use the time.ParseDuration format. Missing or invalid values fall back to
+// the defaults and a warning message is printed to the console.
+func LoadTokenConfig() TokenConfig {
+	defaults := map[string]TokenLifetime{
+		ClientWeb:     {Access: 50 * time.Minute, Refresh: 7 * 24 * time.Hour},
+		ClientMobile:  {Access: 50 * time.Minute, Refresh: 30 * 24 * time.Hour},
+		ClientService: {Access: 50 * time.Minute, Refresh: 24 * time.Hour},
+	}
+
+	lifetimes := make(map[string]TokenLifetime, len(defaults))
+	for client, def := range defaults {
+		suffix := strings.ToUpper(client)
+		lifetimes[client] = TokenLifetime{
+			Access:  durationFromEnv("ACCESS_TOKEN_TTL_"+suffix, def.Access),
+			Refresh: durationFromEnv("REFRESH_TOKEN_TTL_"+suffix, def.Refresh),
+		}
+	}
+
+	return TokenConfig{
+		Lifetimes:         lifetimes,
+		RememberMeRefresh: durationFromEnv("REMEMBER_ME_REFRESH_TTL", 30*24*time.Hour),
+		SlidingSession:    os.Getenv("SLIDING_SESSION") == "true",
+		MaxSessionAge:     durationFromEnv("MAX_SESSION_AGE", 90*24*time.Hour),
+	}
+}
+
+// LifetimeFor returns the token lifetime of the given client type.
+// Unknown or empty client types use the web lifetime.
+func (cfg TokenConfig) LifetimeFor(clientType string) TokenLifetime {
+	if lifetime, ok := cfg.Lifetimes[clientType]; ok {
+		return lifetime
+	}
+	return cfg.Lifetimes[ClientWeb]
+}
+
+// durationFromEnv parses the environment variable with the given key as a duration.
+// It returns the fallback if the variable is empty or not a valid positive duration.
+func durationFromEnv(key string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil || value <= 0 {
+		fmt.Printf("invalid duration for %s: %q, using %s\n", key, raw, fallback)
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,21 @@
+package security
+
+import "time"
+
+type UploadConfig struct {
+	MaxSize       int64
+	TTL           time.Duration
+	PurgeInterval time.Duration
+}
+
+// LoadUploadConfig reads the resumable upload settings from the environment variables.
+// UPLOAD_MAX_SIZE is the largest Upload-Length accepted (default 100MB), the flow the file
+// is meant for may allow less. An upload must be finished within UPLOAD_TTL (default 24h),
+// UPLOAD_PURGE_INTERVAL is how often expired uploads and their chunks are removed (default 1h).
+func LoadUploadConfig() UploadConfig {
+	return UploadConfig{
+		MaxSize:       int64(intFromEnv("UPLOAD_MAX_SIZE", 100<<20)),
+		TTL:           durationFromEnv("UPLOAD_TTL", 24*time.Hour),
+		PurgeInterval: durationFromEnv("UPLOAD_PURGE_INTERVAL", time.Hour),
+	}
+}
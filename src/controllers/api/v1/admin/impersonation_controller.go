@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"errors"
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Impersonate membuat access token berumur pendek untuk melihat aplikasi sebagai user lain
+func Impersonate(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		adminID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		tokens, err := authService.Impersonate(ctx.Request.Context(), adminID, ctx.Param("uuid"), ctx.ClientIP(), ctx.Request.UserAgent())
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, auth_services.ErrImpersonationNotAllowed) {
+				status = http.StatusForbidden
+			}
+			helpers.ErrorResponse(ctx, err, status)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Impersonation started", gin.H{
+			"token_type":        "Bearer",
+			"access_token":      tokens.AccessToken,
+			"access_expires_at": tokens.AccessExpiresAt,
+		})
+	}
+}
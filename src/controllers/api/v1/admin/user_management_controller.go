@@ -0,0 +1,305 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"gin/src/entities/roles"
+	"gin/src/helpers"
+	"gin/src/services/role_services"
+	services "gin/src/services/user_services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateUserRequest struct {
+	Email         string   `form:"email" json:"email" binding:"required,email"`
+	Username      string   `form:"username" json:"username" binding:"required,min=3,max=255"`
+	Password      string   `form:"password" json:"password" binding:"required"`
+	Roles         []string `form:"roles" json:"roles"`
+	EmailVerified bool     `form:"email_verified" json:"email_verified"`
+}
+
+type UpdateUserRequest struct {
+	Email         *string   `form:"email" json:"email" binding:"omitempty,email"`
+	Username      *string   `form:"username" json:"username" binding:"omitempty,min=3,max=255"`
+	Password      *string   `form:"password" json:"password" binding:"omitempty"`
+	EmailVerified *bool     `form:"email_verified" json:"email_verified"`
+	Roles         *[]string `form:"roles" json:"roles"`
+}
+
+type SuspendUserRequest struct {
+	Reason string     `form:"reason" json:"reason" binding:"required,max=255"`
+	Until  *time.Time `form:"until" json:"until"`
+}
+
+type BanUserRequest struct {
+	Reason string `form:"reason" json:"reason" binding:"required,max=255"`
+}
+
+// ListUsers menampilkan daftar user dengan filter, urutan dan pagination
+func ListUsers(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		page, limit, offset := helpers.GetPaginationParams(ctx)
+
+		userList, total, err := adminService.ListUsers(ctx, limit, offset)
+		if err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", userList, helpers.PaginationMeta{
+			Page:  page,
+			Limit: limit,
+			Total: total,
+		})
+	}
+}
+
+// GetUser menampilkan detail satu user
+func GetUser(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, err := adminService.GetUser(ctx.Request.Context(), ctx.Param("uuid"))
+		if err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", user)
+	}
+}
+
+// CreateUser membuat user baru dari back-office
+func CreateUser(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		adminID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body CreateUserRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		// Memberi role hanya boleh untuk admin yang bisa mengelola role
+		if len(body.Roles) > 0 && !canManageRoles(ctx, adminID) {
+			return
+		}
+
+		user, err := adminService.CreateUser(ctx.Request.Context(), adminID, services.AdminUserInput{
+			Email:         body.Email,
+			Username:      body.Username,
+			Password:      body.Password,
+			Roles:         body.Roles,
+			EmailVerified: body.EmailVerified,
+		})
+		if err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "User created successfully", user)
+	}
+}
+
+// UpdateUser mengubah data user, hanya field yang dikirim yang diubah
+func UpdateUser(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		adminID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body UpdateUserRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		if body.Roles != nil && !canManageRoles(ctx, adminID) {
+			return
+		}
+
+		user, err := adminService.UpdateUser(ctx.Request.Context(), adminID, ctx.Param("uuid"), services.AdminUserUpdate{
+			Email:         body.Email,
+			Username:      body.Username,
+			Password:      body.Password,
+			EmailVerified: body.EmailVerified,
+			Roles:         body.Roles,
+		})
+		if err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "User updated successfully", user)
+	}
+}
+
+// DeleteUser menghapus (menganonimkan) user saat itu juga
+func DeleteUser(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		adminID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if err := adminService.DeleteUser(ctx.Request.Context(), adminID, ctx.Param("uuid")); err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "User deleted successfully", nil)
+	}
+}
+
+// SuspendUser menangguhkan user sampai waktu tertentu atau sampai diaktifkan lagi
+func SuspendUser(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		adminID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body SuspendUserRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		user, err := adminService.SuspendUser(ctx.Request.Context(), adminID, ctx.Param("uuid"), body.Reason, body.Until)
+		if err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "User suspended successfully", user)
+	}
+}
+
+// BanUser memblokir user secara permanen
+func BanUser(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		adminID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body BanUserRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		user, err := adminService.BanUser(ctx.Request.Context(), adminID, ctx.Param("uuid"), body.Reason)
+		if err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "User banned successfully", user)
+	}
+}
+
+// ReactivateUser mencabut penangguhan atau blokir user
+func ReactivateUser(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		adminID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		user, err := adminService.ReactivateUser(ctx.Request.Context(), adminID, ctx.Param("uuid"))
+		if err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "User reactivated successfully", user)
+	}
+}
+
+// ForcePasswordReset menghapus password user dan mengirim link reset password
+func ForcePasswordReset(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		adminID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if err := adminService.ForcePasswordReset(ctx.Request.Context(), adminID, ctx.Param("uuid")); err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Password reset link sent", nil)
+	}
+}
+
+// RevokeUserSessions mengeluarkan user dari semua sesi
+func RevokeUserSessions(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		adminID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if err := adminService.RevokeSessions(ctx.Request.Context(), adminID, ctx.Param("uuid")); err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Sessions revoked successfully", nil)
+	}
+}
+
+// LoginHistory menampilkan riwayat login (berhasil maupun gagal) user
+func LoginHistory(adminService services.AdminUserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		events, err := adminService.LoginHistory(ctx.Request.Context(), ctx.Param("uuid"))
+		if err != nil {
+			userManagementErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", events)
+	}
+}
+
+// canManageRoles memastikan admin punya permission roles.manage, jika tidak response 403 langsung dikirim
+func canManageRoles(ctx *gin.Context, adminID int64) bool {
+	allowed, err := role_services.Default().HasPermission(adminID, roles.PermissionRolesManage)
+	if err != nil {
+		helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+		return false
+	}
+	if !allowed {
+		helpers.ErrorResponse(ctx, fmt.Errorf("missing permission: %s", roles.PermissionRolesManage), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func userManagementErrorResponse(ctx *gin.Context, err error, fallback int) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		helpers.ErrorResponse(ctx, err, http.StatusNotFound)
+	case errors.Is(err, services.ErrUsernameTaken), errors.Is(err, services.ErrEmailTaken):
+		helpers.ErrorResponse(ctx, err, http.StatusConflict)
+	case errors.Is(err, services.ErrActingOnSelf), errors.Is(err, services.ErrPrivilegedUser):
+		helpers.ErrorResponse(ctx, err, http.StatusForbidden)
+	default:
+		helpers.ErrorResponse(ctx, err, fallback)
+	}
+}
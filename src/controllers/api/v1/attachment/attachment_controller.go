@@ -0,0 +1,104 @@
+package attachment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gin/src/helpers"
+	"gin/src/services/attachment_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadAttachment melampirkan file (form field "file") ke owner di URL. Field "metadata"
+// opsional berisi objek JSON string ke string, nama file asli disimpan sebagai "filename"
+func UploadAttachment(attachmentService attachment_services.AttachmentServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		file, header, err := ctx.Request.FormFile("file")
+		if err != nil {
+			helpers.ErrorResponse(ctx, fmt.Errorf("failed to get file from form-data: %w", err), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		metadata := map[string]string{}
+		if raw := ctx.PostForm("metadata"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+				helpers.ErrorResponse(ctx, attachment_services.ErrInvalidMetadata, http.StatusBadRequest)
+				return
+			}
+		}
+		if _, ok := metadata["filename"]; !ok && header.Filename != "" {
+			metadata["filename"] = header.Filename
+		}
+
+		attachment, err := attachmentService.CreateAttachment(ctx.Request.Context(), userID, ctx.Param("owner_type"), ctx.Param("owner_uuid"), file, metadata)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, attachmentErrorStatus(err))
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Attachment uploaded successfully", attachment)
+	}
+}
+
+// GetAttachments menampilkan lampiran milik owner di URL, link file bertanda tangan
+func GetAttachments(attachmentService attachment_services.AttachmentServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		list, err := attachmentService.ListAttachments(ctx.Request.Context(), userID, ctx.Param("owner_type"), ctx.Param("owner_uuid"))
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, attachmentErrorStatus(err))
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", list)
+	}
+}
+
+// DeleteAttachment menghapus lampiran, file ikut terhapus kalau tidak dipakai lampiran lain
+func DeleteAttachment(attachmentService attachment_services.AttachmentServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if err := attachmentService.DeleteAttachment(ctx.Request.Context(), userID, ctx.Param("uuid")); err != nil {
+			helpers.ErrorResponse(ctx, err, attachmentErrorStatus(err))
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Attachment deleted successfully", nil)
+	}
+}
+
+func attachmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, attachment_services.ErrOwnerNotFound), errors.Is(err, attachment_services.ErrAttachmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, attachment_services.ErrNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, attachment_services.ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, attachment_services.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, attachment_services.ErrUnknownOwnerType), errors.Is(err, attachment_services.ErrInvalidMetadata), errors.Is(err, attachment_services.ErrEmptyFile):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
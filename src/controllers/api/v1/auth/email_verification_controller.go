@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VerifyEmailRequest struct {
+	User      string `form:"user" json:"user" binding:"required"`
+	Expires   string `form:"expires" json:"expires" binding:"required"`
+	Signature string `form:"signature" json:"signature" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `form:"email" json:"email" binding:"required,email"`
+}
+
+// VerifyEmail memverifikasi email user menggunakan parameter dari link yang dikirim via email
+func VerifyEmail(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body VerifyEmailRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := authService.VerifyEmail(ctx.Request.Context(), body.User, body.Expires, body.Signature); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Email verified successfully", nil)
+	}
+}
+
+// ResendVerification mengirim ulang link verifikasi email, dibatasi per alamat email
+func ResendVerification(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body ResendVerificationRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := authService.ResendVerificationEmail(ctx.Request.Context(), body.Email); err != nil {
+			serviceErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "If the email is registered and not yet verified, a verification link has been sent", nil)
+	}
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"errors"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serviceErrorResponse maps the known auth service errors to their HTTP status code and
+// sends the error response. Unknown errors are sent with the given fallback status code.
+func serviceErrorResponse(ctx *gin.Context, err error, fallback int) {
+	var throttleErr *auth_services.ThrottleError
+	var lockedErr *auth_services.LockedError
+	var statusErr *users.AccountStatusError
+	switch {
+	case errors.As(err, &throttleErr):
+		setRetryAfter(ctx, throttleErr.RetryAfter)
+		helpers.ErrorResponse(ctx, err, http.StatusTooManyRequests)
+	case errors.As(err, &lockedErr):
+		setRetryAfter(ctx, lockedErr.RetryAfter)
+		helpers.ErrorResponse(ctx, err, http.StatusLocked)
+	case errors.As(err, &statusErr):
+		helpers.ErrorResponse(ctx, err, http.StatusForbidden)
+	case errors.Is(err, auth_services.ErrEmailNotVerified):
+		helpers.ErrorResponse(ctx, err, http.StatusForbidden)
+	case errors.Is(err, auth_services.ErrScopeNotGranted):
+		helpers.ErrorResponse(ctx, err, http.StatusForbidden)
+	case errors.Is(err, auth_services.ErrUnknownOIDCProvider):
+		helpers.ErrorResponse(ctx, err, http.StatusNotFound)
+	case errors.Is(err, auth_services.ErrOIDCAccountConflict):
+		helpers.ErrorResponse(ctx, err, http.StatusConflict)
+	default:
+		helpers.ErrorResponse(ctx, err, fallback)
+	}
+}
+
+// setRetryAfter sets the Retry-After header in whole seconds, rounded up.
+func setRetryAfter(ctx *gin.Context, wait time.Duration) {
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
@@ -9,8 +9,10 @@ import (
 )
 
 type LoginRequest struct {
-	Email    string `form:"email" json:"email" binding:"required,email"`
-	Password string `form:"password" json:"password" binding:"required"`
+	Email      string `form:"email" json:"email" binding:"required,email"`
+	Password   string `form:"password" json:"password" binding:"required"`
+	RememberMe bool   `form:"remember_me" json:"remember_me"`
+	ClientType string `form:"client_type" json:"client_type" binding:"omitempty,oneof=web mobile service"`
 }
 
 type RefreshTokenRequest struct {
@@ -29,9 +31,19 @@ func Login(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
 			return
 		}
 		// Memanggil service untuk login
-		response, err := authService.Login(requestCtx, input.Email, input.Password)
+		response, err := authService.Login(requestCtx, input.Email, input.Password, auth_services.TokenOptions{
+			ClientType: input.ClientType,
+			RememberMe: input.RememberMe,
+			UserAgent:  ctx.Request.UserAgent(),
+			IPAddress:  ctx.ClientIP(),
+		})
 		if err != nil {
-			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			serviceErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if response["mfa_required"] == true {
+			helpers.SuccessResponse(ctx, "Two-factor authentication required", response)
 			return
 		}
 
@@ -54,7 +66,7 @@ func RefreshToken(authService auth_services.AuthServiceInterface) gin.HandlerFun
 		// Memanggil service untuk refresh token
 		tokenResult, err := authService.RefreshToken(requestCtx, body.RefreshToken)
 		if err != nil {
-			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			serviceErrorResponse(ctx, err, http.StatusInternalServerError)
 			return
 		}
 
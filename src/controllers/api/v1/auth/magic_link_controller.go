@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MagicLinkRequest struct {
+	Email string `form:"email" json:"email" binding:"required,email"`
+}
+
+type MagicLinkVerifyRequest struct {
+	Token      string `form:"token" json:"token" binding:"required"`
+	Expires    string `form:"expires" json:"expires" binding:"required"`
+	Signature  string `form:"signature" json:"signature" binding:"required"`
+	RememberMe bool   `form:"remember_me" json:"remember_me"`
+	ClientType string `form:"client_type" json:"client_type" binding:"omitempty,oneof=web mobile service"`
+}
+
+// SendMagicLink mengirim link login sekali pakai ke email user.
+// Response selalu sama supaya tidak membocorkan email mana yang terdaftar.
+func SendMagicLink(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body MagicLinkRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := authService.SendMagicLink(ctx.Request.Context(), body.Email); err != nil {
+			serviceErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "If the email is registered, a login link has been sent", nil)
+	}
+}
+
+// VerifyMagicLink menukar parameter link login dengan access token dan refresh token
+func VerifyMagicLink(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body MagicLinkVerifyRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		response, err := authService.MagicLinkLogin(ctx.Request.Context(), body.Token, body.Expires, body.Signature, auth_services.TokenOptions{
+			ClientType: body.ClientType,
+			RememberMe: body.RememberMe,
+			UserAgent:  ctx.Request.UserAgent(),
+			IPAddress:  ctx.ClientIP(),
+		})
+		if err != nil {
+			serviceErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if response["mfa_required"] == true {
+			helpers.SuccessResponse(ctx, "Two-factor authentication required", response)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Login successful", response)
+	}
+}
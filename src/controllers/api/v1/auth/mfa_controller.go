@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MFACodeRequest struct {
+	Code string `form:"code" json:"code" binding:"required"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `form:"mfa_token" json:"mfa_token" binding:"required"`
+	Code     string `form:"code" json:"code" binding:"required"`
+}
+
+type DisableMFARequest struct {
+	Password string `form:"password" json:"password" binding:"required"`
+	Code     string `form:"code" json:"code" binding:"required"`
+}
+
+// LoginMFA adalah langkah kedua login untuk user dengan TOTP aktif,
+// menukar mfa_token dari langkah password dan kode authenticator dengan access & refresh token
+func LoginMFA(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body MFALoginRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		response, err := authService.VerifyMFALogin(ctx.Request.Context(), body.MFAToken, body.Code, auth_services.TokenOptions{
+			UserAgent: ctx.Request.UserAgent(),
+			IPAddress: ctx.ClientIP(),
+		})
+		if err != nil {
+			serviceErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Login successful", response)
+	}
+}
+
+// EnrollMFA memulai pendaftaran TOTP, mengembalikan secret, otpauth URI dan QR code
+func EnrollMFA(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		enrollment, err := authService.EnrollMFA(ctx.Request.Context(), userID)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Scan the QR code with your authenticator app, then confirm with a code", enrollment)
+	}
+}
+
+// ConfirmMFA mengaktifkan TOTP dengan kode dari authenticator, recovery code hanya ditampilkan sekali
+func ConfirmMFA(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body MFACodeRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		recoveryCodes, err := authService.ConfirmMFA(ctx.Request.Context(), userID, body.Code)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Two-factor authentication enabled, store the recovery codes somewhere safe", gin.H{"recovery_codes": recoveryCodes})
+	}
+}
+
+// DisableMFA menonaktifkan TOTP, membutuhkan password dan kode authenticator atau recovery code
+func DisableMFA(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body DisableMFARequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := authService.DisableMFA(ctx.Request.Context(), userID, body.Password, body.Code); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Two-factor authentication disabled", nil)
+	}
+}
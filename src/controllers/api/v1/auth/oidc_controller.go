@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCCallbackRequest struct {
+	Code       string `form:"code" json:"code" binding:"required"`
+	State      string `form:"state" json:"state" binding:"required"`
+	RememberMe bool   `form:"remember_me" json:"remember_me"`
+	ClientType string `form:"client_type" json:"client_type" binding:"omitempty,oneof=web mobile service"`
+}
+
+// GetOIDCProviders menampilkan daftar provider login eksternal yang dikonfigurasi
+func GetOIDCProviders(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		helpers.SuccessResponse(ctx, "Data found!", authService.OIDCProviders())
+	}
+}
+
+// StartOIDCLogin mengembalikan authorization url provider yang harus dibuka client
+func StartOIDCLogin(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		response, err := authService.StartOIDCLogin(ctx.Request.Context(), ctx.Param("provider"))
+		if err != nil {
+			serviceErrorResponse(ctx, err, http.StatusBadGateway)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Redirect to the login provider", response)
+	}
+}
+
+// OIDCCallback menyelesaikan login provider eksternal menggunakan code dan state dari redirect
+func OIDCCallback(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var input OIDCCallbackRequest
+		if err := ctx.ShouldBind(&input); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		response, err := authService.OIDCLogin(ctx.Request.Context(), ctx.Param("provider"), input.Code, input.State, auth_services.TokenOptions{
+			ClientType: input.ClientType,
+			RememberMe: input.RememberMe,
+			UserAgent:  ctx.Request.UserAgent(),
+			IPAddress:  ctx.ClientIP(),
+		})
+		if err != nil {
+			serviceErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if response["mfa_required"] == true {
+			helpers.SuccessResponse(ctx, "Two-factor authentication required", response)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Login successful", response)
+	}
+}
+
+// GetIdentities menampilkan akun provider eksternal yang tertaut ke user
+func GetIdentities(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		identities, err := authService.ListExternalIdentities(ctx.Request.Context(), userID)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", identities)
+	}
+}
+
+// UnlinkIdentity melepas akun provider eksternal dari user berdasarkan uuid
+func UnlinkIdentity(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if err := authService.UnlinkExternalIdentity(ctx.Request.Context(), userID, ctx.Param("uuid")); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Identity unlinked successfully", nil)
+	}
+}
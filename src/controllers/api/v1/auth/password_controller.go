@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `form:"email" json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token                string `form:"token" json:"token" binding:"required"`
+	Password             string `form:"password" json:"password" binding:"required"`
+	PasswordConfirmation string `form:"password_confirmation" json:"password_confirmation" binding:"required,eqfield=Password"`
+}
+
+// ForgotPassword mengirim link reset password ke email user.
+// Response selalu sama supaya tidak membocorkan email mana yang terdaftar.
+func ForgotPassword(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body ForgotPasswordRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := authService.ForgotPassword(ctx.Request.Context(), body.Email); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "If the email is registered, a password reset link has been sent", nil)
+	}
+}
+
+// ResetPassword mengganti password menggunakan token dari email reset password
+func ResetPassword(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body ResetPasswordRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := authService.ResetPassword(ctx.Request.Context(), body.Token, body.Password); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Password has been reset, please login again", nil)
+	}
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	authEntities "gin/src/entities/auth"
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required,min=1"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1"` // Kosong berarti tidak pernah kedaluwarsa
+}
+
+// GetPersonalAccessTokens menampilkan personal access token aktif milik user
+func GetPersonalAccessTokens(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		tokens, err := authService.ListPersonalAccessTokens(ctx.Request.Context(), userID)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", tokens)
+	}
+}
+
+// CreatePersonalAccessToken membuat personal access token baru, token hanya ditampilkan sekali
+func CreatePersonalAccessToken(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var req CreatePersonalAccessTokenRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		// Token baru tidak boleh melebihi scope token yang dipakai, token sesi tidak dibatasi
+		grantedScopes, scoped := helpers.GetTokenScopes(ctx)
+		if !scoped {
+			grantedScopes = []string{authEntities.ScopeAll}
+		}
+
+		expiresIn := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+		token, err := authService.CreatePersonalAccessToken(ctx.Request.Context(), userID, req.Name, req.Scopes, grantedScopes, expiresIn)
+		if err != nil {
+			serviceErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Personal access token created, copy it now because it will not be shown again", token)
+	}
+}
+
+// RevokePersonalAccessToken mencabut personal access token milik user berdasarkan uuid
+func RevokePersonalAccessToken(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if err := authService.RevokePersonalAccessToken(ctx.Request.Context(), userID, ctx.Param("uuid")); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Personal access token revoked successfully", nil)
+	}
+}
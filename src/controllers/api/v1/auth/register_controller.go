@@ -11,7 +11,7 @@ import (
 type RegisterRequest struct {
 	Email    string `form:"email" json:"email" binding:"required,email"`
 	Username string `form:"username" json:"username" binding:"required"`
-	Password string `form:"password" json:"password" binding:"required,min=8"`
+	Password string `form:"password" json:"password" binding:"required"`
 }
 
 func Register(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
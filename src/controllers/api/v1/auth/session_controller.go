@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSessions menampilkan semua sesi (device) aktif milik user yang sedang login
+func GetSessions(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		sessions, err := authService.ListSessions(ctx.Request.Context(), userID, helpers.GetSessionID(ctx))
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", sessions)
+	}
+}
+
+// RevokeSession mencabut satu sesi milik user berdasarkan uuid
+func RevokeSession(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if err := authService.RevokeSession(ctx.Request.Context(), userID, ctx.Param("uuid")); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Session revoked successfully", nil)
+	}
+}
+
+// LogoutAll mencabut semua access token, refresh token dan sesi milik user
+func LogoutAll(authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if err := authService.LogoutAll(ctx.Request.Context(), userID); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Logged out from all devices", nil)
+	}
+}
@@ -0,0 +1,69 @@
+package file
+
+import (
+	"errors"
+	"fmt"
+	"gin/src/configs/security"
+	"gin/src/helpers"
+	"gin/src/utils/storages"
+	"math"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeFile serves stored files for GET and HEAD /files/*key. Range, If-None-Match,
+// If-Modified-Since and If-Range are handled by http.ServeContent. Private files (private/...)
+// are only served through a signed link that has not expired yet.
+func ServeFile(storage storages.Storage) gin.HandlerFunc {
+	config := security.LoadFileConfig()
+
+	return func(ctx *gin.Context) {
+		key, err := storages.CleanKey(strings.TrimPrefix(ctx.Param("key"), "/"))
+		if err != nil {
+			helpers.ErrorResponse(ctx, storages.ErrNotFound, http.StatusNotFound)
+			return
+		}
+
+		cacheControl := fmt.Sprintf("public, max-age=%d", int64(config.CacheMaxAge.Seconds()))
+		if storages.IsPrivate(key) {
+			expiresAt, ok := storages.VerifySignedURL(key, ctx.Query("expires"), ctx.Query("signature"))
+			if !ok {
+				helpers.ErrorResponse(ctx, errors.New("invalid or expired file link"), http.StatusForbidden)
+				return
+			}
+			// Cache tidak boleh menyimpan file lebih lama dari umur link
+			maxAge := int64(math.Max(0, time.Until(expiresAt).Seconds()))
+			cacheControl = fmt.Sprintf("private, max-age=%d", maxAge)
+		}
+
+		reader, object, err := storage.Get(ctx.Request.Context(), key)
+		if errors.Is(err, storages.ErrNotFound) {
+			helpers.ErrorResponse(ctx, err, http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			helpers.ErrorResponse(ctx, fmt.Errorf("failed to open file: %w", err), http.StatusInternalServerError)
+			return
+		}
+		defer reader.Close()
+
+		contentType := object.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(path.Ext(key))
+		}
+		if contentType != "" {
+			ctx.Header("Content-Type", contentType)
+		}
+		if object.ETag != "" {
+			ctx.Header("ETag", object.ETag)
+		}
+		ctx.Header("Cache-Control", cacheControl)
+
+		http.ServeContent(ctx.Writer, ctx.Request, path.Base(key), object.LastModified, reader)
+	}
+}
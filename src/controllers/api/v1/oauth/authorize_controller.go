@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"gin/src/helpers"
+	"gin/src/services/oauth_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type" binding:"required"`
+	ClientID            string `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+}
+
+func (r AuthorizeRequest) toService() oauth_services.AuthorizationRequest {
+	return oauth_services.AuthorizationRequest{
+		ResponseType:        r.ResponseType,
+		ClientID:            r.ClientID,
+		RedirectURI:         r.RedirectURI,
+		Scope:               r.Scope,
+		State:               r.State,
+		CodeChallenge:       r.CodeChallenge,
+		CodeChallengeMethod: r.CodeChallengeMethod,
+	}
+}
+
+// GetAuthorize memvalidasi authorization request dan mengembalikan data untuk halaman consent
+func GetAuthorize(oauthService oauth_services.OAuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req AuthorizeRequest
+		if err := ctx.ShouldBindQuery(&req); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		prompt, err := oauthService.ValidateAuthorization(ctx.Request.Context(), req.toService())
+		if err != nil {
+			oauthErrorResponse(ctx, err)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Authorization request is valid", prompt)
+	}
+}
+
+// ApproveAuthorize dipanggil saat user menyetujui akses client, mengembalikan redirect_uri berisi code dan state
+func ApproveAuthorize(oauthService oauth_services.OAuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var req AuthorizeRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		redirectURI, err := oauthService.Authorize(ctx.Request.Context(), userID, req.toService())
+		if err != nil {
+			oauthErrorResponse(ctx, err)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Authorization approved", gin.H{"redirect_uri": redirectURI})
+	}
+}
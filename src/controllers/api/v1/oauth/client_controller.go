@@ -0,0 +1,85 @@
+package oauth
+
+import (
+	"gin/src/helpers"
+	"gin/src/services/oauth_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RegisterClientRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes" binding:"required,min=1"`
+	GrantTypes   []string `json:"grant_types"`
+	Confidential bool     `json:"confidential"`
+}
+
+// GetClients menampilkan OAuth client yang didaftarkan user
+func GetClients(oauthService oauth_services.OAuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		clients, err := oauthService.ListClients(ctx.Request.Context(), userID)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", clients)
+	}
+}
+
+// RegisterClient mendaftarkan OAuth client baru, client secret hanya ditampilkan sekali
+func RegisterClient(oauthService oauth_services.OAuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var req RegisterClientRequest
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		client, err := oauthService.RegisterClient(ctx.Request.Context(), userID, oauth_services.ClientRegistration{
+			Name:         req.Name,
+			RedirectURIs: req.RedirectURIs,
+			Scopes:       req.Scopes,
+			GrantTypes:   req.GrantTypes,
+			Confidential: req.Confidential,
+		})
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Client registered, copy the client secret now because it will not be shown again", client)
+	}
+}
+
+// RevokeClient menonaktifkan OAuth client milik user beserta semua tokennya
+func RevokeClient(oauthService oauth_services.OAuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		if err := oauthService.RevokeClient(ctx.Request.Context(), userID, ctx.Param("uuid")); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusNotFound)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Client revoked successfully", nil)
+	}
+}
@@ -0,0 +1,32 @@
+package oauth
+
+import (
+	"errors"
+	"gin/src/services/oauth_services"
+	"gin/src/utils/loggers"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oauthErrorResponse sends the error in the format of RFC 6749 section 5.2 instead of the
+// usual helpers.ErrorResponse, because OAuth clients expect the "error" and "error_description" fields.
+func oauthErrorResponse(ctx *gin.Context, err error) {
+	var oauthErr *oauth_services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		loggers.Log.Error("OAuth server error", map[string]interface{}{
+			"path":  ctx.FullPath(),
+			"error": err.Error(),
+		})
+		oauthErr = &oauth_services.OAuthError{Code: "server_error", Description: "internal server error", Status: http.StatusInternalServerError}
+	}
+
+	if oauthErr.Status == http.StatusUnauthorized {
+		ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	ctx.Header("Cache-Control", "no-store")
+	ctx.JSON(oauthErr.Status, gin.H{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+	})
+}
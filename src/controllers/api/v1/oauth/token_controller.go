@@ -0,0 +1,110 @@
+package oauth
+
+import (
+	"gin/src/services/oauth_services"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TokenRequest struct {
+	GrantType    string `form:"grant_type"`
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type TokenActionRequest struct {
+	Token         string `form:"token"`
+	TokenTypeHint string `form:"token_type_hint"`
+	ClientID      string `form:"client_id"`
+	ClientSecret  string `form:"client_secret"`
+}
+
+// Token adalah token endpoint OAuth2 untuk grant authorization_code, client_credentials dan refresh_token
+func Token(oauthService oauth_services.OAuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req TokenRequest
+		if err := ctx.ShouldBind(&req); err != nil {
+			oauthErrorResponse(ctx, &oauth_services.OAuthError{Code: "invalid_request", Description: err.Error(), Status: http.StatusBadRequest})
+			return
+		}
+
+		tokens, err := oauthService.Token(ctx.Request.Context(), oauth_services.TokenRequest{
+			ClientCredentials: clientCredentials(ctx, req.ClientID, req.ClientSecret),
+			GrantType:         req.GrantType,
+			Code:              req.Code,
+			RedirectURI:       req.RedirectURI,
+			CodeVerifier:      req.CodeVerifier,
+			RefreshToken:      req.RefreshToken,
+			Scope:             req.Scope,
+			UserAgent:         ctx.Request.UserAgent(),
+			IPAddress:         ctx.ClientIP(),
+		})
+		if err != nil {
+			oauthErrorResponse(ctx, err)
+			return
+		}
+
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusOK, tokens)
+	}
+}
+
+// Introspect adalah endpoint token introspection (RFC 7662)
+func Introspect(oauthService oauth_services.OAuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req TokenActionRequest
+		if err := ctx.ShouldBind(&req); err != nil || req.Token == "" {
+			oauthErrorResponse(ctx, &oauth_services.OAuthError{Code: "invalid_request", Description: "token is required", Status: http.StatusBadRequest})
+			return
+		}
+
+		response, err := oauthService.Introspect(ctx.Request.Context(), clientCredentials(ctx, req.ClientID, req.ClientSecret), req.Token)
+		if err != nil {
+			oauthErrorResponse(ctx, err)
+			return
+		}
+
+		ctx.Header("Cache-Control", "no-store")
+		ctx.JSON(http.StatusOK, response)
+	}
+}
+
+// Revoke adalah endpoint token revocation (RFC 7009), token yang tidak dikenal tetap dijawab 200
+func Revoke(oauthService oauth_services.OAuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var req TokenActionRequest
+		if err := ctx.ShouldBind(&req); err != nil || req.Token == "" {
+			oauthErrorResponse(ctx, &oauth_services.OAuthError{Code: "invalid_request", Description: "token is required", Status: http.StatusBadRequest})
+			return
+		}
+
+		if err := oauthService.Revoke(ctx.Request.Context(), clientCredentials(ctx, req.ClientID, req.ClientSecret), req.Token); err != nil {
+			oauthErrorResponse(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// clientCredentials reads the client credentials from the HTTP Basic header (RFC 6749 section 2.3.1),
+// falling back to the client_id and client_secret body parameters.
+func clientCredentials(ctx *gin.Context, clientID string, clientSecret string) oauth_services.ClientCredentials {
+	if username, password, ok := ctx.Request.BasicAuth(); ok {
+		if decoded, err := url.QueryUnescape(username); err == nil {
+			username = decoded
+		}
+		if decoded, err := url.QueryUnescape(password); err == nil {
+			password = decoded
+		}
+		return oauth_services.ClientCredentials{ClientID: username, ClientSecret: password}
+	}
+	return oauth_services.ClientCredentials{ClientID: clientID, ClientSecret: clientSecret}
+}
@@ -0,0 +1,244 @@
+package organization
+
+import (
+	"errors"
+	"gin/src/configs/security"
+	"gin/src/helpers"
+	"gin/src/services/auth_services"
+	"gin/src/services/organization_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateOrganizationRequest struct {
+	Name string `form:"name" json:"name" binding:"required,max=255"`
+}
+
+type InviteMemberRequest struct {
+	Email string `form:"email" json:"email" binding:"required,email"`
+	Role  string `form:"role" json:"role" binding:"omitempty,oneof=admin member"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `form:"token" json:"token" binding:"required"`
+}
+
+// CreateOrganization membuat organisasi baru dengan user sebagai owner
+func CreateOrganization(orgService organization_services.OrganizationServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body CreateOrganizationRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		organization, err := orgService.CreateOrganization(ctx.Request.Context(), userID, body.Name)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Organization created successfully", organization)
+	}
+}
+
+// GetOrganizations menampilkan organisasi tempat user menjadi anggota
+func GetOrganizations(orgService organization_services.OrganizationServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		list, err := orgService.ListOrganizations(ctx.Request.Context(), userID)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", list)
+	}
+}
+
+// IssueOrganizationToken membuat access token dengan claim "org" untuk organisasi tempat user
+// menjadi anggota, sehingga request berikutnya tidak perlu header X-Organization-ID. Token ikut
+// sesi dan scope token yang dipakai, dan tidak bisa di-refresh. API key dan token tanpa sesi
+// ditolak, token organisasinya tidak akan ikut dicabut saat key tersebut dicabut.
+func IssueOrganizationToken(orgService organization_services.OrganizationServiceInterface, authService auth_services.AuthServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		sessionID := helpers.GetSessionID(ctx)
+		if sessionID == 0 {
+			helpers.ErrorResponse(ctx, errors.New("organization tokens can only be issued from a signed-in session, not with an API key"), http.StatusForbidden)
+			return
+		}
+
+		organization, err := orgService.GetOrganizationByUUID(ctx.Request.Context(), userID, ctx.Param("uuid"))
+		if err != nil {
+			organizationErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		scopes, _ := helpers.GetTokenScopes(ctx)
+		tokens, err := authService.GenerateAccessToken(userID, auth_services.TokenOptions{
+			ClientType:    security.ClientWeb,
+			SessionID:     sessionID,
+			OauthClientID: helpers.GetOAuthClientID(ctx),
+			Scopes:        scopes,
+			Organization:  organization.UUID,
+		})
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Organization token created", gin.H{
+			"token_type":        "Bearer",
+			"access_token":      tokens.AccessToken,
+			"access_expires_at": tokens.AccessExpiresAt,
+			"organization":      organization,
+		})
+	}
+}
+
+// GetCurrentOrganization menampilkan organisasi aktif yang dipilih lewat header atau claim token
+func GetCurrentOrganization(orgService organization_services.OrganizationServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		organizationID, err := helpers.GetOrganizationID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		organization, err := orgService.GetOrganization(ctx.Request.Context(), userID, organizationID)
+		if err != nil {
+			organizationErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", organization)
+	}
+}
+
+// GetMembers menampilkan anggota organisasi
+func GetMembers(orgService organization_services.OrganizationServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		members, err := orgService.ListMembers(ctx.Request.Context(), userID, ctx.Param("uuid"))
+		if err != nil {
+			organizationErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", members)
+	}
+}
+
+// InviteMember mengirim undangan bergabung ke organisasi lewat email
+func InviteMember(orgService organization_services.OrganizationServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body InviteMemberRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		invitation, err := orgService.InviteMember(ctx.Request.Context(), userID, ctx.Param("uuid"), body.Email, body.Role)
+		if err != nil {
+			organizationErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Invitation sent", invitation)
+	}
+}
+
+// GetInvitations menampilkan undangan yang belum diterima
+func GetInvitations(orgService organization_services.OrganizationServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		invitations, err := orgService.ListInvitations(ctx.Request.Context(), userID, ctx.Param("uuid"))
+		if err != nil {
+			organizationErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Data found!", invitations)
+	}
+}
+
+// AcceptInvitation menerima undangan dari link di email, user harus login dengan email yang diundang
+func AcceptInvitation(orgService organization_services.OrganizationServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body AcceptInvitationRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		organization, err := orgService.AcceptInvitation(ctx.Request.Context(), userID, body.Token)
+		if err != nil {
+			organizationErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Invitation accepted", organization)
+	}
+}
+
+func organizationErrorResponse(ctx *gin.Context, err error, fallback int) {
+	switch {
+	case errors.Is(err, organization_services.ErrOrganizationNotFound):
+		helpers.ErrorResponse(ctx, err, http.StatusNotFound)
+	case errors.Is(err, organization_services.ErrNotOrganizationAdmin), errors.Is(err, organization_services.ErrInvitationForOtherUser),
+		errors.Is(err, organization_services.ErrEmailNotVerified):
+		helpers.ErrorResponse(ctx, err, http.StatusForbidden)
+	case errors.Is(err, organization_services.ErrAlreadyMember):
+		helpers.ErrorResponse(ctx, err, http.StatusConflict)
+	case errors.Is(err, organization_services.ErrInvalidInvitation):
+		helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+	default:
+		helpers.ErrorResponse(ctx, err, fallback)
+	}
+}
@@ -0,0 +1,183 @@
+package upload
+
+import (
+	"errors"
+	"gin/src/entities/uploads"
+	"gin/src/helpers"
+	"gin/src/services/attachment_services"
+	"gin/src/services/upload_services"
+	"gin/src/utils/images"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination,expiration"
+	chunkType     = "application/offset+octet-stream"
+)
+
+// Options menjelaskan kemampuan server tus, boleh dipanggil tanpa login
+func Options(uploadService upload_services.UploadServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Tus-Resumable", tusVersion)
+		ctx.Header("Tus-Version", tusVersion)
+		ctx.Header("Tus-Extension", tusExtensions)
+		ctx.Header("Tus-Max-Size", strconv.FormatInt(uploadService.MaxSize(), 10))
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// CreateUpload memulai upload baru dengan Upload-Length dan Upload-Metadata (purpose wajib ada)
+func CreateUpload(uploadService upload_services.UploadServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, ok := tusRequest(ctx)
+		if !ok {
+			return
+		}
+
+		if ctx.GetHeader("Upload-Defer-Length") != "" {
+			helpers.ErrorResponse(ctx, errors.New("Upload-Defer-Length is not supported"), http.StatusBadRequest)
+			return
+		}
+		length, err := strconv.ParseInt(ctx.GetHeader("Upload-Length"), 10, 64)
+		if err != nil {
+			helpers.ErrorResponse(ctx, upload_services.ErrInvalidLength, http.StatusBadRequest)
+			return
+		}
+
+		upload, err := uploadService.CreateUpload(ctx.Request.Context(), userID, length, ctx.GetHeader("Upload-Metadata"))
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, uploadErrorStatus(err))
+			return
+		}
+
+		ctx.Header("Location", strings.TrimRight(ctx.Request.URL.Path, "/")+"/"+upload.UUID)
+		ctx.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+		helpers.SuccessResponse(ctx, "Upload created, send the file with PATCH", toUploadResponse(upload))
+	}
+}
+
+// GetUploadOffset mengembalikan offset upload supaya client bisa melanjutkan dari sana
+func GetUploadOffset(uploadService upload_services.UploadServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, ok := tusRequest(ctx)
+		if !ok {
+			return
+		}
+
+		upload, err := uploadService.GetUpload(ctx.Request.Context(), userID, ctx.Param("uuid"))
+		if err != nil {
+			ctx.Status(uploadErrorStatus(err))
+			return
+		}
+
+		ctx.Header("Cache-Control", "no-store")
+		ctx.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		ctx.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+		ctx.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+		if upload.Metadata != "" {
+			ctx.Header("Upload-Metadata", upload.Metadata)
+		}
+		ctx.Status(http.StatusOK)
+	}
+}
+
+// UploadChunk menyimpan potongan file mulai dari Upload-Offset, potongan terakhir
+// langsung diteruskan ke proses sesuai purpose (misalnya avatar)
+func UploadChunk(uploadService upload_services.UploadServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, ok := tusRequest(ctx)
+		if !ok {
+			return
+		}
+
+		if ctx.ContentType() != chunkType {
+			helpers.ErrorResponse(ctx, errors.New("Content-Type must be "+chunkType), http.StatusUnsupportedMediaType)
+			return
+		}
+		offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+		if err != nil || offset < 0 {
+			helpers.ErrorResponse(ctx, errors.New("Upload-Offset must be a number"), http.StatusBadRequest)
+			return
+		}
+
+		upload, err := uploadService.WriteChunk(ctx.Request.Context(), userID, ctx.Param("uuid"), offset, ctx.Request.Body)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, uploadErrorStatus(err))
+			return
+		}
+
+		ctx.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		ctx.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// TerminateUpload membatalkan upload dan menghapus potongan yang sudah diterima
+func TerminateUpload(uploadService upload_services.UploadServiceInterface) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, ok := tusRequest(ctx)
+		if !ok {
+			return
+		}
+
+		if err := uploadService.TerminateUpload(ctx.Request.Context(), userID, ctx.Param("uuid")); err != nil {
+			helpers.ErrorResponse(ctx, err, uploadErrorStatus(err))
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// tusRequest memeriksa header Tus-Resumable dan mengambil user yang login
+func tusRequest(ctx *gin.Context) (int64, bool) {
+	ctx.Header("Tus-Resumable", tusVersion)
+	if ctx.GetHeader("Tus-Resumable") != tusVersion {
+		ctx.Header("Tus-Version", tusVersion)
+		helpers.ErrorResponse(ctx, errors.New("unsupported Tus-Resumable version"), http.StatusPreconditionFailed)
+		return 0, false
+	}
+
+	userID, err := helpers.GetUserID(ctx)
+	if err != nil {
+		helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+		return 0, false
+	}
+	return userID, true
+}
+
+func uploadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, upload_services.ErrUploadNotFound), errors.Is(err, attachment_services.ErrOwnerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, upload_services.ErrUploadExpired):
+		return http.StatusGone
+	case errors.Is(err, upload_services.ErrOffsetMismatch), errors.Is(err, upload_services.ErrUploadCompleted):
+		return http.StatusConflict
+	case errors.Is(err, upload_services.ErrUploadTooLarge), errors.Is(err, images.ErrTooLarge), errors.Is(err, attachment_services.ErrFileTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, images.ErrUnsupportedType), errors.Is(err, attachment_services.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, upload_services.ErrInvalidLength), errors.Is(err, upload_services.ErrUnknownPurpose), errors.Is(err, upload_services.ErrInvalidMetadata),
+		errors.Is(err, images.ErrDimensionsTooLarge), errors.Is(err, images.ErrInvalidImage),
+		errors.Is(err, attachment_services.ErrUnknownOwnerType), errors.Is(err, attachment_services.ErrInvalidMetadata), errors.Is(err, attachment_services.ErrEmptyFile):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func toUploadResponse(upload *uploads.Upload) uploads.UploadResponse {
+	return uploads.UploadResponse{
+		UUID:        upload.UUID,
+		Purpose:     upload.Purpose,
+		Length:      upload.Length,
+		Offset:      upload.Offset,
+		ExpiresAt:   upload.ExpiresAt,
+		CompletedAt: upload.CompletedAt,
+	}
+}
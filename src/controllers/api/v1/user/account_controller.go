@@ -0,0 +1,145 @@
+package user
+
+import (
+	"errors"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	services "gin/src/services/user_services"
+	"gin/src/utils/storages"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateProfileRequest struct {
+	Username string `form:"username" json:"username" binding:"required,min=3,max=255"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword      string `form:"current_password" json:"current_password" binding:"required"`
+	Password             string `form:"password" json:"password" binding:"required"`
+	PasswordConfirmation string `form:"password_confirmation" json:"password_confirmation" binding:"required,eqfield=Password"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `form:"email" json:"email" binding:"required,email"`
+	Password string `form:"password" json:"password" binding:"required"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	User      string `form:"user" json:"user" binding:"required"`
+	Email     string `form:"email" json:"email" binding:"required,email"`
+	Expires   string `form:"expires" json:"expires" binding:"required"`
+	Signature string `form:"signature" json:"signature" binding:"required"`
+}
+
+// UpdateProfile mengubah username user yang sedang login
+func UpdateProfile(userService services.UserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body UpdateProfileRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		user, err := userService.UpdateProfile(ctx.Request.Context(), userID, body.Username)
+		if err != nil {
+			serviceErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Profile updated successfully", users.ProfileResponse{
+			UUID:          user.UUID,
+			ID:            user.ID,
+			Email:         user.Email,
+			Username:      user.Username,
+			Avatar:        user.Avatar.URLs(storages.Default().URL),
+			EmailVerified: user.IsEmailVerified(),
+		})
+	}
+}
+
+// ChangePassword mengganti password user, sesi lain milik user ikut dicabut
+func ChangePassword(userService services.UserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body ChangePasswordRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		err = userService.ChangePassword(ctx.Request.Context(), userID, helpers.GetSessionID(ctx), body.CurrentPassword, body.Password)
+		if err != nil {
+			serviceErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Password changed successfully, other sessions have been signed out", nil)
+	}
+}
+
+// ChangeEmail mengirim link konfirmasi ke alamat email baru,
+// email baru dipakai setelah link dikonfirmasi
+func ChangeEmail(userService services.UserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body ChangeEmailRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := userService.RequestEmailChange(ctx.Request.Context(), userID, body.Email, body.Password); err != nil {
+			serviceErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "A confirmation link has been sent to the new email address", nil)
+	}
+}
+
+// ConfirmEmailChange mengganti email user menggunakan parameter link konfirmasi
+func ConfirmEmailChange(userService services.UserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body ConfirmEmailChangeRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		if err := userService.ConfirmEmailChange(ctx.Request.Context(), body.User, body.Email, body.Expires, body.Signature); err != nil {
+			serviceErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Email changed successfully", nil)
+	}
+}
+
+// serviceErrorResponse maps the known user service errors to their HTTP status code and
+// sends the error response. Unknown errors are sent with the given fallback status code.
+func serviceErrorResponse(ctx *gin.Context, err error, fallback int) {
+	switch {
+	case errors.Is(err, services.ErrUsernameTaken), errors.Is(err, services.ErrEmailTaken):
+		helpers.ErrorResponse(ctx, err, http.StatusConflict)
+	default:
+		helpers.ErrorResponse(ctx, err, fallback)
+	}
+}
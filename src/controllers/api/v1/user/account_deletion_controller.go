@@ -0,0 +1,63 @@
+package user
+
+import (
+	"gin/src/helpers"
+	services "gin/src/services/user_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeleteAccountRequest struct {
+	Password string `form:"password" json:"password" binding:"required"`
+}
+
+// DeleteAccount menjadwalkan penghapusan akun user yang sedang login,
+// semua sesi langsung dicabut dan data pribadi dianonimkan setelah masa tenggang
+func DeleteAccount(userService services.UserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		var body DeleteAccountRequest
+		if err := ctx.ShouldBind(&body); err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		response, err := userService.DeleteAccount(ctx.Request.Context(), userID, body.Password)
+		if err != nil {
+			serviceErrorResponse(ctx, err, http.StatusBadRequest)
+			return
+		}
+
+		helpers.SuccessResponse(ctx, "Account scheduled for deletion, sign in again before the date to cancel", response)
+	}
+}
+
+// ExportData mengembalikan status export data user, export baru dibuat di background
+// dan link download tersedia setelah statusnya ready
+func ExportData(userService services.UserService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		userID, err := helpers.GetUserID(ctx)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusUnauthorized)
+			return
+		}
+
+		response, err := userService.RequestDataExport(ctx.Request.Context(), userID)
+		if err != nil {
+			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			return
+		}
+
+		message := "Your data export is being prepared, check again later"
+		if response.DownloadURL != "" {
+			message = "Your data export is ready"
+		}
+		helpers.SuccessResponse(ctx, message, response)
+	}
+}
@@ -1,10 +1,13 @@
 package user
 
 import (
+	"errors"
 	"fmt"
 	"gin/src/entities/users"
 	"gin/src/helpers"
 	services "gin/src/services/user_services"
+	"gin/src/utils/images"
+	"gin/src/utils/storages"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -26,9 +29,12 @@ func GetProfile(ctx *gin.Context) {
 	}
 
 	response := users.ProfileResponse{
-		ID:       user.ID,
-		Email:    user.Email,
-		Username: user.Username,
+		UUID:          user.UUID,
+		ID:            user.ID,
+		Email:         user.Email,
+		Username:      user.Username,
+		Avatar:        user.Avatar.URLs(storages.Default().URL),
+		EmailVerified: user.IsEmailVerified(),
 	}
 
 	helpers.SuccessResponse(ctx, "Data Found!", response)
@@ -50,10 +56,12 @@ func GetAllUsers(service services.UserService) gin.HandlerFunc {
 		var response []users.ProfileResponse
 		for _, u := range userList {
 			response = append(response, users.ProfileResponse{
-				UUID:     u.UUID,
-				ID:       u.ID,
-				Email:    u.Email,
-				Username: u.Username,
+				UUID:          u.UUID,
+				ID:            u.ID,
+				Email:         u.Email,
+				Username:      u.Username,
+				Avatar:        u.Avatar.URLs(storages.Default().URL),
+				EmailVerified: u.IsEmailVerified(),
 			})
 		}
 
@@ -94,15 +102,30 @@ func UploadAvatar(userService services.UserService) gin.HandlerFunc {
 			helpers.ErrorResponse(ctx, fmt.Errorf("failed to get file from form-data: %w", err), http.StatusBadRequest)
 			return
 		}
+		defer file.Close()
 
-		// Call the service to upload the file and get the URL
-		avatarURL, err := userService.UploadAvatar(ctx, userIDInt64, file, "avatars")
+		// Call the service to validate the image and store its variants
+		avatar, err := userService.UploadAvatar(ctx.Request.Context(), userIDInt64, file, "avatars")
 		if err != nil {
-			helpers.ErrorResponse(ctx, err, http.StatusInternalServerError)
+			helpers.ErrorResponse(ctx, err, avatarErrorStatus(err))
 			return
 		}
 
 		// Return success response
-		helpers.SuccessResponse(ctx, "Avatar uploaded successfully", gin.H{"avatar_url": avatarURL})
+		helpers.SuccessResponse(ctx, "Avatar uploaded successfully", gin.H{"avatar_url": avatar.Original(), "avatar": avatar})
+	}
+}
+
+// avatarErrorStatus maps the image validation errors to 413 or 415 and 400, anything else is a server error.
+func avatarErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, images.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, images.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, images.ErrDimensionsTooLarge), errors.Is(err, images.ErrInvalidImage):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
 	}
 }
@@ -0,0 +1,94 @@
+package attachments
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Owner types files can be attached to. Every type needs an owner policy registered with the
+// attachment service, which resolves the owner and decides who may use its attachments.
+const (
+	OwnerUser         = "user"
+	OwnerOrganization = "organization"
+)
+
+// storagePrefix keeps attachments private, they are only served through signed links.
+const storagePrefix = "private/attachments/"
+
+// Attachment is a file attached to a row of any table, identified by OwnerType and OwnerID.
+// Files are stored once per content: rows with the same Checksum share the StorageKey.
+type Attachment struct {
+	UUID       string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID         int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	OwnerType  string    `gorm:"size:50;not null;index:idx_attachments_owner" db:"owner_type" json:"owner_type"`
+	OwnerID    int64     `gorm:"not null;index:idx_attachments_owner" db:"owner_id" json:"owner_id"`
+	UserID     int64     `gorm:"not null;index" db:"user_id" json:"user_id"` // User that uploaded the file
+	StorageKey string    `gorm:"size:512;not null" db:"storage_key" json:"storage_key"`
+	MimeType   string    `gorm:"size:255;not null" db:"mime_type" json:"mime_type"` // sniffed from the content
+	Size       int64     `gorm:"not null" db:"size" json:"size"`
+	Checksum   string    `gorm:"size:64;not null;index" db:"checksum" json:"checksum"` // hex SHA-256 of the content
+	Metadata   Metadata  `gorm:"type:text" db:"metadata" json:"metadata"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+// StorageKey returns the key of the file with the given checksum, so identical content always
+// ends up under the same key.
+func StorageKey(checksum string, extension string) string {
+	return storagePrefix + checksum[:2] + "/" + checksum + extension
+}
+
+// Metadata holds free form details about the file, such as the "filename" it was uploaded
+// with. It is stored as JSON in the metadata column.
+type Metadata map[string]string
+
+func (m Metadata) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(map[string]string(m))
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+func (m *Metadata) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("cannot scan %T into Metadata", value)
+	}
+
+	if len(raw) == 0 {
+		*m = nil
+		return nil
+	}
+	var metadata map[string]string
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return fmt.Errorf("invalid attachment metadata: %w", err)
+	}
+	*m = metadata
+	return nil
+}
+
+type AttachmentResponse struct {
+	UUID      string    `json:"uuid"`
+	OwnerType string    `json:"owner_type"`
+	OwnerUUID string    `json:"owner_uuid"`
+	MimeType  string    `json:"mime_type"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum"`
+	Metadata  Metadata  `json:"metadata"`
+	URL       string    `json:"url"` // signed link, valid for FILE_SIGNED_URL_TTL
+	CreatedAt time.Time `json:"created_at"`
+}
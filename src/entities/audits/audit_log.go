@@ -0,0 +1,35 @@
+package audits
+
+import "time"
+
+const (
+	EventImpersonationStarted = "impersonation.started"
+	EventImpersonatedRequest  = "impersonation.request"
+
+	EventAdminUserCreated     = "admin.user.created"
+	EventAdminUserUpdated     = "admin.user.updated"
+	EventAdminUserDeleted     = "admin.user.deleted"
+	EventAdminUserSuspended   = "admin.user.suspended"
+	EventAdminUserBanned      = "admin.user.banned"
+	EventAdminUserReactivated = "admin.user.reactivated"
+	EventAdminPasswordReset   = "admin.user.password_reset"
+	EventAdminSessionsRevoked = "admin.user.sessions_revoked"
+)
+
+// AuditLog records a security relevant action. ActorID is the user who really performed it and
+// UserID the user it was performed on or as, they differ while an admin impersonates a user.
+type AuditLog struct {
+	UUID      string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID        int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	Event     string    `gorm:"size:100;not null;index" db:"event" json:"event"`
+	ActorID   int64     `gorm:"index" db:"actor_id" json:"actor_id"`
+	UserID    int64     `gorm:"index" db:"user_id" json:"user_id"`
+	Method    string    `gorm:"size:10" db:"method" json:"method"`
+	Path      string    `gorm:"size:2048" db:"path" json:"path"`
+	Status    int       `db:"status" json:"status"`
+	IPAddress string    `gorm:"size:45" db:"ip_address" json:"ip_address"`
+	UserAgent string    `gorm:"size:512" db:"user_agent" json:"user_agent"`
+	Details   string    `gorm:"type:text" db:"details" json:"details"` // JSON encoded extra data
+	CreatedAt time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
@@ -6,13 +6,27 @@ import (
 )
 
 type AccessToken struct {
-	UUID      string      `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
-	ID        int64       `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
-	UserID    int64       `gorm:"not null;index" db:"user_id" json:"user_id"`
-	User      *users.User `gorm:"foreignKey:UserID" db:"-" json:"user"`
-	Token     string      `gorm:"uniqueIndex" db:"token" json:"token"`
-	ExpiresAt time.Time   `db:"expires_at" json:"expires_at"`
-	Revoked   bool        `gorm:"default:false" db:"revoked" json:"revoked"`
-	CreatedAt time.Time   `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
-	UpdatedAt time.Time   `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+	UUID           string      `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID             int64       `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID         int64       `gorm:"not null;index" db:"user_id" json:"user_id"`
+	User           *users.User `gorm:"foreignKey:UserID" db:"-" json:"user"`
+	SessionID      int64       `gorm:"index" db:"session_id" json:"session_id"`
+	Token          string      `gorm:"uniqueIndex" db:"token" json:"token"`
+	OauthClientID  int64       `gorm:"index" db:"oauth_client_id" json:"oauth_client_id"` // Filled when issued to an OAuth client
+	Scopes         string      `gorm:"size:512" db:"scopes" json:"scopes"`                // Comma separated, empty means not scoped
+	ImpersonatorID int64       `gorm:"index" db:"impersonator_id" json:"impersonator_id"` // Admin acting as the user, 0 for normal tokens
+	ExpiresAt      time.Time   `db:"expires_at" json:"expires_at"`
+	Revoked        bool        `gorm:"default:false" db:"revoked" json:"revoked"`
+	CreatedAt      time.Time   `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time   `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+// ScopeList returns the scopes of the token as a slice.
+func (t *AccessToken) ScopeList() []string {
+	return SplitScopes(t.Scopes)
+}
+
+// IsScoped reports whether the token is limited to its scopes, which is the case for tokens issued to OAuth clients.
+func (t *AccessToken) IsScoped() bool {
+	return t.OauthClientID != 0 || t.Scopes != ""
 }
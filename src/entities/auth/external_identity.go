@@ -0,0 +1,46 @@
+package auth
+
+import "time"
+
+// ExternalIdentity links a user to an account at an external OpenID Connect provider.
+// A user may link several identities, one provider account belongs to a single user.
+type ExternalIdentity struct {
+	UUID      string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID        int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID    int64     `gorm:"not null;index" db:"user_id" json:"user_id"`
+	Provider  string    `gorm:"size:50;not null;uniqueIndex:idx_provider_subject" db:"provider" json:"provider"`
+	Subject   string    `gorm:"size:255;not null;uniqueIndex:idx_provider_subject" db:"subject" json:"subject"` // "sub" claim of the ID token
+	Email     string    `gorm:"size:255" db:"email" json:"email"`
+	CreatedAt time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+func (ExternalIdentity) TableName() string {
+	return "external_identities"
+}
+
+type ExternalIdentityResponse struct {
+	UUID      string    `json:"uuid"`
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OIDCState remembers a login started at an external provider until the provider redirects back.
+// The state itself is only stored hashed, the nonce and PKCE verifier never leave the server.
+type OIDCState struct {
+	UUID         string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID           int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	StateHash    string    `gorm:"size:64;uniqueIndex;not null" db:"state_hash" json:"-"`
+	Provider     string    `gorm:"size:50;not null" db:"provider" json:"provider"`
+	Nonce        string    `gorm:"size:64;not null" db:"nonce" json:"-"`
+	CodeVerifier string    `gorm:"size:128;not null" db:"code_verifier" json:"-"`
+	ExpiresAt    time.Time `gorm:"not null" db:"expires_at" json:"expires_at"`
+	Used         bool      `gorm:"default:false" db:"used" json:"used"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+func (OIDCState) TableName() string {
+	return "oidc_states"
+}
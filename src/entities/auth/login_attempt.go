@@ -0,0 +1,21 @@
+package auth
+
+import "time"
+
+// LoginAttempt tracks consecutive failed logins of one key, either an account
+// ("account:<email>") or a client IP ("ip:<address>").
+type LoginAttempt struct {
+	UUID         string     `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID           int64      `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	AttemptKey   string     `gorm:"size:320;uniqueIndex;not null" db:"attempt_key" json:"attempt_key"`
+	Failures     int        `gorm:"default:0" db:"failures" json:"failures"`
+	LastFailedAt time.Time  `db:"last_failed_at" json:"last_failed_at"`
+	LockedUntil  *time.Time `db:"locked_until" json:"locked_until"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+// IsLocked reports whether the key is locked at the given time.
+func (a *LoginAttempt) IsLocked(now time.Time) bool {
+	return a.LockedUntil != nil && now.Before(*a.LockedUntil)
+}
@@ -0,0 +1,25 @@
+package auth
+
+import "time"
+
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodMFA       = "mfa"
+	LoginMethodMagicLink = "magic_link"
+	LoginMethodOIDC      = "oidc"
+)
+
+// LoginEvent records one successful or failed sign-in of a user, it makes up the login
+// history shown to admins.
+type LoginEvent struct {
+	UUID      string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID        int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID    int64     `gorm:"not null;index" db:"user_id" json:"user_id"`
+	Method    string    `gorm:"size:20" db:"method" json:"method"`
+	Success   bool      `gorm:"default:false" db:"success" json:"success"`
+	Reason    string    `gorm:"size:255" db:"reason" json:"reason"` // why a failed login was refused
+	IPAddress string    `gorm:"size:64" db:"ip_address" json:"ip_address"`
+	UserAgent string    `gorm:"size:512" db:"user_agent" json:"user_agent"`
+	CreatedAt time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package auth
+
+import "time"
+
+// PasswordHistory keeps the hash of a password a user has set, so the password policy can
+// refuse reusing recent passwords.
+type PasswordHistory struct {
+	UUID         string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID           int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID       int64     `gorm:"not null;index" db:"user_id" json:"user_id"`
+	PasswordHash string    `gorm:"not null" db:"password_hash" json:"-"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
@@ -0,0 +1,14 @@
+package auth
+
+import "time"
+
+type PasswordResetToken struct {
+	UUID      string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID        int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID    int64     `gorm:"not null;index" db:"user_id" json:"user_id"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null" db:"token_hash" json:"-"` // SHA-256 of the token sent by email
+	ExpiresAt time.Time `gorm:"not null" db:"expires_at" json:"expires_at"`
+	Used      bool      `gorm:"default:false" db:"used" json:"used"`
+	CreatedAt time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,42 @@
+package auth
+
+import "time"
+
+type PersonalAccessToken struct {
+	UUID       string     `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID         int64      `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID     int64      `gorm:"not null;index" db:"user_id" json:"user_id"`
+	Name       string     `gorm:"size:255;not null" db:"name" json:"name"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex;not null" db:"token_hash" json:"-"` // SHA-256 of the token, the token itself is shown only once
+	Prefix     string     `gorm:"size:16" db:"prefix" json:"prefix"`                     // First characters of the token, to recognise it in the list
+	Scopes     string     `gorm:"size:512" db:"scopes" json:"scopes"`                    // Comma separated scopes
+	ExpiresAt  *time.Time `db:"expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at"`
+	Revoked    bool       `gorm:"default:false" db:"revoked" json:"revoked"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+// ScopeList returns the scopes of the token as a slice.
+func (t *PersonalAccessToken) ScopeList() []string {
+	return SplitScopes(t.Scopes)
+}
+
+// IsExpired reports whether the token has an expiry that has passed at the given time.
+func (t *PersonalAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && now.After(*t.ExpiresAt)
+}
+
+type PersonalAccessTokenResponse struct {
+	UUID       string     `json:"uuid"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Token      string     `json:"token,omitempty"` // Only filled once, right after creation
+}
+
+// PersonalAccessTokenPrefix marks personal access tokens so they can be told apart from JWTs in the Authorization header.
+const PersonalAccessTokenPrefix = "pat_"
@@ -3,13 +3,20 @@ package auth
 import "time"
 
 type RefreshToken struct {
-	UUID          string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
-	ID            int64     `gorm:"primaryKey" db:"id,primary,serial" json:"id"`
-	UserID        int64     `gorm:"not null" db:"user_id"`
-	Token         string    `gorm:"not null;unique" db:"token"`
-	AccessTokenID int64     `gorm:"not null" db:"access_token_id"` // Reference to AccessToken
-	ExpiresAt     time.Time `gorm:"not null" db:"expires_at"`
-	Claimed       bool      `gorm:"default:false" db:"claimed"`
-	CreatedAt     time.Time `gorm:"autoCreateTime" db:"created_at"`
-	UpdatedAt     time.Time `gorm:"autoUpdateTime" db:"updated_at"`
+	UUID             string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID               int64     `gorm:"primaryKey" db:"id,primary,serial" json:"id"`
+	UserID           int64     `gorm:"not null" db:"user_id"`
+	Token            string    `gorm:"not null;unique" db:"token"`
+	AccessTokenID    int64     `gorm:"not null" db:"access_token_id"` // Reference to AccessToken
+	SessionID        int64     `gorm:"index" db:"session_id"`         // Reference to Session
+	ExpiresAt        time.Time `gorm:"not null" db:"expires_at"`
+	Claimed          bool      `gorm:"default:false" db:"claimed"`
+	Revoked          bool      `gorm:"default:false" db:"revoked"`
+	ClientType       string    `gorm:"size:20;default:web" db:"client_type"`
+	RememberMe       bool      `gorm:"default:false" db:"remember_me"`
+	OauthClientID    int64     `gorm:"index" db:"oauth_client_id"` // Filled when issued to an OAuth client
+	Scopes           string    `gorm:"size:512" db:"scopes"`
+	SessionStartedAt time.Time `gorm:"not null" db:"session_started_at"` // Start of the login session, used for the max session age
+	CreatedAt        time.Time `gorm:"autoCreateTime" db:"created_at"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime" db:"updated_at"`
 }
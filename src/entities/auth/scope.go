@@ -0,0 +1,67 @@
+package auth
+
+import "strings"
+
+// Scopes limit what a personal access token may do. Session tokens from Login are not
+// scoped and pass every scope check.
+const (
+	ScopeAll          = "*"
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+	ScopeUsersRead    = "users:read"
+	ScopeTokensManage = "tokens:manage"
+)
+
+var KnownScopes = []string{
+	ScopeAll,
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeUsersRead,
+	ScopeTokensManage,
+}
+
+// DelegableScopes are the scopes third-party OAuth clients may request. Full access and managing
+// tokens stay reserved for the user's own sessions and personal access tokens.
+var DelegableScopes = []string{
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopeUsersRead,
+}
+
+// IsDelegableScope reports whether the scope is one of DelegableScopes.
+func IsDelegableScope(scope string) bool {
+	for _, delegable := range DelegableScopes {
+		if delegable == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitScopes turns a comma separated scope column into a slice.
+func SplitScopes(scopes string) []string {
+	if scopes == "" {
+		return []string{}
+	}
+	return strings.Split(scopes, ",")
+}
+
+// IsKnownScope reports whether the scope is one of KnownScopes.
+func IsKnownScope(scope string) bool {
+	for _, known := range KnownScopes {
+		if known == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopesAllow reports whether the granted scopes include the required scope, either directly or through ScopeAll.
+func ScopesAllow(granted []string, required string) bool {
+	for _, scope := range granted {
+		if scope == ScopeAll || scope == required {
+			return true
+		}
+	}
+	return false
+}
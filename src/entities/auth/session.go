@@ -0,0 +1,26 @@
+package auth
+
+import "time"
+
+type Session struct {
+	UUID       string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID         int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID     int64     `gorm:"not null;index" db:"user_id" json:"user_id"`
+	UserAgent  string    `gorm:"size:512" db:"user_agent" json:"user_agent"`
+	IPAddress  string    `gorm:"size:64" db:"ip_address" json:"ip_address"`
+	DeviceName string    `gorm:"size:255" db:"device_name" json:"device_name"`
+	LastUsedAt time.Time `db:"last_used_at" json:"last_used_at"`
+	Revoked    bool      `gorm:"default:false" db:"revoked" json:"revoked"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+type SessionResponse struct {
+	UUID       string    `json:"uuid"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	Current    bool      `json:"current"`
+}
@@ -0,0 +1,35 @@
+package auth
+
+import "time"
+
+type TwoFactor struct {
+	UUID         string     `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID           int64      `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID       int64      `gorm:"not null;uniqueIndex" db:"user_id" json:"user_id"`
+	Secret       string     `gorm:"size:255;not null" db:"secret" json:"-"` // TOTP secret, encrypted with crypts.Encrypt
+	ConfirmedAt  *time.Time `db:"confirmed_at" json:"confirmed_at"`
+	LastUsedStep int64      `gorm:"default:0" db:"last_used_step" json:"-"` // Last accepted TOTP time step, prevents code replay
+	CreatedAt    time.Time  `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+// IsEnabled reports whether the enrolment has been confirmed with a valid code.
+func (t *TwoFactor) IsEnabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+type RecoveryCode struct {
+	UUID      string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID        int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID    int64     `gorm:"not null;index" db:"user_id" json:"user_id"`
+	CodeHash  string    `gorm:"size:64;not null" db:"code_hash" json:"-"` // SHA-256 of the recovery code
+	Used      bool      `gorm:"default:false" db:"used" json:"used"`
+	CreatedAt time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+type MFAEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	QRCode     string `json:"qr_code"` // PNG encoded as a data URI
+}
@@ -0,0 +1,56 @@
+package oauth
+
+import "time"
+
+// AuthorizationCode is the short-lived, single-use code handed to the client after the user approved
+// the authorization request. It is exchanged for tokens together with the PKCE code verifier.
+type AuthorizationCode struct {
+	UUID                string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID                  int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	OauthClientID       int64     `gorm:"not null;index" db:"oauth_client_id" json:"oauth_client_id"`
+	UserID              int64     `gorm:"not null;index" db:"user_id" json:"user_id"`
+	CodeHash            string    `gorm:"size:64;uniqueIndex;not null" db:"code_hash" json:"-"` // SHA-256 of the code
+	RedirectURI         string    `gorm:"size:2048" db:"redirect_uri" json:"redirect_uri"`
+	Scopes              string    `gorm:"size:512" db:"scopes" json:"scopes"`
+	CodeChallenge       string    `gorm:"size:128" db:"code_challenge" json:"-"`
+	CodeChallengeMethod string    `gorm:"size:10" db:"code_challenge_method" json:"-"`
+	ExpiresAt           time.Time `gorm:"not null" db:"expires_at" json:"expires_at"`
+	Used                bool      `gorm:"default:false" db:"used" json:"used"`
+	SessionID           int64     `gorm:"index" db:"session_id" json:"-"` // Session created by the exchange, revoked when the code is replayed
+	CreatedAt           time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+func (AuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// AuthorizationPrompt describes a valid authorization request, shown to the user on the consent screen.
+type AuthorizationPrompt struct {
+	ClientID    string   `json:"client_id"`
+	ClientName  string   `json:"client_name"`
+	RedirectURI string   `json:"redirect_uri"`
+	Scopes      []string `json:"scopes"`
+	State       string   `json:"state,omitempty"`
+}
+
+// TokenResponse is the token endpoint response of RFC 6749 section 5.1.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// IntrospectionResponse is the token introspection response of RFC 7662 section 2.2.
+type IntrospectionResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Username  string `json:"username,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+}
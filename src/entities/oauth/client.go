@@ -0,0 +1,87 @@
+package oauth
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantClientCredentials = "client_credentials"
+	GrantRefreshToken      = "refresh_token"
+)
+
+// Client is a third-party application registered by a user to get delegated access through OAuth2.
+// Confidential clients authenticate with their secret, public clients (mobile, SPA) rely on PKCE only.
+type Client struct {
+	UUID         string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID           int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID       int64     `gorm:"not null;index" db:"user_id" json:"user_id"` // Owner of the client
+	Name         string    `gorm:"size:255;not null" db:"name" json:"name"`
+	ClientID     string    `gorm:"size:64;uniqueIndex;not null" db:"client_id" json:"client_id"`
+	SecretHash   string    `gorm:"size:64" db:"secret_hash" json:"-"`     // SHA-256 of the secret, empty for public clients
+	RedirectURIs string    `gorm:"size:2048" db:"redirect_uris" json:"-"` // Space separated
+	Scopes       string    `gorm:"size:512" db:"scopes" json:"-"`         // Comma separated scopes the client may request
+	GrantTypes   string    `gorm:"size:255" db:"grant_types" json:"-"`    // Comma separated
+	Confidential bool      `gorm:"default:false" db:"confidential" json:"confidential"`
+	Revoked      bool      `gorm:"default:false" db:"revoked" json:"revoked"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+func (Client) TableName() string {
+	return "oauth_clients"
+}
+
+func (c *Client) RedirectURIList() []string {
+	return strings.Fields(c.RedirectURIs)
+}
+
+func (c *Client) ScopeList() []string {
+	return splitList(c.Scopes)
+}
+
+func (c *Client) GrantTypeList() []string {
+	return splitList(c.GrantTypes)
+}
+
+// AllowsRedirectURI reports whether the redirect URI exactly matches one of the registered URIs.
+func (c *Client) AllowsRedirectURI(redirectURI string) bool {
+	return contains(c.RedirectURIList(), redirectURI)
+}
+
+func (c *Client) AllowsGrant(grantType string) bool {
+	return contains(c.GrantTypeList(), grantType)
+}
+
+func (c *Client) AllowsScope(scope string) bool {
+	return contains(c.ScopeList(), scope)
+}
+
+type ClientResponse struct {
+	UUID         string    `json:"uuid"`
+	Name         string    `json:"name"`
+	ClientID     string    `json:"client_id"`
+	ClientSecret string    `json:"client_secret,omitempty"` // Only filled once, right after registration
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	GrantTypes   []string  `json:"grant_types"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func splitList(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, ",")
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
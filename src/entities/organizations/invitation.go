@@ -0,0 +1,32 @@
+package organizations
+
+import "time"
+
+// Invitation is an emailed invitation to join an organization. Only the SHA-256 hash of the
+// token is stored, the plain token is only part of the accept link.
+type Invitation struct {
+	UUID           string     `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID             int64      `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	OrganizationID int64      `gorm:"not null;index" db:"organization_id" json:"organization_id"`
+	Email          string     `gorm:"size:255;not null;index" db:"email" json:"email"`
+	Role           string     `gorm:"size:20;not null" db:"role" json:"role"`
+	InvitedBy      int64      `gorm:"not null" db:"invited_by" json:"invited_by"`
+	TokenHash      string     `gorm:"size:64;uniqueIndex;not null" db:"token_hash" json:"-"`
+	ExpiresAt      time.Time  `gorm:"not null" db:"expires_at" json:"expires_at"`
+	AcceptedAt     *time.Time `db:"accepted_at" json:"accepted_at"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+// IsPending reports whether the invitation can still be accepted at the given time.
+func (i *Invitation) IsPending(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
+
+type InvitationResponse struct {
+	UUID      string    `json:"uuid"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
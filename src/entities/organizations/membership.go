@@ -0,0 +1,38 @@
+package organizations
+
+import "time"
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// Roles are the organization-level roles, from most to least privileged. They are separate from
+// the application roles in the roles package.
+var Roles = []string{RoleOwner, RoleAdmin, RoleMember}
+
+// Membership links a user to an organization with an organization-level role.
+type Membership struct {
+	UUID           string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID             int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	OrganizationID int64     `gorm:"not null;index" db:"organization_id" json:"organization_id"`
+	UserID         int64     `gorm:"not null;index" db:"user_id" json:"user_id"`
+	Role           string    `gorm:"size:20;not null" db:"role" json:"role"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+// CanManageMembers reports whether the member may invite others and change memberships.
+func (m *Membership) CanManageMembers() bool {
+	return m.Role == RoleOwner || m.Role == RoleAdmin
+}
+
+type MemberResponse struct {
+	UUID     string    `json:"uuid"`
+	UserUUID string    `json:"user_uuid"`
+	Email    string    `json:"email"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
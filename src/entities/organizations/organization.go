@@ -0,0 +1,20 @@
+package organizations
+
+import "time"
+
+// Organization is a customer company. Users belong to one or more organizations through a Membership.
+type Organization struct {
+	UUID      string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID        int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	Name      string    `gorm:"size:255;not null" db:"name" json:"name"`
+	OwnerID   int64     `gorm:"not null;index" db:"owner_id" json:"owner_id"` // User that created the organization
+	CreatedAt time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+type OrganizationResponse struct {
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"` // Role of the current user in the organization
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package roles
+
+import "time"
+
+const (
+	PermissionUsersList        = "users.list"
+	PermissionUsersView        = "users.view"
+	PermissionUsersCreate      = "users.create"
+	PermissionUsersUpdate      = "users.update"
+	PermissionUsersDelete      = "users.delete"
+	PermissionUsersImpersonate = "users.impersonate"
+	PermissionRolesManage      = "roles.manage"
+)
+
+type Permission struct {
+	UUID        string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID          int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	Name        string    `gorm:"size:100;uniqueIndex;not null" db:"name" json:"name"`
+	Description string    `gorm:"size:255" db:"description" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+type RolePermission struct {
+	UUID         string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID           int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	RoleID       int64     `gorm:"not null;index" db:"role_id" json:"role_id"`
+	PermissionID int64     `gorm:"not null;index" db:"permission_id" json:"permission_id"`
+	CreatedAt    time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package roles
+
+import "time"
+
+const (
+	RoleAdmin   = "admin"
+	RoleSupport = "support"
+)
+
+type Role struct {
+	UUID        string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID          int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	Name        string    `gorm:"size:100;uniqueIndex;not null" db:"name" json:"name"`
+	Description string    `gorm:"size:255" db:"description" json:"description"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+type UserRole struct {
+	UUID      string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID        int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID    int64     `gorm:"not null;index" db:"user_id" json:"user_id"`
+	RoleID    int64     `gorm:"not null;index" db:"role_id" json:"role_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
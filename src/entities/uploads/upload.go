@@ -0,0 +1,58 @@
+package uploads
+
+import (
+	"strings"
+	"time"
+)
+
+// Purposes an upload can be made for, the finished file is handed to the matching flow.
+const (
+	PurposeAvatar     = "avatar"
+	PurposeAttachment = "attachment"
+)
+
+// Upload is a resumable (tus) upload. The received chunks are kept in the storage under
+// PartPrefix until Offset reaches Length, then the file is handed to the flow of Purpose.
+type Upload struct {
+	UUID        string     `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID          int64      `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID      int64      `gorm:"not null;index" db:"user_id" json:"user_id"`
+	Purpose     string     `gorm:"size:50;not null" db:"purpose" json:"purpose"`
+	Length      int64      `gorm:"column:upload_length;not null" db:"upload_length" json:"length"`
+	Offset      int64      `gorm:"column:upload_offset;not null;default:0" db:"upload_offset" json:"offset"`
+	Parts       int        `gorm:"not null;default:0" db:"parts" json:"parts"`
+	PartKeys    string     `gorm:"type:text" db:"part_keys" json:"-"`       // storage keys of the parts, one per line
+	Metadata    string     `gorm:"type:text" db:"metadata" json:"metadata"` // raw Upload-Metadata header
+	ExpiresAt   time.Time  `gorm:"not null;index" db:"expires_at" json:"expires_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+// IsExpired reports whether the upload can no longer be resumed at the given time.
+func (u *Upload) IsExpired(now time.Time) bool {
+	return !now.Before(u.ExpiresAt)
+}
+
+// StoredParts returns the storage keys of the received parts in the order of the file.
+func (u *Upload) StoredParts() []string {
+	if u.PartKeys == "" {
+		return nil
+	}
+	return strings.Split(u.PartKeys, "\n")
+}
+
+// PartPrefix is the storage key prefix of the received chunks. It is private, so the
+// chunks are never served by /files without a signed link.
+func (u *Upload) PartPrefix() string {
+	return "private/uploads/" + u.UUID + "/"
+}
+
+type UploadResponse struct {
+	UUID        string     `json:"uuid"`
+	Purpose     string     `json:"purpose"`
+	Length      int64      `json:"length"`
+	Offset      int64      `json:"offset"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+}
@@ -0,0 +1,23 @@
+package users
+
+import "time"
+
+// AdminUserResponse is the view of a user in the admin user management API.
+type AdminUserResponse struct {
+	UUID                string         `json:"uuid"`
+	ID                  int64          `json:"id"`
+	Email               string         `json:"email"`
+	Username            string         `json:"username"`
+	Avatar              AvatarVariants `json:"avatar"`
+	EmailVerified       bool           `json:"email_verified"`
+	Status              string         `json:"status"`
+	StatusReason        string         `json:"status_reason,omitempty"`
+	SuspendedUntil      *time.Time     `json:"suspended_until,omitempty"`
+	Roles               []string       `json:"roles"`
+	DeletionScheduledAt *time.Time     `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+}
+
+// AdminUserFilterFields are the columns the admin user list can be filtered and ordered by.
+var AdminUserFilterFields = []string{"id", "uuid", "email", "username", "status", "created_at", "updated_at"}
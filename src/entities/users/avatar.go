@@ -0,0 +1,99 @@
+package users
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Avatar variant names, every upload is stored in all of them.
+const (
+	AvatarOriginal = "original" // re-encoded upload, at most 1024x1024
+	AvatarLarge    = "large"    // 256x256 square
+	AvatarMedium   = "medium"   // 128x128 square
+	AvatarSmall    = "small"    // 64x64 square
+)
+
+// legacyFilesPrefix is the prefix of the public paths stored before avatars were kept as
+// storage keys, "/files/avatars/x.png" is the key "avatars/x.png".
+const legacyFilesPrefix = "/files/"
+
+// AvatarVariants maps the variant name to the storage key of its file. It is stored as JSON
+// in the avatar column; a plain path written before variants existed reads as the original.
+// Responses carry the URLs of the keys, see URLs.
+type AvatarVariants map[string]string
+
+// Original returns the key of the full size avatar, empty when the user has none.
+func (a AvatarVariants) Original() string {
+	return a[AvatarOriginal]
+}
+
+// Keys returns the storage keys of all variants.
+func (a AvatarVariants) Keys() []string {
+	keys := make([]string, 0, len(a))
+	for _, key := range a {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// URLs returns the variants with every key replaced by its URL, nil when there is no avatar.
+func (a AvatarVariants) URLs(urlFor func(key string) string) AvatarVariants {
+	if len(a) == 0 {
+		return nil
+	}
+	urls := make(AvatarVariants, len(a))
+	for name, key := range a {
+		urls[name] = urlFor(key)
+	}
+	return urls
+}
+
+func (a AvatarVariants) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(map[string]string(a))
+	if err != nil {
+		return nil, err
+	}
+	return string(encoded), nil
+}
+
+func (a *AvatarVariants) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		raw = v
+	case []byte:
+		raw = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into AvatarVariants", value)
+	}
+
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		*a = nil
+		return nil
+	}
+	if !strings.HasPrefix(raw, "{") {
+		*a = AvatarVariants{AvatarOriginal: strings.TrimPrefix(raw, legacyFilesPrefix)}
+		return nil
+	}
+
+	var variants map[string]string
+	if err := json.Unmarshal([]byte(raw), &variants); err != nil {
+		return fmt.Errorf("invalid avatar variants: %w", err)
+	}
+	for name, key := range variants {
+		variants[name] = strings.TrimPrefix(key, legacyFilesPrefix)
+	}
+	*a = variants
+	return nil
+}
@@ -0,0 +1,47 @@
+package users
+
+import "time"
+
+const (
+	ExportPending    = "pending"
+	ExportProcessing = "processing"
+	ExportReady      = "ready"
+	ExportFailed     = "failed"
+)
+
+// DataExport is a ZIP archive with the personal data of a user, built in the background and
+// downloaded through a signed link until ExpiresAt.
+type DataExport struct {
+	UUID        string     `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID          int64      `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	UserID      int64      `gorm:"not null;index" db:"user_id" json:"user_id"`
+	Status      string     `gorm:"size:20;not null;index" db:"status" json:"status"`
+	FilePath    string     `gorm:"size:512" db:"file_path" json:"-"` // storage key of the archive
+	Error       string     `gorm:"size:512" db:"error" json:"error"`
+	ExpiresAt   *time.Time `db:"expires_at" json:"expires_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+}
+
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+// IsExpired reports whether a finished export can no longer be downloaded.
+func (e *DataExport) IsExpired(now time.Time) bool {
+	return e.ExpiresAt != nil && now.After(*e.ExpiresAt)
+}
+
+type DataExportResponse struct {
+	UUID        string     `json:"uuid"`
+	Status      string     `json:"status"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// AccountDeletionResponse tells the user when the account will be anonymised.
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
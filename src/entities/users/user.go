@@ -1,29 +1,110 @@
 package users
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	StatusActive    = "active"
+	StatusSuspended = "suspended"
+	StatusBanned    = "banned"
+)
 
 type User struct {
-	UUID      string    `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
-	ID        int64     `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
-	Email     string    `gorm:"size:255;unique;not null" db:"email" json:"email" binding:"required,email"`
-	Username  string    `gorm:"size:255;unique;not null" db:"username" json:"username" binding:"required,min=3,max=255"`
-	Password  string    `gorm:"size:255;not null" db:"password" json:"password" binding:"required,min=6"`
-	Avatar    string    `gorm:"size:255" db:"avatar" json:"avatar"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	UUID                string         `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID                  int64          `gorm:"primaryKey;autoIncrement" db:"id,primary,serial" json:"id"`
+	Email               string         `gorm:"size:255;unique;not null" db:"email" json:"email" binding:"required,email"`
+	Username            string         `gorm:"size:255;unique;not null" db:"username" json:"username" binding:"required,min=3,max=255"`
+	Password            string         `gorm:"size:255;not null" db:"password" json:"password" binding:"required,min=6"`
+	Avatar              AvatarVariants `gorm:"type:text" db:"avatar" json:"avatar"`
+	EmailVerifiedAt     *time.Time     `db:"email_verified_at" json:"email_verified_at"`
+	Status              string         `gorm:"size:20;default:active;index" db:"status" json:"status"` // active, suspended or banned
+	StatusReason        string         `gorm:"size:500" db:"status_reason" json:"status_reason"`
+	SuspendedUntil      *time.Time     `db:"suspended_until" json:"suspended_until"` // nil suspends until reactivated
+	DeletionPending     bool           `gorm:"default:false;index" db:"deletion_pending" json:"deletion_pending"`
+	DeletionScheduledAt *time.Time     `db:"deletion_scheduled_at" json:"deletion_scheduled_at"` // signing in before this cancels the deletion
+	AnonymizedAt        *time.Time     `db:"anonymized_at" json:"anonymized_at"`
+	CreatedAt           time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// CurrentStatus returns the effective status at the given time. A suspension whose
+// SuspendedUntil has passed counts as active again, an empty status too.
+func (u *User) CurrentStatus(now time.Time) string {
+	switch u.Status {
+	case StatusBanned:
+		return StatusBanned
+	case StatusSuspended:
+		if u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil) {
+			return StatusSuspended
+		}
+	}
+	return StatusActive
 }
+
+// StatusError returns an AccountStatusError when the account is suspended or banned at the
+// given time, nil when the user may sign in.
+func (u *User) StatusError(now time.Time) error {
+	status := u.CurrentStatus(now)
+	if status == StatusActive {
+		return nil
+	}
+
+	statusErr := &AccountStatusError{Status: status, Reason: u.StatusReason}
+	if status == StatusSuspended {
+		statusErr.Until = u.SuspendedUntil
+	}
+	return statusErr
+}
+
+// AccountStatusError is returned when a suspended or banned user tries to sign in or use a token.
+type AccountStatusError struct {
+	Status string
+	Reason string
+	Until  *time.Time
+}
+
+func (e *AccountStatusError) Error() string {
+	message := "your account has been " + e.Status
+	if e.Until != nil {
+		message += fmt.Sprintf(" until %s", e.Until.UTC().Format(time.RFC3339))
+	}
+	if e.Reason != "" {
+		message += ": " + e.Reason
+	}
+	return message
+}
+
+// ErrorCode is the machine readable code sent with the error response,
+// "account_suspended" or "account_banned".
+func (e *AccountStatusError) ErrorCode() string {
+	return "account_" + e.Status
+}
+
+// IsAnonymized reports whether the account was deleted and its personal data removed.
+func (u *User) IsAnonymized() bool {
+	return u.AnonymizedAt != nil
+}
+
+// IsEmailVerified reports whether the user has confirmed ownership of the email address.
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 type ResponseRegister struct {
-	UUID     string `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
-	ID       int64  `db:"id" json:"id"`
-	Email    string `db:"email" json:"email" binding:"required,email"`
-	Username string `db:"username" json:"username" binding:"required,min=3,max=255"`
-	Avatar   string `gorm:"size:255" db:"avatar" json:"avatar"`
+	UUID     string         `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID       int64          `db:"id" json:"id"`
+	Email    string         `db:"email" json:"email" binding:"required,email"`
+	Username string         `db:"username" json:"username" binding:"required,min=3,max=255"`
+	Avatar   AvatarVariants `db:"avatar" json:"avatar"`
 }
 
 type ProfileResponse struct {
-	UUID     string `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
-	ID       int64  `db:"id" json:"id"`
-	Email    string `db:"email" json:"email" binding:"required,email"`
-	Username string `db:"username" json:"username" binding:"required,min=3,max=255"`
-	Avatar   string `gorm:"size:255" db:"avatar" json:"avatar"`
+	UUID          string         `gorm:"uniqueIndex" db:"uuid" json:"uuid"`
+	ID            int64          `db:"id" json:"id"`
+	Email         string         `db:"email" json:"email" binding:"required,email"`
+	Username      string         `db:"username" json:"username" binding:"required,min=3,max=255"`
+	Avatar        AvatarVariants `db:"avatar" json:"avatar"`
+	EmailVerified bool           `json:"email_verified"`
 }
@@ -0,0 +1,80 @@
+package helpers
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUserID returns the authenticated user ID that JWTAuthMiddleware stored in the
+// context under the key "user_id". It returns an error if the key is missing or
+// holds an unexpected type.
+func GetUserID(ctx *gin.Context) (int64, error) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		return 0, fmt.Errorf("user not exists")
+	}
+
+	switch v := userID.(type) {
+	case int64:
+		return v, nil
+	case uint:
+		return int64(v), nil
+	case uint64:
+		return int64(v), nil
+	default:
+		return 0, fmt.Errorf("unexpected type for user_id: %T", v)
+	}
+}
+
+// GetSessionID returns the ID of the session the current access token belongs to.
+// It returns 0 if the token is not linked to a session.
+func GetSessionID(ctx *gin.Context) int64 {
+	sessionID, _ := ctx.Get("session_id")
+	id, _ := sessionID.(int64)
+	return id
+}
+
+// GetTokenScopes returns the scopes of the token used for the request and whether the token is scoped.
+// Session tokens from Login are not scoped.
+func GetTokenScopes(ctx *gin.Context) ([]string, bool) {
+	scopes, exists := ctx.Get("token_scopes")
+	if !exists {
+		return nil, false
+	}
+	list, _ := scopes.([]string)
+	return list, true
+}
+
+// GetOAuthClientID returns the OAuth client the access token of the request was issued to,
+// or 0 for first-party tokens.
+func GetOAuthClientID(ctx *gin.Context) int64 {
+	clientID, _ := ctx.Get("oauth_client_id")
+	id, _ := clientID.(int64)
+	return id
+}
+
+// GetRealUserID returns the admin behind an impersonation token and true, or 0 and false
+// when the request is not impersonated.
+func GetRealUserID(ctx *gin.Context) (int64, bool) {
+	realUserID, exists := ctx.Get("real_user_id")
+	if !exists {
+		return 0, false
+	}
+	id, ok := realUserID.(uint)
+	return int64(id), ok
+}
+
+// GetOrganizationID returns the current organization that RequireOrganization stored in the
+// context under the key "organization_id". It returns an error if no organization was selected.
+func GetOrganizationID(ctx *gin.Context) (int64, error) {
+	organizationID, exists := ctx.Get("organization_id")
+	if !exists {
+		return 0, fmt.Errorf("no organization selected")
+	}
+	id, ok := organizationID.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected type for organization_id: %T", organizationID)
+	}
+	return id, nil
+}
@@ -0,0 +1,29 @@
+package helpers
+
+import (
+	"database/sql"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotFound is wrapped by the errors of the native SQL lookups when no record matches.
+var ErrNotFound = errors.New("record not found")
+
+// IsNotFound reports whether err means that no record matched, with GORM as well as native SQL.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, sql.ErrNoRows)
+}
+
+// uniqueViolation is the SQLSTATE PostgreSQL reports for a duplicate key.
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err is a duplicate key error, from lib/pq (native SQL) as
+// well as pgx (GORM), so an insert that lost a race can be told apart from a failing database.
+func IsUniqueViolation(err error) bool {
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) && state.SQLState() == uniqueViolation {
+		return true
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
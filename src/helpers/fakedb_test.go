@@ -0,0 +1,250 @@
+package helpers
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fakeDB is an in-memory database/sql driver that understands the native SQL built by the
+// generic helpers: INSERT ... RETURNING id, SELECT * / SELECT COUNT(*), UPDATE and DELETE with
+// "column = $n" conditions joined by AND. Every DSN is its own database.
+type fakeDB struct {
+	mu        sync.Mutex
+	databases map[string]*fakeStore
+}
+
+type fakeStore struct {
+	mu      sync.Mutex
+	columns map[string][]string
+	rows    map[string][]map[string]driver.Value
+	nextID  int64
+}
+
+var fakeDriver = &fakeDB{databases: map[string]*fakeStore{}}
+
+func init() {
+	sql.Register("helpersfake", fakeDriver)
+}
+
+// openFakeDB opens an empty database with the given tables, each described by its columns in
+// the order of the `db` tags of its model.
+func openFakeDB(name string, tables map[string][]string) (*sql.DB, error) {
+	fakeDriver.mu.Lock()
+	fakeDriver.databases[name] = &fakeStore{columns: tables, rows: map[string][]map[string]driver.Value{}}
+	fakeDriver.mu.Unlock()
+	return sql.Open("helpersfake", name)
+}
+
+func (d *fakeDB) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, ok := d.databases[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown fake database %q", name)
+	}
+	return &fakeConn{store: store}, nil
+}
+
+type fakeConn struct {
+	store *fakeStore
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{store: c.store, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported by the fake database")
+}
+
+type fakeStmt struct {
+	store *fakeStore
+	query string
+}
+
+var (
+	fakeInsert = regexp.MustCompile(`^INSERT INTO (\w+) \(([^)]*)\) VALUES \(([^)]*)\) RETURNING id$`)
+	fakeSelect = regexp.MustCompile(`^SELECT (\*|COUNT\(\*\)) FROM (\w+)(?: WHERE (.+?))?(?: LIMIT \d+)?$`)
+	fakeUpdate = regexp.MustCompile(`^UPDATE (\w+) SET (.+) WHERE (.+)$`)
+	fakeDelete = regexp.MustCompile(`^DELETE FROM (\w+) WHERE (.+)$`)
+	fakeAssign = regexp.MustCompile(`^(\w+) = \$(\d+)$`)
+)
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if m := fakeUpdate.FindStringSubmatch(s.query); m != nil {
+		sets, err := fakeAssignments(strings.Split(m[2], ", "), args)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := s.store.matching(m[1], m[3], args)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range matches {
+			for column, value := range sets {
+				row[column] = value
+			}
+		}
+		return driver.RowsAffected(len(matches)), nil
+	}
+
+	if m := fakeDelete.FindStringSubmatch(s.query); m != nil {
+		matches, err := s.store.matching(m[1], m[2], args)
+		if err != nil {
+			return nil, err
+		}
+		kept := s.store.rows[m[1]][:0]
+		for _, row := range s.store.rows[m[1]] {
+			if !containsRow(matches, row) {
+				kept = append(kept, row)
+			}
+		}
+		s.store.rows[m[1]] = kept
+		return driver.RowsAffected(len(matches)), nil
+	}
+
+	return nil, fmt.Errorf("fake database cannot execute %q", s.query)
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	if m := fakeInsert.FindStringSubmatch(s.query); m != nil {
+		columns := strings.Split(m[2], ", ")
+		placeholders := strings.Split(m[3], ", ")
+		row := map[string]driver.Value{}
+		for i, column := range columns {
+			value, err := fakeArg(placeholders[i], args)
+			if err != nil {
+				return nil, err
+			}
+			row[column] = value
+		}
+		s.store.nextID++
+		row["id"] = s.store.nextID
+		s.store.rows[m[1]] = append(s.store.rows[m[1]], row)
+		return &fakeRows{columns: []string{"id"}, values: [][]driver.Value{{s.store.nextID}}}, nil
+	}
+
+	if m := fakeSelect.FindStringSubmatch(s.query); m != nil {
+		matches, err := s.store.matching(m[2], m[3], args)
+		if err != nil {
+			return nil, err
+		}
+		if m[1] != "*" {
+			return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{int64(len(matches))}}}, nil
+		}
+
+		columns := s.store.columns[m[2]]
+		rows := &fakeRows{columns: columns}
+		for _, row := range matches {
+			values := make([]driver.Value, len(columns))
+			for i, column := range columns {
+				values[i] = row[column]
+			}
+			rows.values = append(rows.values, values)
+		}
+		return rows, nil
+	}
+
+	return nil, fmt.Errorf("fake database cannot query %q", s.query)
+}
+
+// matching returns the rows of the table that satisfy the WHERE clause.
+func (st *fakeStore) matching(table string, where string, args []driver.Value) ([]map[string]driver.Value, error) {
+	if _, ok := st.columns[table]; !ok {
+		return nil, fmt.Errorf("unknown table %q", table)
+	}
+
+	var conditions []string
+	if where != "" {
+		where = strings.NewReplacer("(", "", ")", "").Replace(where)
+		conditions = strings.Split(where, " AND ")
+	}
+	wanted, err := fakeAssignments(conditions, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []map[string]driver.Value
+	for _, row := range st.rows[table] {
+		matched := true
+		for column, value := range wanted {
+			if fmt.Sprint(row[column]) != fmt.Sprint(value) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, row)
+		}
+	}
+	return matches, nil
+}
+
+// fakeAssignments maps the columns of "column = $n" expressions to their arguments.
+func fakeAssignments(expressions []string, args []driver.Value) (map[string]driver.Value, error) {
+	values := map[string]driver.Value{}
+	for _, expression := range expressions {
+		m := fakeAssign.FindStringSubmatch(strings.TrimSpace(expression))
+		if m == nil {
+			return nil, fmt.Errorf("fake database cannot evaluate %q", expression)
+		}
+		value, err := fakeArg("$"+m[2], args)
+		if err != nil {
+			return nil, err
+		}
+		values[m[1]] = value
+	}
+	return values, nil
+}
+
+func fakeArg(placeholder string, args []driver.Value) (driver.Value, error) {
+	n, err := strconv.Atoi(strings.TrimPrefix(placeholder, "$"))
+	if err != nil || n < 1 || n > len(args) {
+		return nil, fmt.Errorf("invalid placeholder %q", placeholder)
+	}
+	return args[n-1], nil
+}
+
+func containsRow(rows []map[string]driver.Value, row map[string]driver.Value) bool {
+	for _, candidate := range rows {
+		if fmt.Sprint(candidate["id"]) == fmt.Sprint(row["id"]) {
+			return true
+		}
+	}
+	return false
+}
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
@@ -75,11 +75,22 @@ func ToSnakeCase(str string) string {
 // models. It will use the maximum batch size of 500 records for each batch.
 // If the database connection is not available, InsertModelBatch returns
 // sql.ErrConnDone.
-func InsertModelBatch[T any](models []T) error {
+// QueryOption values scope the records the same way as InsertModel.
+func InsertModelBatch[T any](models []T, opts ...QueryOption) error {
 	if len(models) == 0 {
 		return nil
 	}
 
+	scope, err := resolveScope(new(T), opts)
+	if err != nil {
+		return err
+	}
+	for i := range models {
+		if err := scopeRecord(&models[i], scope); err != nil {
+			return err
+		}
+	}
+
 	now := time.Now()
 	useGorm := os.Getenv("USE_GORM") == "true"
 	useSQL := !useGorm && database.SQLDB != nil
@@ -244,7 +255,18 @@ func InsertModelBatch[T any](models []T) error {
 // InsertModel will return an error if the model has no valid columns to insert.
 //
 // InsertModel will return an error if the primary key field is not addressable.
-func InsertModel[T any](model *T) error {
+//
+// QueryOption values scope the new record: its organization_id column is filled from the scope
+// when empty, and a record of another organization gives ErrOrganizationChange.
+func InsertModel[T any](model *T, opts ...QueryOption) error {
+	scope, err := resolveScope(model, opts)
+	if err != nil {
+		return err
+	}
+	if err := scopeRecord(model, scope); err != nil {
+		return err
+	}
+
 	if database.GormDB != nil {
 		if err := SetUUIDForStruct(model); err != nil {
 			return fmt.Errorf("❌ Error setting UUID: %w", err)
@@ -381,7 +403,14 @@ func InsertModel[T any](model *T) error {
 // offset, and orderBy. It will use GORM if the USE_GORM environment variable is set
 // to "true", otherwise it will use native SQL. It will automatically build a WHERE
 // clause from the query string parameters of the given gin.Context.
-func GetAllModels[T any](ctx *gin.Context, models *[]T, limit, offset int) error {
+// QueryOption values such as ForOrganization are added to the WHERE clause, organization-owned
+// tables are scoped to the organization of the request.
+func GetAllModels[T any](ctx *gin.Context, models *[]T, limit, offset int, opts ...QueryOption) error {
+	scope, err := resolveScope(new(T), requestScope(ctx, opts))
+	if err != nil {
+		return err
+	}
+
 	useGORM := os.Getenv("USE_GORM") == "true"
 	if useGORM {
 		useGORM = true
@@ -414,6 +443,7 @@ func GetAllModels[T any](ctx *gin.Context, models *[]T, limit, offset int) error
 		if whereClause != "" {
 			query = query.Where(whereClause, args...)
 		}
+		query = whereConditions(query, scope)
 
 		return query.Find(models).Error
 	}
@@ -431,6 +461,7 @@ func GetAllModels[T any](ctx *gin.Context, models *[]T, limit, offset int) error
 	if err != nil {
 		return err
 	}
+	whereClause, args = appendWhereClause(whereClause, args, scope)
 	if whereClause != "" {
 		query += " WHERE " + whereClause
 	}
@@ -509,9 +540,16 @@ func GetTableName[T any](model *T) string {
 // sql.ErrNoRows.
 // If the database connection is not available, GetModelByID returns
 // sql.ErrConnDone.
-func GetModelByID[T any](model *T, id any) error {
+// QueryOption values such as ForOrganization narrow the lookup, a record outside the
+// scope (or of another organization) is reported as not found.
+func GetModelByID[T any](model *T, id any, opts ...QueryOption) error {
+	scope, err := resolveScope(model, opts)
+	if err != nil {
+		return err
+	}
+
 	if database.GormDB != nil {
-		return database.GormDB.First(model, id).Error
+		return whereConditions(database.GormDB, scope).First(model, id).Error
 	}
 
 	if database.SQLDB == nil {
@@ -520,8 +558,9 @@ func GetModelByID[T any](model *T, id any) error {
 	}
 
 	table := GetTableName(model)
-	query := fmt.Sprintf("SELECT * FROM %s WHERE id = $1 LIMIT 1", table)
-	row := database.SQLDB.QueryRow(query, id)
+	whereClause, args := buildWhereClause(append([]any{"id", id}, scope...))
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT 1", table, whereClause)
+	row := database.SQLDB.QueryRow(query, args...)
 
 	return scanRowIntoStruct(row, model)
 }
@@ -561,7 +600,7 @@ func scanRowIntoStruct[T any](row *sql.Row, model *T) error {
 
 	err := row.Scan(dest...)
 	if errors.Is(err, sql.ErrNoRows) {
-		return fmt.Errorf("record not found for %T", model)
+		return fmt.Errorf("%w for %T", ErrNotFound, model)
 	}
 	if err != nil {
 		return fmt.Errorf("scan error: %w", err)
@@ -579,11 +618,21 @@ func scanRowIntoStruct[T any](row *sql.Row, model *T) error {
 // If the record is not found, UpdateModelByIDWithMap returns an error with a message
 // indicating the record was not found. If the update fails, it returns an error with
 // details about the failure.
-func UpdateModelByIDWithMap[T any](updatedFields map[string]interface{}, id any) error {
+// QueryOption values such as ForOrganization are added to the WHERE clause, so a record
+// outside the scope (or of another organization) is left unchanged.
+func UpdateModelByIDWithMap[T any](updatedFields map[string]interface{}, id any, opts ...QueryOption) error {
+	scope, err := resolveScope(new(T), opts)
+	if err != nil {
+		return err
+	}
+	if err := refuseOrganizationChange(updatedFields, scope); err != nil {
+		return err
+	}
+
 	if database.GormDB != nil {
 		// Menggunakan new(T) untuk memberikan tipe eksplisit ke GORM
 		// Dengan new(T), kita bisa memastikan bahwa tipe tersebut sesuai
-		return database.GormDB.Model(new(T)).Where("id = ?", id).Updates(updatedFields).Error
+		return whereConditions(database.GormDB.Model(new(T)).Where("id = ?", id), scope).Updates(updatedFields).Error
 	}
 
 	if database.SQLDB == nil {
@@ -607,11 +656,12 @@ func UpdateModelByIDWithMap[T any](updatedFields map[string]interface{}, id any)
 		values = append(values, value)
 	}
 
-	// Membuat query untuk update
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", table, strings.Join(sets, ", "), len(values)+1)
-	values = append(values, id)
+	// Membuat query untuk update, id dan scope menjadi kondisi WHERE
+	whereClause, whereArgs := buildWhereClauseFrom(append([]any{"id", id}, scope...), len(values))
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(sets, ", "), whereClause)
+	values = append(values, whereArgs...)
 
-	_, err := database.SQLDB.Exec(query, values...)
+	_, err = database.SQLDB.Exec(query, values...)
 	return err
 }
 
@@ -624,9 +674,19 @@ func UpdateModelByIDWithMap[T any](updatedFields map[string]interface{}, id any)
 // If the record is not found, UpdateModelByID returns an error with a message
 // indicating the record was not found. If the update fails, it returns an error with
 // details about the failure.
-func UpdateModelByID[T any](model *T, id any) error {
+// QueryOption values are added to the WHERE clause like UpdateModelByIDWithMap, and the
+// model may not move the record to another organization.
+func UpdateModelByID[T any](model *T, id any, opts ...QueryOption) error {
+	scope, err := resolveScope(model, opts)
+	if err != nil {
+		return err
+	}
+	if err := scopeRecord(model, scope); err != nil {
+		return err
+	}
+
 	if database.GormDB != nil {
-		return database.GormDB.Model(model).Where("id = ?", id).Updates(model).Error
+		return whereConditions(database.GormDB.Model(model).Where("id = ?", id), scope).Updates(model).Error
 	}
 
 	if database.SQLDB == nil {
@@ -666,11 +726,11 @@ func UpdateModelByID[T any](model *T, id any) error {
 		return errors.New("no fields to update")
 	}
 
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = $%d", table,
-		strings.Join(sets, ", "), len(values)+1)
-	values = append(values, id)
+	whereClause, whereArgs := buildWhereClauseFrom(append([]any{"id", id}, scope...), len(values))
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(sets, ", "), whereClause)
+	values = append(values, whereArgs...)
 
-	_, err := database.SQLDB.Exec(query, values...)
+	_, err = database.SQLDB.Exec(query, values...)
 	return err
 }
 
@@ -696,15 +756,21 @@ func hasDeletedAt(model any) bool {
 // If it doesn't, it will perform a hard delete.
 // If the database connection is not available, DeleteModelByID returns sql.ErrConnDone.
 // If the delete operation fails, it returns an error with details about the failure.
-func DeleteModelByID[T any](model *T, id any) error {
+// QueryOption values such as ForOrganization are added to the WHERE clause, so a record
+// outside the scope (or of another organization) is not deleted.
+func DeleteModelByID[T any](model *T, id any, opts ...QueryOption) error {
+	scope, err := resolveScope(model, opts)
+	if err != nil {
+		return err
+	}
+
 	if database.GormDB != nil {
 		// GORM punya soft delete bawaan, tapi kita handle manual biar konsisten
 		if hasDeletedAt(model) {
-			return database.GormDB.Model(model).
-				Where("id = ?", id).
+			return whereConditions(database.GormDB.Model(model).Where("id = ?", id), scope).
 				Update("deleted_at", time.Now()).Error
 		}
-		return database.GormDB.Delete(model, id).Error
+		return whereConditions(database.GormDB, scope).Delete(model, id).Error
 	}
 
 	if database.SQLDB == nil {
@@ -712,15 +778,18 @@ func DeleteModelByID[T any](model *T, id any) error {
 	}
 
 	table := GetTableName(model)
+	conditions := append([]any{"id", id}, scope...)
 
 	if hasDeletedAt(model) {
-		query := fmt.Sprintf("UPDATE %s SET deleted_at = $1 WHERE id = $2", table)
-		_, err := database.SQLDB.Exec(query, time.Now(), id)
+		whereClause, args := buildWhereClauseFrom(conditions, 1)
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = $1 WHERE %s", table, whereClause)
+		_, err := database.SQLDB.Exec(query, append([]any{time.Now()}, args...)...)
 		return err
 	}
 
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", table)
-	_, err := database.SQLDB.Exec(query, id)
+	whereClause, args := buildWhereClause(conditions)
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", table, whereClause)
+	_, err = database.SQLDB.Exec(query, args...)
 	return err
 }
 
@@ -731,20 +800,19 @@ func DeleteModelByID[T any](model *T, id any) error {
 // The function returns sql.ErrConnDone if no database connection is available.
 // If the record is found, it populates the provided model with the record's data.
 // If no record matches the conditions, it returns an error indicating the record was not found.
+// QueryOption values such as ForOrganization can be passed among the conditions.
 
 func FindOneByField[T any](model *T, conditions ...any) error {
+	conditions, err := splitConditions(model, conditions)
+	if err != nil {
+		return err
+	}
 	if len(conditions)%2 != 0 {
 		return fmt.Errorf("conditions must be in key-value pairs")
 	}
 
 	if database.GormDB != nil {
-		query := database.GormDB
-		for i := 0; i < len(conditions); i += 2 {
-			field := conditions[i].(string)
-			value := conditions[i+1]
-			query = query.Where(fmt.Sprintf("%s = ?", field), value)
-		}
-		return query.First(model).Error
+		return whereConditions(database.GormDB, conditions).First(model).Error
 	}
 
 	if database.SQLDB == nil {
@@ -752,22 +820,212 @@ func FindOneByField[T any](model *T, conditions ...any) error {
 	}
 
 	table := GetTableName(model)
-	whereClause := ""
-	args := []any{}
+	whereClause, args := buildWhereClause(conditions)
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT 1", table, whereClause)
+
+	row := database.SQLDB.QueryRow(query, args...)
+
+	return scanRowIntoStruct(row, model)
+}
+
+// FindAllByField retrieves all records from the database that match the given conditions.
+// The conditions must be provided as key-value pairs, the same way as FindOneByField.
+// For example: FindAllByField(&tokens, "user_id", userID, "revoked", false).
+// If GORM is enabled, it uses GORM's querying capabilities. Otherwise, it uses native SQL.
+// The function returns sql.ErrConnDone if no database connection is available.
+// If no record matches the conditions, models is left empty and no error is returned.
+// QueryOption values such as ForOrganization can be passed among the conditions.
+func FindAllByField[T any](models *[]T, conditions ...any) error {
+	conditions, err := splitConditions(new(T), conditions)
+	if err != nil {
+		return err
+	}
+	if len(conditions)%2 != 0 {
+		return fmt.Errorf("conditions must be in key-value pairs")
+	}
 
+	if database.GormDB != nil {
+		return whereConditions(database.GormDB, conditions).Find(models).Error
+	}
+
+	if database.SQLDB == nil {
+		return sql.ErrConnDone
+	}
+
+	var model T
+	table := GetTableName(&model)
+	whereClause, args := buildWhereClause(conditions)
+
+	query := fmt.Sprintf("SELECT * FROM %s", table)
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+
+	rows, err := database.SQLDB.Query(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item T
+		dest, err := dbFieldDestinations(&item)
+		if err != nil {
+			return fmt.Errorf("error scanning row destinations: %w", err)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("error scanning row: %w", err)
+		}
+		*models = append(*models, item)
+	}
+
+	return rows.Err()
+}
+
+// UpdateModelsByFieldWithMap updates every record in the database that matches the given conditions.
+// The updatedFields map specifies the fields to update and their new values, the conditions must be
+// provided as key-value pairs. For example, to revoke all tokens of a user you would call:
+// UpdateModelsByFieldWithMap[auth.AccessToken](map[string]interface{}{"revoked": true}, "user_id", userID).
+// The updated_at field will automatically be set to the current time if it is not present in the map
+// when native SQL is used. QueryOption values such as ForOrganization can be passed among the conditions.
+func UpdateModelsByFieldWithMap[T any](updatedFields map[string]interface{}, conditions ...any) error {
+	_, err := UpdateModelsByFieldWithMapCount[T](updatedFields, conditions...)
+	return err
+}
+
+// UpdateModelsByFieldWithMapCount works like UpdateModelsByFieldWithMap and also returns the number
+// of records that were changed. With a condition on the old value it makes a compare-and-set, e.g.
+// marking a single-use token: UpdateModelsByFieldWithMapCount[auth.MagicLinkToken](map[string]interface{}{"used": true},
+// "id", id, "used", false) changes one record for the first caller and none for every later one.
+func UpdateModelsByFieldWithMapCount[T any](updatedFields map[string]interface{}, conditions ...any) (int64, error) {
+	conditions, err := splitConditions(new(T), conditions)
+	if err != nil {
+		return 0, err
+	}
+	if err := refuseOrganizationChange(updatedFields, conditions); err != nil {
+		return 0, err
+	}
+	if len(conditions) == 0 || len(conditions)%2 != 0 {
+		return 0, fmt.Errorf("conditions must be in key-value pairs")
+	}
+
+	if database.GormDB != nil {
+		result := whereConditions(database.GormDB.Model(new(T)), conditions).Updates(updatedFields)
+		return result.RowsAffected, result.Error
+	}
+
+	if database.SQLDB == nil {
+		return 0, sql.ErrConnDone
+	}
+
+	table := GetTableName(new(T))
+
+	if _, exists := updatedFields["updated_at"]; !exists {
+		updatedFields["updated_at"] = time.Now()
+	}
+
+	var sets []string
+	var values []any
+	for column, value := range updatedFields {
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(values)+1))
+		values = append(values, value)
+	}
+
+	whereClause, whereArgs := buildWhereClauseFrom(conditions, len(values))
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(sets, ", "), whereClause)
+	values = append(values, whereArgs...)
+
+	result, err := database.SQLDB.Exec(query, values...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// buildWhereClause builds a native SQL WHERE clause with numbered placeholders
+// from conditions provided as key-value pairs, see conditionSQL for the keys.
+func buildWhereClause(conditions []any) (string, []any) {
+	return buildWhereClauseFrom(conditions, 0)
+}
+
+// buildWhereClauseFrom builds the same WHERE clause as buildWhereClause, numbering the
+// placeholders after the given number of arguments that come before it in the query.
+func buildWhereClauseFrom(conditions []any, offset int) (string, []any) {
+	var wheres []string
+	var args []any
 	for i := 0; i < len(conditions); i += 2 {
-		field := conditions[i].(string)
-		value := conditions[i+1]
-		if i > 0 {
-			whereClause += " AND "
+		wheres = append(wheres, conditionSQL(conditions[i].(string), fmt.Sprintf("$%d", offset+len(args)+1)))
+		args = append(args, conditions[i+1])
+	}
+	return strings.Join(wheres, " AND "), args
+}
+
+// dbFieldDestinations returns the addresses of the struct fields that are mapped to a
+// database column via the `db` tag, in declaration order. Fields with a `db` tag set to
+// "-" or not set at all are skipped, the same way as scanRowIntoStruct.
+func dbFieldDestinations[T any](model *T) ([]any, error) {
+	val := reflect.ValueOf(model)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return nil, errors.New("model must be a non-nil pointer")
+	}
+
+	elem := val.Elem()
+	if elem.Kind() != reflect.Struct {
+		return nil, errors.New("model must point to a struct")
+	}
+
+	var dest []any
+	for i := 0; i < elem.NumField(); i++ {
+		dbTag := elem.Type().Field(i).Tag.Get("db")
+		if dbTag == "-" || dbTag == "" {
+			continue
+		}
+		if elem.Field(i).CanSet() {
+			dest = append(dest, elem.Field(i).Addr().Interface())
 		}
-		whereClause += fmt.Sprintf("%s = $%d", field, (i/2)+1)
-		args = append(args, value)
 	}
+	return dest, nil
+}
 
-	query := fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT 1", table, whereClause)
+// DeleteModelsByField deletes every record from the database that matches the given conditions.
+// The conditions must be provided as key-value pairs, the same way as FindOneByField.
+// Like DeleteModelByID it performs a soft delete when the model has a "DeletedAt" field.
+// If the database connection is not available, DeleteModelsByField returns sql.ErrConnDone.
+// QueryOption values such as ForOrganization can be passed among the conditions.
+func DeleteModelsByField[T any](conditions ...any) error {
+	conditions, err := splitConditions(new(T), conditions)
+	if err != nil {
+		return err
+	}
+	if len(conditions) == 0 || len(conditions)%2 != 0 {
+		return fmt.Errorf("conditions must be in key-value pairs")
+	}
 
-	row := database.SQLDB.QueryRow(query, args...)
+	model := new(T)
 
-	return scanRowIntoStruct(row, model)
+	if database.GormDB != nil {
+		query := whereConditions(database.GormDB.Model(model), conditions)
+		if hasDeletedAt(model) {
+			return query.Update("deleted_at", time.Now()).Error
+		}
+		return query.Delete(model).Error
+	}
+
+	if database.SQLDB == nil {
+		return sql.ErrConnDone
+	}
+
+	table := GetTableName(model)
+	whereClause, args := buildWhereClause(conditions)
+
+	if hasDeletedAt(model) {
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = $%d WHERE %s", table, len(args)+1, whereClause)
+		_, err := database.SQLDB.Exec(query, append(args, time.Now())...)
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", table, whereClause)
+	_, err = database.SQLDB.Exec(query, args...)
+	return err
 }
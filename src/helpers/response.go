@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"gin/src/configs/database"
+	"gin/src/utils/filters"
 	"gin/src/utils/loggers"
 	"math"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 
@@ -29,12 +31,19 @@ type PaginationMeta struct {
 
 type Response struct {
 	Success bool        `json:"success"`
+	Code    string      `json:"code,omitempty"`
 	Message interface{} `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 	Meta    interface{} `json:"meta,omitempty"`
 	Links   interface{} `json:"links,omitempty"`
 }
 
+// ErrorCoder is implemented by errors that carry a machine readable code,
+// ErrorResponse sends it in the "code" field.
+type ErrorCoder interface {
+	ErrorCode() string
+}
+
 type ErrorData struct {
 	Error       interface{} `json:"error"`
 	Path        string      `json:"path"`
@@ -74,10 +83,17 @@ func SuccessResponse(ctx *gin.Context, message string, data interface{}, paginat
 
 // GetPaginatedData retrieves data with pagination or returns an empty array if the page is too high
 // GetPaginatedData fetches paginated data and returns it
-func GetPaginatedData[T any](ctx *gin.Context, db *gorm.DB, order string, page, limit, offset int) ([]T, PaginationMeta, int64) {
+// The query is scoped like GetAllModels, an organization-owned table without an organization gives no data.
+func GetPaginatedData[T any](ctx *gin.Context, db *gorm.DB, order string, page, limit, offset int, opts ...QueryOption) ([]T, PaginationMeta, int64) {
 	var data []T
 	var total int64
 
+	scope, err := resolveScope(new(T), requestScope(ctx, opts))
+	if err != nil {
+		return []T{}, PaginationMeta{Page: page, Limit: limit}, 0
+	}
+	db = whereConditions(db, scope)
+
 	db.Model(new(T)).Count(&total)
 
 	// If page is out of range, return empty data but still return meta
@@ -189,6 +205,12 @@ func ErrorResponse(ctx *gin.Context, err error, httpCode ...int) {
 		Message: message,
 		Data:    nil,
 	}
+
+	// Error dengan kode (misal akun disuspend) dikirim bersama kodenya agar mudah dibedakan client
+	var codedErr ErrorCoder
+	if errors.As(err, &codedErr) {
+		webResponse.Code = codedErr.ErrorCode()
+	}
 	ctx.JSON(httpCode[0], webResponse)
 }
 
@@ -269,10 +291,29 @@ func FormatLogError(message interface{}, ctx *gin.Context, httpCode int, request
 	}
 }
 
+// FieldErrors holds validation messages per request field for checks that cannot be expressed
+// as binding tags, for example the password policy. ParseValidationError renders it the same
+// way as binding errors.
+type FieldErrors map[string]string
+
+func (e FieldErrors) Error() string {
+	var messages []string
+	for field, message := range e {
+		messages = append(messages, field+": "+message)
+	}
+	return strings.Join(messages, ", ")
+}
+
 func ParseValidationError(err error) interface{} {
 	var ve validator.ValidationErrors
+	var fieldErrors FieldErrors
 	var message interface{}
 
+	if errors.As(err, &fieldErrors) {
+		jsonMessage, _ := json.Marshal(fieldErrors)
+		return string(jsonMessage)
+	}
+
 	if errors.As(err, &ve) {
 		errorMap := map[string]string{}
 		for _, fe := range ve {
@@ -284,6 +325,10 @@ func ParseValidationError(err error) interface{} {
 				errorMap[field] = fmt.Sprintf("%s must be a valid email", field)
 			case "min":
 				errorMap[field] = fmt.Sprintf("%s must be at least %s characters", field, fe.Param())
+			case "eqfield":
+				errorMap[field] = fmt.Sprintf("%s must match %s", field, strings.ToLower(fe.Param()))
+			case "oneof":
+				errorMap[field] = fmt.Sprintf("%s must be one of %s", field, fe.Param())
 			default:
 				errorMap[field] = fmt.Sprintf("%s is invalid", field)
 			}
@@ -327,10 +372,61 @@ func GetPaginationParams(ctx *gin.Context) (page, limit, offset int) {
 	return
 }
 
-func CountModel[T any]() (int64, error) {
+// CountModelWithFilters counts the records matching the filter query parameters of the request,
+// the same filters GetAllModels applies, so it gives the pagination total of a filtered list.
+func CountModelWithFilters[T any](ctx *gin.Context, opts ...QueryOption) (int64, error) {
+	scope, err := resolveScope(new(T), requestScope(ctx, opts))
+	if err != nil {
+		return 0, err
+	}
+
+	if os.Getenv("USE_GORM") == "true" && database.GormDB != nil {
+		whereClause, args, err := filters.BuildFilters(ctx, true)
+		if err != nil {
+			return 0, err
+		}
+		query := database.GormDB.Model(new(T))
+		if whereClause != "" {
+			query = query.Where(whereClause, args...)
+		}
+		query = whereConditions(query, scope)
+		var total int64
+		err = query.Count(&total).Error
+		return total, err
+	}
+
+	if database.SQLDB == nil {
+		return 0, sql.ErrConnDone
+	}
+
+	whereClause, args, err := filters.BuildFilters(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+
+	whereClause, args = appendWhereClause(whereClause, args, scope)
+
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", GetTableName(new(T)))
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+
+	var total int64
+	err = database.SQLDB.QueryRow(query, args...).Scan(&total)
+	return total, err
+}
+
+// CountModel counts the records of the table of T. QueryOption values such as ForOrganization
+// are added to the WHERE clause, organization-owned tables are counted for the organization of the scope.
+func CountModel[T any](opts ...QueryOption) (int64, error) {
+	scope, err := resolveScope(new(T), opts)
+	if err != nil {
+		return 0, err
+	}
+
 	if database.GormDB != nil {
 		var total int64
-		err := database.GormDB.Model(new(T)).Count(&total).Error
+		err := whereConditions(database.GormDB.Model(new(T)), scope).Count(&total).Error
 		return total, err
 	}
 
@@ -342,9 +438,13 @@ func CountModel[T any]() (int64, error) {
 	table := GetTableName(&model)
 
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", table)
-	row := database.SQLDB.QueryRow(query)
+	whereClause, args := buildWhereClause(scope)
+	if whereClause != "" {
+		query += " WHERE " + whereClause
+	}
+	row := database.SQLDB.QueryRow(query, args...)
 
 	var total int64
-	err := row.Scan(&total)
+	err = row.Scan(&total)
 	return total, err
 }
@@ -0,0 +1,253 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"gin/src/utils/tenants"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrOrganizationRequired is returned when a table with an organization_id column is queried
+// without an organization in the context, without ForOrganization and without Unscoped, so a
+// query can never silently cross organizations.
+var ErrOrganizationRequired = errors.New("query on an organization-owned table needs an organization, pass helpers.WithContext(ctx), helpers.ForOrganization(id) or helpers.Unscoped()")
+
+// ErrOrganizationChange is returned when a scoped insert or update would put a record in
+// another organization than the one of its scope.
+var ErrOrganizationChange = errors.New("a scoped write cannot move records to another organization")
+
+// QueryOption narrows the queries built by the generic helpers. It is passed as an extra
+// argument, for example GetModelByID(&project, id, ForOrganization(orgID)), or mixed into the
+// key-value conditions of FindOneByField, FindAllByField, UpdateModelsByFieldWithMap and
+// DeleteModelsByField: FindAllByField(&members, ForOrganization(orgID), "role", "admin").
+// InsertModel, InsertModelBatch and UpdateModelByID fill the scoped columns of the record.
+//
+// Organizations are the tenants of the application. Tables with an organization_id column are
+// always scoped to one organization: the current organization of the request, passed with
+// WithContext(ctx) (see middleware.RequireOrganization and tenants.WithOrganization), or the one
+// named with ForOrganization. Background jobs and lookups across organizations opt out with
+// Unscoped(). GetAllModels, CountModelWithFilters and GetPaginatedData read the organization from
+// the request of their gin.Context themselves.
+type QueryOption func(*queryScope)
+
+type queryScope struct {
+	conditions   []any
+	ctx          context.Context
+	unscoped     bool
+	organization bool
+}
+
+// ForOrganization scopes the query to a single organization by adding organization_id = ?,
+// so records of other organizations can neither be read nor changed.
+func ForOrganization(organizationID int64) QueryOption {
+	return func(scope *queryScope) {
+		scope.conditions = append(scope.conditions, tenants.Column, organizationID)
+		scope.organization = true
+	}
+}
+
+// WithContext scopes queries on organization-owned tables to the organization stored in ctx.
+func WithContext(ctx context.Context) QueryOption {
+	return func(scope *queryScope) {
+		scope.ctx = ctx
+	}
+}
+
+// Unscoped lifts the organization scope of the context, for background jobs, admin tools and
+// lookups that work across organizations on purpose. ForOrganization still applies.
+func Unscoped() QueryOption {
+	return func(scope *queryScope) {
+		scope.unscoped = true
+	}
+}
+
+// resolveScope returns the key-value conditions of the options for the given model, including
+// organization_id = ? for the organization of the context when the table of the model is owned
+// by organizations. It returns ErrOrganizationRequired when the table is owned by organizations
+// but neither the context nor ForOrganization names one and the query is not Unscoped.
+func resolveScope(model any, opts []QueryOption) ([]any, error) {
+	var scope queryScope
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&scope)
+		}
+	}
+
+	if scope.unscoped || !hasColumn(model, tenants.Column) {
+		return scope.conditions, nil
+	}
+
+	// Tabel milik organisasi selalu dibatasi ke organisasi aktif request atau yang disebut ForOrganization
+	organizationID, ok := tenants.FromContext(scope.ctx)
+	if !ok {
+		if !scope.organization {
+			return nil, ErrOrganizationRequired
+		}
+		return scope.conditions, nil
+	}
+	return append(scope.conditions, tenants.Column, organizationID), nil
+}
+
+// splitConditions takes the QueryOption values out of the arguments of the field helpers and
+// returns the remaining key-value conditions followed by the conditions of the scope.
+func splitConditions(model any, args []any) ([]any, error) {
+	var conditions []any
+	var opts []QueryOption
+	for _, arg := range args {
+		if opt, ok := arg.(QueryOption); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		conditions = append(conditions, arg)
+	}
+
+	scope, err := resolveScope(model, opts)
+	if err != nil {
+		return nil, err
+	}
+	return append(conditions, scope...), nil
+}
+
+// refuseOrganizationChange returns ErrOrganizationChange when the conditions scope the update to
+// an organization and the updated fields change the organization_id column.
+func refuseOrganizationChange(updatedFields map[string]interface{}, conditions []any) error {
+	if _, changes := updatedFields[tenants.Column]; !changes {
+		return nil
+	}
+	for i := 0; i < len(conditions); i += 2 {
+		if conditions[i] == tenants.Column {
+			return ErrOrganizationChange
+		}
+	}
+	return nil
+}
+
+// scopeRecord checks a record written by InsertModel, InsertModelBatch or UpdateModelByID against
+// the conditions of the scope. Empty scoped columns are filled in, so a new record lands in the
+// organization of the scope, and a record of another organization gives ErrOrganizationChange.
+func scopeRecord(model any, conditions []any) error {
+	val := reflect.ValueOf(model)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < len(conditions); i += 2 {
+		field, ok := columnField(val, conditions[i].(string))
+		if !ok {
+			continue
+		}
+		want := reflect.ValueOf(conditions[i+1])
+		if !want.IsValid() || !want.Type().ConvertibleTo(field.Type()) {
+			continue
+		}
+		want = want.Convert(field.Type())
+
+		// Kolom kosong diisi dari scope, kolom terisi harus sama dengan scope
+		if field.IsZero() {
+			if field.CanSet() {
+				field.Set(want)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(field.Interface(), want.Interface()) {
+			return ErrOrganizationChange
+		}
+	}
+	return nil
+}
+
+// columnField returns the field of the struct value mapped to the given db column.
+func columnField(val reflect.Value, column string) (reflect.Value, bool) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if strings.Split(typ.Field(i).Tag.Get("db"), ",")[0] == column {
+			return val.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// requestScope prepends the organization of the request to the options, for the helpers that get the
+// gin.Context of the request.
+func requestScope(ctx *gin.Context, opts []QueryOption) []QueryOption {
+	if ctx == nil || ctx.Request == nil {
+		return opts
+	}
+	return append([]QueryOption{WithContext(ctx.Request.Context())}, opts...)
+}
+
+type columnKey struct {
+	typ    reflect.Type
+	column string
+}
+
+var modelColumns sync.Map // columnKey -> bool
+
+// hasColumn reports whether the model has a field mapped to the given db column.
+func hasColumn(model any, column string) bool {
+	typ := reflect.TypeOf(model)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return false
+	}
+
+	key := columnKey{typ: typ, column: column}
+	if cached, ok := modelColumns.Load(key); ok {
+		return cached.(bool)
+	}
+
+	found := false
+	for i := 0; i < typ.NumField(); i++ {
+		if strings.Split(typ.Field(i).Tag.Get("db"), ",")[0] == column {
+			found = true
+			break
+		}
+	}
+	modelColumns.Store(key, found)
+	return found
+}
+
+// whereConditions adds the key-value conditions to a GORM query.
+func whereConditions(query *gorm.DB, conditions []any) *gorm.DB {
+	for i := 0; i < len(conditions); i += 2 {
+		query = query.Where(conditionSQL(conditions[i].(string), "?"), conditions[i+1])
+	}
+	return query
+}
+
+// conditionSQL returns the comparison of a condition key with its placeholder. A key is either a
+// column, compared with "=", or a column followed by an operator such as "last_used_step <"
+// for the compare-and-set updates of UpdateModelsByFieldWithMapCount.
+func conditionSQL(key string, placeholder string) string {
+	if strings.ContainsAny(key, " <>!=") {
+		return key + " " + placeholder
+	}
+	return key + " = " + placeholder
+}
+
+// appendWhereClause joins a native SQL WHERE clause with the scope conditions, numbering the
+// placeholders of the scope after the given args.
+func appendWhereClause(whereClause string, args []any, conditions []any) (string, []any) {
+	if len(conditions) == 0 {
+		return whereClause, args
+	}
+
+	scopeClause, scopeArgs := buildWhereClauseFrom(conditions, len(args))
+	if whereClause == "" {
+		return scopeClause, append(args, scopeArgs...)
+	}
+	return "(" + whereClause + ") AND " + scopeClause, append(args, scopeArgs...)
+}
@@ -0,0 +1,200 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"gin/src/configs/database"
+	"gin/src/entities/organizations"
+	"gin/src/utils/tenants"
+	"testing"
+	"time"
+)
+
+var (
+	organizationA = tenants.WithOrganization(context.Background(), 1)
+	organizationB = tenants.WithOrganization(context.Background(), 2)
+)
+
+// useFakeDB points the generic helpers at an empty in-memory database with the organization
+// tables for the test.
+func useFakeDB(t *testing.T) {
+	t.Helper()
+
+	db, err := openFakeDB(t.Name(), map[string][]string{
+		"memberships": {"uuid", "id", "organization_id", "user_id", "role", "created_at", "updated_at"},
+		"invitations": {"uuid", "id", "organization_id", "email", "role", "invited_by", "token_hash", "expires_at", "accepted_at", "created_at", "updated_at"},
+	})
+	if err != nil {
+		t.Fatalf("failed to open fake database: %v", err)
+	}
+
+	previousSQL, previousGorm := database.SQLDB, database.GormDB
+	database.SQLDB, database.GormDB = db, nil
+	t.Cleanup(func() {
+		database.SQLDB, database.GormDB = previousSQL, previousGorm
+		db.Close()
+	})
+}
+
+// insertMembership stores a membership of organization A and returns its ID.
+func insertMembership(t *testing.T, userID int64) int64 {
+	t.Helper()
+
+	membership := organizations.Membership{UserID: userID, Role: organizations.RoleMember}
+	if err := InsertModel(&membership, WithContext(organizationA)); err != nil {
+		t.Fatalf("InsertModel failed: %v", err)
+	}
+	if membership.OrganizationID != 1 {
+		t.Fatalf("InsertModel should fill organization_id from the context, got %d", membership.OrganizationID)
+	}
+	return membership.ID
+}
+
+// insertInvitation stores an invitation of organization A and returns its ID.
+func insertInvitation(t *testing.T, email string) int64 {
+	t.Helper()
+
+	invitation := organizations.Invitation{Email: email, Role: organizations.RoleMember, InvitedBy: 1, TokenHash: email, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := InsertModel(&invitation, ForOrganization(1)); err != nil {
+		t.Fatalf("InsertModel failed: %v", err)
+	}
+	return invitation.ID
+}
+
+func TestScopedReadAcrossOrganizations(t *testing.T) {
+	useFakeDB(t)
+	id := insertMembership(t, 7)
+
+	tests := []struct {
+		name    string
+		opts    []QueryOption
+		found   bool
+		wantErr error
+	}{
+		{name: "same organization", opts: []QueryOption{WithContext(organizationA)}, found: true},
+		{name: "other organization", opts: []QueryOption{WithContext(organizationB)}},
+		{name: "named organization", opts: []QueryOption{ForOrganization(1)}, found: true},
+		{name: "named other organization", opts: []QueryOption{ForOrganization(2)}},
+		{name: "named organization outside the context", opts: []QueryOption{WithContext(organizationB), ForOrganization(1)}},
+		{name: "unscoped", opts: []QueryOption{Unscoped()}, found: true},
+		{name: "no organization", wantErr: ErrOrganizationRequired},
+		{name: "context without organization", opts: []QueryOption{WithContext(context.Background())}, wantErr: ErrOrganizationRequired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var byID organizations.Membership
+			err := GetModelByID(&byID, id, tt.opts...)
+			checkFound(t, "GetModelByID", err, tt.found, tt.wantErr)
+
+			var byField organizations.Membership
+			args := []any{"user_id", int64(7)}
+			for _, opt := range tt.opts {
+				args = append(args, opt)
+			}
+			err = FindOneByField(&byField, args...)
+			checkFound(t, "FindOneByField", err, tt.found, tt.wantErr)
+
+			var all []organizations.Membership
+			err = FindAllByField(&all, args...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("FindAllByField: expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil || (len(all) == 1) != tt.found {
+				t.Errorf("FindAllByField: expected found=%v, got %d records (%v)", tt.found, len(all), err)
+			}
+
+			total, err := CountModel[organizations.Membership](tt.opts...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("CountModel: expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil || (total == 1) != tt.found {
+				t.Errorf("CountModel: expected found=%v, got %d (%v)", tt.found, total, err)
+			}
+		})
+	}
+}
+
+func checkFound(t *testing.T, helper string, err error, found bool, wantErr error) {
+	t.Helper()
+
+	switch {
+	case wantErr != nil:
+		if !errors.Is(err, wantErr) {
+			t.Errorf("%s: expected %v, got %v", helper, wantErr, err)
+		}
+	case found && err != nil:
+		t.Errorf("%s: expected the record, got %v", helper, err)
+	case !found && err == nil:
+		t.Errorf("%s: the record of another organization must not be found", helper)
+	}
+}
+
+func TestScopedWritesAcrossOrganizations(t *testing.T) {
+	useFakeDB(t)
+	id := insertInvitation(t, "invitee@example.com")
+
+	if err := UpdateModelByIDWithMap[organizations.Invitation](map[string]interface{}{"role": organizations.RoleAdmin}, id, WithContext(organizationB)); err != nil {
+		t.Fatalf("UpdateModelByIDWithMap failed: %v", err)
+	}
+	if err := UpdateModelByID(&organizations.Invitation{Role: organizations.RoleAdmin}, id, ForOrganization(2)); err != nil {
+		t.Fatalf("UpdateModelByID failed: %v", err)
+	}
+	if err := DeleteModelByID(&organizations.Invitation{}, id, WithContext(organizationB)); err != nil {
+		t.Fatalf("DeleteModelByID failed: %v", err)
+	}
+	if err := DeleteModelByID(&organizations.Invitation{}, id); !errors.Is(err, ErrOrganizationRequired) {
+		t.Errorf("DeleteModelByID without an organization: expected ErrOrganizationRequired, got %v", err)
+	}
+
+	var invitation organizations.Invitation
+	if err := GetModelByID(&invitation, id, Unscoped()); err != nil {
+		t.Fatalf("the invitation of organization A must survive writes of organization B: %v", err)
+	}
+	if invitation.Role != organizations.RoleMember || invitation.OrganizationID != 1 {
+		t.Fatalf("organization B changed the invitation of organization A: %+v", invitation)
+	}
+
+	// Undangan organisasi A tidak boleh dipindahkan lewat scope organisasi B
+	if err := UpdateModelByID(&invitation, id, WithContext(organizationB)); !errors.Is(err, ErrOrganizationChange) {
+		t.Errorf("UpdateModelByID: expected ErrOrganizationChange, got %v", err)
+	}
+	if err := UpdateModelByIDWithMap[organizations.Invitation](map[string]interface{}{"organization_id": int64(2)}, id, WithContext(organizationA)); !errors.Is(err, ErrOrganizationChange) {
+		t.Errorf("UpdateModelByIDWithMap: expected ErrOrganizationChange, got %v", err)
+	}
+
+	invitation.Role = organizations.RoleAdmin
+	if err := UpdateModelByID(&invitation, id, WithContext(organizationA)); err != nil {
+		t.Fatalf("UpdateModelByID within the organization failed: %v", err)
+	}
+	if err := GetModelByID(&invitation, id, WithContext(organizationA)); err != nil || invitation.Role != organizations.RoleAdmin {
+		t.Fatalf("the update within the organization was not applied: %+v (%v)", invitation, err)
+	}
+}
+
+func TestScopedInsert(t *testing.T) {
+	useFakeDB(t)
+
+	if err := InsertModel(&organizations.Membership{UserID: 7, Role: organizations.RoleMember}); !errors.Is(err, ErrOrganizationRequired) {
+		t.Errorf("insert without an organization: expected ErrOrganizationRequired, got %v", err)
+	}
+	if err := InsertModel(&organizations.Membership{OrganizationID: 2, UserID: 7, Role: organizations.RoleMember}, WithContext(organizationA)); !errors.Is(err, ErrOrganizationChange) {
+		t.Errorf("insert for another organization: expected ErrOrganizationChange, got %v", err)
+	}
+	if err := InsertModelBatch([]organizations.Membership{{UserID: 7, Role: organizations.RoleMember}}); !errors.Is(err, ErrOrganizationRequired) {
+		t.Errorf("batch insert without an organization: expected ErrOrganizationRequired, got %v", err)
+	}
+
+	membership := organizations.Membership{OrganizationID: 2, UserID: 8, Role: organizations.RoleOwner}
+	if err := InsertModel(&membership, Unscoped()); err != nil {
+		t.Fatalf("unscoped insert failed: %v", err)
+	}
+	if total, err := CountModel[organizations.Membership](WithContext(organizationB)); err != nil || total != 1 {
+		t.Errorf("organization B should see its membership, got %d (%v)", total, err)
+	}
+	if total, err := CountModel[organizations.Membership](WithContext(organizationA)); err != nil || total != 0 {
+		t.Errorf("organization A must not see the membership of organization B, got %d (%v)", total, err)
+	}
+}
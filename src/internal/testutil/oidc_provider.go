@@ -0,0 +1,210 @@
+// Package testutil holds in-process fakes of external services for the tests. It is not
+// imported by the application, so none of it ends up in the production binary.
+package testutil
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"gin/src/utils/oidc"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// FakeOIDCUser is the account the FakeOIDCProvider signs in.
+type FakeOIDCUser struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type fakeOIDCCode struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	user          FakeOIDCUser
+}
+
+// FakeOIDCProvider is an in-process OpenID Connect provider for tests. It serves the discovery
+// document, JWKS, authorization and token endpoints, approves every authorization request for
+// User without a login page and signs ID tokens with a generated RSA key. Serve it with
+// httptest.NewServer and set Issuer to the server URL. TamperClaims, when set, may change the
+// ID token claims before they are signed, to test how a client handles a misbehaving provider.
+type FakeOIDCProvider struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	User         FakeOIDCUser
+	TamperClaims func(claims jwt.MapClaims)
+
+	mu    sync.Mutex
+	key   *rsa.PrivateKey
+	codes map[string]fakeOIDCCode
+}
+
+const fakeOIDCKeyID = "fake-key"
+
+// fakeJSONWebKey is the RSA public key as served in the JWKS of the fake provider.
+type fakeJSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func NewFakeOIDCProvider(clientID string, clientSecret string, user FakeOIDCUser) (*FakeOIDCProvider, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &FakeOIDCProvider{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		User:         user,
+		key:          key,
+		codes:        map[string]fakeOIDCCode{},
+	}, nil
+}
+
+func (f *FakeOIDCProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/.well-known/openid-configuration":
+		f.writeJSON(w, http.StatusOK, oidc.Discovery{
+			Issuer:                f.Issuer,
+			AuthorizationEndpoint: f.Issuer + "/authorize",
+			TokenEndpoint:         f.Issuer + "/token",
+			JWKSURI:               f.Issuer + "/jwks",
+		})
+	case "/jwks":
+		f.writeJSON(w, http.StatusOK, map[string][]fakeJSONWebKey{"keys": {{
+			Kty: "RSA",
+			Kid: fakeOIDCKeyID,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(f.key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(f.key.E)).Bytes()),
+		}}})
+	case "/authorize":
+		location, err := f.Authorize(r.URL.String())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Redirect(w, r, location, http.StatusFound)
+	case "/token":
+		f.token(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// Authorize approves the authorization URL for User and returns the redirect location with code and state,
+// so tests can skip the browser redirect.
+func (f *FakeOIDCProvider) Authorize(authorizationURL string) (string, error) {
+	parsed, err := url.Parse(authorizationURL)
+	if err != nil {
+		return "", err
+	}
+	query := parsed.Query()
+
+	if query.Get("client_id") != f.ClientID {
+		return "", fmt.Errorf("unknown client_id")
+	}
+	if query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		return "", fmt.Errorf("only the code flow with S256 PKCE is supported")
+	}
+
+	code := fmt.Sprintf("code-%d", time.Now().UnixNano())
+	f.mu.Lock()
+	f.codes[code] = fakeOIDCCode{
+		clientID:      query.Get("client_id"),
+		redirectURI:   query.Get("redirect_uri"),
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		user:          f.User,
+	}
+	f.mu.Unlock()
+
+	redirect, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		return "", err
+	}
+	redirectQuery := redirect.Query()
+	redirectQuery.Set("code", code)
+	redirectQuery.Set("state", query.Get("state"))
+	redirect.RawQuery = redirectQuery.Encode()
+	return redirect.String(), nil
+}
+
+func (f *FakeOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		f.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != f.ClientID || clientSecret != f.ClientSecret {
+		f.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	f.mu.Lock()
+	code, exists := f.codes[r.PostForm.Get("code")]
+	delete(f.codes, r.PostForm.Get("code"))
+	f.mu.Unlock()
+
+	if !exists || code.redirectURI != r.PostForm.Get("redirect_uri") || oidc.Challenge(r.PostForm.Get("code_verifier")) != code.codeChallenge {
+		f.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            f.Issuer,
+		"sub":            code.user.Subject,
+		"aud":            f.ClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          code.nonce,
+		"email":          code.user.Email,
+		"email_verified": code.user.EmailVerified,
+		"name":           code.user.Name,
+	}
+	if f.TamperClaims != nil {
+		f.TamperClaims(claims)
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = fakeOIDCKeyID
+
+	signed, err := idToken.SignedString(f.key)
+	if err != nil {
+		f.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	f.writeJSON(w, http.StatusOK, oidc.TokenResponse{
+		AccessToken: fmt.Sprintf("fake-access-%d", now.UnixNano()),
+		IDToken:     signed,
+		TokenType:   "Bearer",
+		ExpiresIn:   3600,
+	})
+}
+
+func (f *FakeOIDCProvider) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
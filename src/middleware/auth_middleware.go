@@ -1,7 +1,14 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
+	"gin/src/entities/audits"
+	"gin/src/entities/auth"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	"gin/src/utils/auditors"
+	"gin/src/utils/loggers"
 	"net/http"
 	"os"
 	"strings"
@@ -20,9 +27,29 @@ import (
 //	Authorization: Bearer <token>
 //
 // The middleware will extract the user_id claim from the token and store it in the gin.Context under the key "user_id".
+// The token must still be active in the access_tokens table, so revoked tokens (logout, revoked sessions) are rejected.
+// The session of the token is stored under the key "session_id" and its last used time is refreshed.
+// An "org" claim is stored under "token_organization" for RequireOrganization.
+// Tokens of suspended or banned users are refused with 403 Forbidden and an "account_suspended"
+// or "account_banned" code.
+//
+// Personal access tokens are accepted as well, either in the X-API-Key header or as a Bearer token
+// starting with "pat_". They set the same "user_id" key, plus "token_scopes" with the scopes granted
+// to the token, which RequireScope checks. Access tokens issued to OAuth clients set "token_scopes"
+// the same way, together with "oauth_client_id".
+//
+// Impersonation tokens keep the impersonated user under "user_id" and store the admin under
+// "real_user_id". The response gets an X-Impersonated-By header and every such request is
+// written to the audit sink after the handler ran.
 // The middleware will then call the next handler in the chain.
 func JWTAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// API key dari machine client
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			authenticatePersonalAccessToken(c, apiKey)
+			return
+		}
+
 		// Ambil token dari Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -39,6 +66,11 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if strings.HasPrefix(tokenString, auth.PersonalAccessTokenPrefix) {
+			authenticatePersonalAccessToken(c, tokenString)
+			return
+		}
+
 		// Load JWT secret
 		secret := os.Getenv("JWT_SECRET")
 		if secret == "" {
@@ -91,6 +123,149 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Organisasi aktif bisa dibawa token lewat claim "org" (lihat RequireOrganization)
+		if organization, ok := claims["org"].(string); ok {
+			c.Set("token_organization", organization)
+		}
+
+		// Pastikan token belum di-revoke (logout / sesi dicabut)
+		var accessToken auth.AccessToken
+		if err := helpers.FindOneByField(&accessToken, "token", tokenString, "revoked", false); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+		// Akun yang disuspend / diblokir tidak boleh memakai token apa pun
+		if !checkAccountStatus(c, accessToken.UserID) {
+			return
+		}
+		c.Set("session_id", accessToken.SessionID)
+		touchSession(accessToken.SessionID)
+
+		// Token dari OAuth client hanya boleh dipakai sesuai scope yang disetujui user
+		if accessToken.IsScoped() {
+			c.Set("token_scopes", accessToken.ScopeList())
+			c.Set("oauth_client_id", accessToken.OauthClientID)
+		}
+
+		// Token impersonation: user_id adalah user yang dilihat, real_user_id adalah admin
+		if accessToken.ImpersonatorID != 0 {
+			c.Set("real_user_id", uint(accessToken.ImpersonatorID))
+			if act, ok := claims["act"].(map[string]interface{}); ok {
+				if actor, ok := act["sub"].(string); ok {
+					c.Header("X-Impersonated-By", actor)
+				}
+			}
+			c.Next()
+			auditImpersonatedRequest(c, accessToken.ImpersonatorID, accessToken.UserID)
+			return
+		}
+
 		c.Next()
 	}
 }
+
+// auditImpersonatedRequest writes a request made with an impersonation token to the audit sink.
+func auditImpersonatedRequest(c *gin.Context, adminID int64, userID int64) {
+	err := auditors.Default().Record(c.Request.Context(), audits.AuditLog{
+		Event:     audits.EventImpersonatedRequest,
+		ActorID:   adminID,
+		UserID:    userID,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.RequestURI(),
+		Status:    c.Writer.Status(),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+	if err != nil {
+		loggers.Log.Error("failed to audit impersonated request", map[string]interface{}{
+			"admin_id": adminID,
+			"user_id":  userID,
+			"error":    err.Error(),
+		})
+	}
+}
+
+// checkAccountStatus aborts the request with 403 Forbidden and the error code of the status
+// when the user is suspended or banned. It reports whether the request may continue.
+func checkAccountStatus(c *gin.Context, userID int64) bool {
+	_, ok := loadActiveUser(c, userID)
+	return ok
+}
+
+// loadActiveUser loads the user behind the token and aborts the request like checkAccountStatus
+// when the account is suspended or banned. The user is returned for further checks.
+func loadActiveUser(c *gin.Context, userID int64) (*users.User, bool) {
+	var user users.User
+	if err := helpers.GetModelByID(&user, userID); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		c.Abort()
+		return nil, false
+	}
+
+	var statusErr *users.AccountStatusError
+	if err := user.StatusError(time.Now()); errors.As(err, &statusErr) {
+		c.JSON(http.StatusForbidden, gin.H{"error": statusErr.Error(), "code": statusErr.ErrorCode()})
+		c.Abort()
+		return nil, false
+	}
+	return &user, true
+}
+
+// sessionTouchInterval limits how often the last used time of a session is written.
+const sessionTouchInterval = time.Minute
+
+// touchSession updates the last used time of the session, at most once per sessionTouchInterval.
+func touchSession(sessionID int64) {
+	if sessionID == 0 {
+		return
+	}
+
+	var session auth.Session
+	if err := helpers.GetModelByID(&session, sessionID); err != nil {
+		return
+	}
+	if time.Since(session.LastUsedAt) < sessionTouchInterval {
+		return
+	}
+
+	_ = helpers.UpdateModelByIDWithMap[auth.Session](map[string]interface{}{"last_used_at": time.Now()}, sessionID)
+}
+
+// authenticatePersonalAccessToken authenticates the request with a personal access token and calls the next handler.
+func authenticatePersonalAccessToken(c *gin.Context, tokenString string) {
+	var token auth.PersonalAccessToken
+	if err := helpers.FindOneByField(&token, "token_hash", helpers.HashToken(tokenString), "revoked", false); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked API key"})
+		c.Abort()
+		return
+	}
+
+	now := time.Now()
+	if token.IsExpired(now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "API key has expired"})
+		c.Abort()
+		return
+	}
+
+	user, ok := loadActiveUser(c, token.UserID)
+	if !ok {
+		return
+	}
+	// Akun yang dijadwalkan untuk dihapus tidak bisa dipakai lewat API key, hanya login ulang yang membatalkan
+	if user.DeletionPending {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked API key"})
+		c.Abort()
+		return
+	}
+
+	c.Set("user_id", uint(token.UserID))
+	c.Set("token_scopes", token.ScopeList())
+
+	// Catat waktu terakhir dipakai, dibatasi seperti sesi
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= sessionTouchInterval {
+		_ = helpers.UpdateModelByIDWithMap[auth.PersonalAccessToken](map[string]interface{}{"last_used_at": now}, token.ID)
+	}
+
+	c.Next()
+}
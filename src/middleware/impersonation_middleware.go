@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"gin/src/helpers"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DenyImpersonation refuses the request with a 403 Forbidden response when it is made with an
+// impersonation token. Use it on routes that change credentials or sessions, which support staff
+// should not do on behalf of a user. It must be used after JWTAuthMiddleware.
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, impersonating := helpers.GetRealUserID(c); impersonating {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This action is not available while impersonating a user"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"gin/src/helpers"
+	"gin/src/services/role_services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission refuses the request with a 403 Forbidden response unless the authenticated
+// user has every one of the given permissions through their roles. It must be used after
+// JWTAuthMiddleware because it reads the "user_id" from the context. Permissions are looked up
+// through role_services.Default, which caches them per user.
+//
+// Example:
+//
+//	v1.GET("/users", middleware.RequirePermission("users.list"), user.GetAllUsers(userService))
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := helpers.GetUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := role_services.Default().HasPermission(userID, permissions...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not check permissions"})
+			c.Abort()
+			return
+		}
+
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to access this resource"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
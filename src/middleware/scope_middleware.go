@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"gin/src/entities/auth"
+	"gin/src/helpers"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireScope refuses the request with a 403 Forbidden response when it is authenticated with a scoped
+// token (such as a personal access token) that was not granted every one of the given scopes.
+// Requests authenticated with a regular session token are not scoped and always pass.
+// It must be used after JWTAuthMiddleware.
+//
+// Example:
+//
+//	v1.GET("/user/profile", middleware.RequireScope(auth.ScopeProfileRead), user.GetProfile)
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, scoped := helpers.GetTokenScopes(c)
+		if !scoped {
+			c.Next()
+			return
+		}
+
+		for _, scope := range scopes {
+			if !auth.ScopesAllow(granted, scope) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Token is missing the required scope: " + scope})
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
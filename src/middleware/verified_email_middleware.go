@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"gin/src/configs/security"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerifiedEmail refuses requests of users that have not verified their email address
+// with a 403 Forbidden response. It must be used after JWTAuthMiddleware because it reads the
+// "user_id" from the context. The check only runs when EMAIL_VERIFICATION_ENFORCE is set to
+// "routes", otherwise the middleware lets every request through.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	config := security.LoadEmailVerificationConfig()
+
+	return func(c *gin.Context) {
+		if config.Enforce != security.EnforceVerificationRoutes {
+			c.Next()
+			return
+		}
+
+		userID, err := helpers.GetUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		var user users.User
+		if err := helpers.GetModelByID(&user, userID); err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
+		if !user.IsEmailVerified() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Email address is not verified"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
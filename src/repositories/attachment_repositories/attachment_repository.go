@@ -0,0 +1,85 @@
+package attachment_repositories
+
+import (
+	"fmt"
+	"gin/src/entities/attachments"
+	"gin/src/helpers"
+	"sort"
+)
+
+type AttachmentRepositoryInterface interface {
+	CreateAttachment(attachment *attachments.Attachment) error
+	FindAttachmentByUUID(uuid string) (*attachments.Attachment, error)
+	FindAttachmentsByOwner(ownerType string, ownerID int64) ([]attachments.Attachment, error)
+	FindAttachmentsByChecksum(checksum string) ([]attachments.Attachment, error)
+	FindAllAttachments() ([]attachments.Attachment, error)
+	FindAttachmentsByUserID(userID int64) ([]attachments.Attachment, error)
+	DetachAttachmentsFromUser(userID int64) error
+	DeleteAttachment(id int64) error
+}
+
+type attachmentRepository struct{}
+
+func NewAttachmentRepository() *attachmentRepository {
+	return &attachmentRepository{}
+}
+
+func (r *attachmentRepository) CreateAttachment(attachment *attachments.Attachment) error {
+	return helpers.InsertModel(attachment)
+}
+
+func (r *attachmentRepository) FindAttachmentByUUID(uuid string) (*attachments.Attachment, error) {
+	var attachment attachments.Attachment
+	if err := helpers.FindOneByField(&attachment, "uuid", uuid); err != nil {
+		return nil, fmt.Errorf("attachment not found: %w", err)
+	}
+	return &attachment, nil
+}
+
+// FindAttachmentsByOwner mengambil lampiran milik satu baris, urut dari yang terlama
+func (r *attachmentRepository) FindAttachmentsByOwner(ownerType string, ownerID int64) ([]attachments.Attachment, error) {
+	var list []attachments.Attachment
+	if err := helpers.FindAllByField(&list, "owner_type", ownerType, "owner_id", ownerID); err != nil {
+		return nil, err
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// FindAttachmentsByChecksum mengambil semua lampiran dengan isi file yang sama
+func (r *attachmentRepository) FindAttachmentsByChecksum(checksum string) ([]attachments.Attachment, error) {
+	var list []attachments.Attachment
+	if err := helpers.FindAllByField(&list, "checksum", checksum); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// FindAllAttachments mengambil semua lampiran, dipakai job pembersih file yatim
+func (r *attachmentRepository) FindAllAttachments() ([]attachments.Attachment, error) {
+	var list []attachments.Attachment
+	if err := helpers.FindAllByField(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// FindAttachmentsByUserID mengambil semua lampiran yang diupload user, di owner mana pun
+func (r *attachmentRepository) FindAttachmentsByUserID(userID int64) ([]attachments.Attachment, error) {
+	var list []attachments.Attachment
+	if err := helpers.FindAllByField(&list, "user_id", userID); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// DetachAttachmentsFromUser melepas user sebagai pengupload, lampiran tetap milik owner-nya
+func (r *attachmentRepository) DetachAttachmentsFromUser(userID int64) error {
+	return helpers.UpdateModelsByFieldWithMap[attachments.Attachment](map[string]interface{}{"user_id": 0}, "user_id", userID)
+}
+
+func (r *attachmentRepository) DeleteAttachment(id int64) error {
+	return helpers.DeleteModelByID(&attachments.Attachment{}, id)
+}
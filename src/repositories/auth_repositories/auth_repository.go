@@ -6,22 +6,73 @@ import (
 	"gin/src/entities/auth"
 	"gin/src/entities/users"
 	"gin/src/helpers"
+	"gin/src/utils/hashers"
+	"sort"
 	"strings"
 	"time"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthRepositoryInterface interface {
 	Register(ctx context.Context, email string, username string, password string) (map[string]interface{}, error)
 	FindByEmail(email string) (*users.User, error)
 	FindByUsername(username string) (*users.User, error)
+	FindByUUID(uuid string) (*users.User, error)
+	FindByID(id int64) (*users.User, error)
 	CreateUser(user *users.User) error
-	SaveTokens(userID int64, accessToken string, accessExp time.Time, refreshToken string, refreshExp time.Time) error
+	SaveTokens(access *auth.AccessToken, refresh *auth.RefreshToken) error
+	SaveAccessToken(access *auth.AccessToken) error
+	FindAccessToken(token string) (*auth.AccessToken, error)
 	FindRefreshToken(token string) (*auth.RefreshToken, error)
-	MarkRefreshTokenAsUsed(id int64) error
+	ClaimRefreshToken(id int64) (bool, error)
 	MarkTokenAsRevoked(tokenID int64) error
+	RevokeRefreshToken(tokenID int64) error
 	FindTokenByUserIDAndToken(userID int64, tokenString string) (*auth.AccessToken, error)
+	CreateSession(session *auth.Session) error
+	TouchSession(sessionID int64, ipAddress string) error
+	FindSessionsByUserID(userID int64) ([]auth.Session, error)
+	FindSessionByUUID(userID int64, uuid string) (*auth.Session, error)
+	RevokeSession(sessionID int64) error
+	RevokeAllUserTokens(userID int64) error
+	CreatePasswordResetToken(token *auth.PasswordResetToken) error
+	FindPasswordResetToken(tokenHash string) (*auth.PasswordResetToken, error)
+	MarkPasswordResetTokenAsUsed(id int64) (bool, error)
+	CreateMagicLinkToken(token *auth.MagicLinkToken) error
+	FindMagicLinkToken(tokenHash string) (*auth.MagicLinkToken, error)
+	MarkMagicLinkTokenAsUsed(id int64) (bool, error)
+	UpdatePassword(userID int64, hashedPassword string) error
+	AddPasswordHistory(userID int64, hashedPassword string, keep int) error
+	FindPasswordHistory(userID int64) ([]auth.PasswordHistory, error)
+	CancelAccountDeletion(userID int64) error
+	PurgeUserCredentials(userID int64) error
+	MarkEmailAsVerified(userID int64) error
+	FindTwoFactorByUserID(userID int64) (*auth.TwoFactor, error)
+	SaveTwoFactor(twoFactor *auth.TwoFactor) error
+	ConfirmTwoFactor(id int64, step int64) error
+	UpdateTwoFactorLastUsedStep(id int64, step int64) (bool, error)
+	DeleteTwoFactor(userID int64) error
+	ReplaceRecoveryCodes(userID int64, codeHashes []string) error
+	UseRecoveryCode(userID int64, codeHash string) error
+	FindLoginAttempt(key string) (*auth.LoginAttempt, error)
+	SaveLoginAttempt(attempt *auth.LoginAttempt, previousFailures int) (bool, error)
+	ClaimLoginAttempt(attempt *auth.LoginAttempt, notAfter time.Time, now time.Time) (bool, error)
+	FindExpiredLoginLocks(now time.Time) ([]auth.LoginAttempt, error)
+	ReleaseLoginLock(id int64, now time.Time) (bool, error)
+	DeleteLoginAttempt(key string) error
+	CreateLoginEvent(event *auth.LoginEvent) error
+	FindLoginEventsByUserID(userID int64) ([]auth.LoginEvent, error)
+	CreatePersonalAccessToken(token *auth.PersonalAccessToken) error
+	FindPersonalAccessTokensByUserID(userID int64) ([]auth.PersonalAccessToken, error)
+	FindPersonalAccessTokenByUUID(userID int64, uuid string) (*auth.PersonalAccessToken, error)
+	RevokePersonalAccessToken(id int64) error
+	RevokeAllPersonalAccessTokens(userID int64) error
+	CreateOIDCState(state *auth.OIDCState) error
+	FindOIDCState(stateHash string) (*auth.OIDCState, error)
+	MarkOIDCStateAsUsed(id int64) error
+	FindExternalIdentity(provider string, subject string) (*auth.ExternalIdentity, error)
+	CreateExternalIdentity(identity *auth.ExternalIdentity) error
+	FindExternalIdentitiesByUserID(userID int64) ([]auth.ExternalIdentity, error)
+	FindExternalIdentityByUUID(userID int64, uuid string) (*auth.ExternalIdentity, error)
+	DeleteExternalIdentity(id int64) error
 }
 
 type authRepository struct{}
@@ -43,7 +94,7 @@ func (r *authRepository) Register(ctx context.Context, email string, username st
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := hashers.Default().Hash(password)
 	if err != nil {
 		return nil, fmt.Errorf("could not hash password: %w", err)
 	}
@@ -53,6 +104,7 @@ func (r *authRepository) Register(ctx context.Context, email string, username st
 		Email:    email,
 		Username: username,
 		Password: string(hashedPassword),
+		Status:   users.StatusActive,
 	}
 
 	// Save the user in the database
@@ -90,27 +142,50 @@ func (r *authRepository) FindByUsername(username string) (*users.User, error) {
 	return &user, nil
 }
 
+func (r *authRepository) FindByUUID(uuid string) (*users.User, error) {
+	var user users.User
+	err := helpers.FindOneByField(&user, "uuid", uuid)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *authRepository) FindByID(id int64) (*users.User, error) {
+	var user users.User
+	if err := helpers.GetModelByID(&user, id); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
 func (r *authRepository) CreateUser(user *users.User) error {
 	return helpers.InsertModel(user)
 }
 
-func (r *authRepository) SaveTokens(userID int64, accessToken string, accessExp time.Time, refreshToken string, refreshExp time.Time) error {
-	access := auth.AccessToken{
-		UserID:    userID,
-		Token:     accessToken,
-		ExpiresAt: accessExp,
-	}
-	if err := helpers.InsertModel(&access); err != nil {
+// SaveTokens menyimpan pasangan access token dan refresh token,
+// refresh token akan otomatis dihubungkan ke access token yang baru dibuat
+func (r *authRepository) SaveTokens(access *auth.AccessToken, refresh *auth.RefreshToken) error {
+	if err := helpers.InsertModel(access); err != nil {
 		return fmt.Errorf("failed insert access token: %w", err)
 	}
 
-	refresh := auth.RefreshToken{
-		UserID:        userID,
-		AccessTokenID: access.ID,
-		Token:         refreshToken,
-		ExpiresAt:     refreshExp,
+	refresh.UserID = access.UserID
+	refresh.AccessTokenID = access.ID
+	return helpers.InsertModel(refresh)
+}
+
+// SaveAccessToken menyimpan access token tanpa refresh token
+func (r *authRepository) SaveAccessToken(access *auth.AccessToken) error {
+	return helpers.InsertModel(access)
+}
+
+func (r *authRepository) FindAccessToken(token string) (*auth.AccessToken, error) {
+	var access auth.AccessToken
+	if err := helpers.FindOneByField(&access, "token", token); err != nil {
+		return nil, fmt.Errorf("token not found: %w", err)
 	}
-	return helpers.InsertModel(&refresh)
+	return &access, nil
 }
 
 func (r *authRepository) FindRefreshToken(token string) (*auth.RefreshToken, error) {
@@ -121,11 +196,11 @@ func (r *authRepository) FindRefreshToken(token string) (*auth.RefreshToken, err
 	return &refresh, nil
 }
 
-func (r *authRepository) MarkRefreshTokenAsUsed(id int64) error {
-	refresh := auth.RefreshToken{
-		Claimed: true,
-	}
-	return helpers.UpdateModelByID(&refresh, id)
+// ClaimRefreshToken menandai refresh token terpakai hanya kalau belum terpakai,
+// false berarti request lain sudah menukarnya lebih dulu
+func (r *authRepository) ClaimRefreshToken(id int64) (bool, error) {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[auth.RefreshToken](map[string]interface{}{"claimed": true}, "id", id, "claimed", false, "revoked", false)
+	return affected == 1, err
 }
 
 // MarkTokenAsRevoked menandai token sebagai revoked di database
@@ -140,6 +215,11 @@ func (r *authRepository) MarkTokenAsRevoked(tokenID int64) error {
 	return helpers.UpdateModelByIDWithMap[auth.AccessToken](updatedFields, tokenID)
 }
 
+// RevokeRefreshToken menandai satu refresh token sebagai revoked, untuk token yang tidak terikat sesi
+func (r *authRepository) RevokeRefreshToken(tokenID int64) error {
+	return helpers.UpdateModelByIDWithMap[auth.RefreshToken](map[string]interface{}{"revoked": true}, tokenID)
+}
+
 // FindTokenByUserIDAndToken mencari token berdasarkan user_id dan token string
 func (r *authRepository) FindTokenByUserIDAndToken(userID int64, tokenString string) (*auth.AccessToken, error) {
 	var token auth.AccessToken
@@ -151,3 +231,434 @@ func (r *authRepository) FindTokenByUserIDAndToken(userID int64, tokenString str
 	}
 	return &token, nil
 }
+
+// CreateSession menyimpan sesi login baru milik user
+func (r *authRepository) CreateSession(session *auth.Session) error {
+	if session.LastUsedAt.IsZero() {
+		session.LastUsedAt = time.Now()
+	}
+	return helpers.InsertModel(session)
+}
+
+// TouchSession memperbarui waktu terakhir sesi dipakai, beserta IP jika diberikan
+func (r *authRepository) TouchSession(sessionID int64, ipAddress string) error {
+	updatedFields := map[string]interface{}{
+		"last_used_at": time.Now(),
+	}
+	if ipAddress != "" {
+		updatedFields["ip_address"] = ipAddress
+	}
+	return helpers.UpdateModelByIDWithMap[auth.Session](updatedFields, sessionID)
+}
+
+// FindSessionsByUserID mengambil semua sesi aktif (belum di-revoke) milik user
+func (r *authRepository) FindSessionsByUserID(userID int64) ([]auth.Session, error) {
+	var sessions []auth.Session
+	if err := helpers.FindAllByField(&sessions, "user_id", userID, "revoked", false); err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// FindSessionByUUID mencari sesi aktif berdasarkan uuid, hanya milik user yang bersangkutan
+func (r *authRepository) FindSessionByUUID(userID int64, uuid string) (*auth.Session, error) {
+	var session auth.Session
+	if err := helpers.FindOneByField(&session, "user_id", userID, "uuid", uuid, "revoked", false); err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+	return &session, nil
+}
+
+// RevokeSession menandai sesi beserta semua access dan refresh token di dalamnya sebagai revoked
+func (r *authRepository) RevokeSession(sessionID int64) error {
+	if err := helpers.UpdateModelsByFieldWithMap[auth.AccessToken](map[string]interface{}{"revoked": true}, "session_id", sessionID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	if err := helpers.UpdateModelsByFieldWithMap[auth.RefreshToken](map[string]interface{}{"revoked": true}, "session_id", sessionID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return helpers.UpdateModelByIDWithMap[auth.Session](map[string]interface{}{"revoked": true}, sessionID)
+}
+
+// RevokeAllUserTokens menandai semua access token, refresh token dan sesi milik user sebagai revoked
+func (r *authRepository) RevokeAllUserTokens(userID int64) error {
+	if err := helpers.UpdateModelsByFieldWithMap[auth.AccessToken](map[string]interface{}{"revoked": true}, "user_id", userID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	if err := helpers.UpdateModelsByFieldWithMap[auth.RefreshToken](map[string]interface{}{"revoked": true}, "user_id", userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+	return helpers.UpdateModelsByFieldWithMap[auth.Session](map[string]interface{}{"revoked": true}, "user_id", userID)
+}
+
+// CreatePasswordResetToken menyimpan token reset password baru,
+// token lama milik user yang belum terpakai otomatis dibatalkan
+func (r *authRepository) CreatePasswordResetToken(token *auth.PasswordResetToken) error {
+	if err := helpers.UpdateModelsByFieldWithMap[auth.PasswordResetToken](map[string]interface{}{"used": true}, "user_id", token.UserID, "used", false); err != nil {
+		return fmt.Errorf("failed to invalidate previous reset tokens: %w", err)
+	}
+	return helpers.InsertModel(token)
+}
+
+// FindPasswordResetToken mencari token reset password yang belum terpakai berdasarkan hash
+func (r *authRepository) FindPasswordResetToken(tokenHash string) (*auth.PasswordResetToken, error) {
+	var token auth.PasswordResetToken
+	if err := helpers.FindOneByField(&token, "token_hash", tokenHash, "used", false); err != nil {
+		return nil, fmt.Errorf("reset token not found: %w", err)
+	}
+	return &token, nil
+}
+
+// MarkPasswordResetTokenAsUsed menandai token terpakai hanya kalau belum terpakai,
+// false berarti request lain sudah lebih dulu memakai token ini
+func (r *authRepository) MarkPasswordResetTokenAsUsed(id int64) (bool, error) {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[auth.PasswordResetToken](map[string]interface{}{"used": true}, "id", id, "used", false)
+	return affected == 1, err
+}
+
+// CreateMagicLinkToken menyimpan token magic link baru,
+// link lama milik user yang belum terpakai otomatis dibatalkan
+func (r *authRepository) CreateMagicLinkToken(token *auth.MagicLinkToken) error {
+	if err := helpers.UpdateModelsByFieldWithMap[auth.MagicLinkToken](map[string]interface{}{"used": true}, "user_id", token.UserID, "used", false); err != nil {
+		return fmt.Errorf("failed to invalidate previous magic links: %w", err)
+	}
+	return helpers.InsertModel(token)
+}
+
+// FindMagicLinkToken mencari token magic link yang belum terpakai berdasarkan hash
+func (r *authRepository) FindMagicLinkToken(tokenHash string) (*auth.MagicLinkToken, error) {
+	var token auth.MagicLinkToken
+	if err := helpers.FindOneByField(&token, "token_hash", tokenHash, "used", false); err != nil {
+		return nil, fmt.Errorf("magic link not found: %w", err)
+	}
+	return &token, nil
+}
+
+// MarkMagicLinkTokenAsUsed menandai link terpakai hanya kalau belum terpakai,
+// false berarti request lain sudah lebih dulu memakai link ini
+func (r *authRepository) MarkMagicLinkTokenAsUsed(id int64) (bool, error) {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[auth.MagicLinkToken](map[string]interface{}{"used": true}, "id", id, "used", false)
+	return affected == 1, err
+}
+
+// UpdatePassword menyimpan password user yang sudah di-hash
+func (r *authRepository) UpdatePassword(userID int64, hashedPassword string) error {
+	return helpers.UpdateModelByIDWithMap[users.User](map[string]interface{}{"password": hashedPassword}, userID)
+}
+
+// AddPasswordHistory menyimpan hash password yang baru dipasang dan menghapus riwayat
+// yang lebih lama dari `keep` password terakhir
+func (r *authRepository) AddPasswordHistory(userID int64, hashedPassword string, keep int) error {
+	if err := helpers.InsertModel(&auth.PasswordHistory{UserID: userID, PasswordHash: hashedPassword}); err != nil {
+		return err
+	}
+
+	history, err := r.FindPasswordHistory(userID)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(history); i++ {
+		if err := helpers.DeleteModelByID(&auth.PasswordHistory{}, history[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindPasswordHistory mengambil riwayat password user, yang terbaru lebih dulu
+func (r *authRepository) FindPasswordHistory(userID int64) ([]auth.PasswordHistory, error) {
+	var history []auth.PasswordHistory
+	if err := helpers.FindAllByField(&history, "user_id", userID); err != nil {
+		return nil, err
+	}
+	sort.Slice(history, func(i, j int) bool {
+		return history[i].ID > history[j].ID
+	})
+	return history, nil
+}
+
+// CancelAccountDeletion membatalkan penghapusan akun yang masih dalam masa tenggang
+func (r *authRepository) CancelAccountDeletion(userID int64) error {
+	return helpers.UpdateModelByIDWithMap[users.User](map[string]interface{}{
+		"deletion_pending":      false,
+		"deletion_scheduled_at": nil,
+	}, userID)
+}
+
+// PurgeUserCredentials menghapus semua token, sesi dan kredensial lain milik user
+// saat akunnya dianonimkan
+func (r *authRepository) PurgeUserCredentials(userID int64) error {
+	purges := []func() error{
+		func() error { return helpers.DeleteModelsByField[auth.AccessToken]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.RefreshToken]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.Session]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.PersonalAccessToken]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.PasswordResetToken]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.MagicLinkToken]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.PasswordHistory]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.RecoveryCode]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.TwoFactor]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.ExternalIdentity]("user_id", userID) },
+		func() error { return helpers.DeleteModelsByField[auth.LoginEvent]("user_id", userID) },
+	}
+	for _, purge := range purges {
+		if err := purge(); err != nil {
+			return fmt.Errorf("failed to purge credentials: %w", err)
+		}
+	}
+	return nil
+}
+
+// MarkEmailAsVerified mengisi email_verified_at user dengan waktu sekarang
+func (r *authRepository) MarkEmailAsVerified(userID int64) error {
+	return helpers.UpdateModelByIDWithMap[users.User](map[string]interface{}{"email_verified_at": time.Now()}, userID)
+}
+
+// FindTwoFactorByUserID mencari data TOTP milik user
+func (r *authRepository) FindTwoFactorByUserID(userID int64) (*auth.TwoFactor, error) {
+	var twoFactor auth.TwoFactor
+	if err := helpers.FindOneByField(&twoFactor, "user_id", userID); err != nil {
+		return nil, fmt.Errorf("two factor not found: %w", err)
+	}
+	return &twoFactor, nil
+}
+
+// SaveTwoFactor menyimpan secret TOTP baru, enrolment lama yang belum dikonfirmasi akan diganti
+func (r *authRepository) SaveTwoFactor(twoFactor *auth.TwoFactor) error {
+	if err := helpers.DeleteModelsByField[auth.TwoFactor]("user_id", twoFactor.UserID); err != nil {
+		return fmt.Errorf("failed to remove previous enrolment: %w", err)
+	}
+	return helpers.InsertModel(twoFactor)
+}
+
+// ConfirmTwoFactor mengaktifkan TOTP setelah user memasukkan kode yang valid
+func (r *authRepository) ConfirmTwoFactor(id int64, step int64) error {
+	return helpers.UpdateModelByIDWithMap[auth.TwoFactor](map[string]interface{}{
+		"confirmed_at":   time.Now(),
+		"last_used_step": step,
+	}, id)
+}
+
+// UpdateTwoFactorLastUsedStep menyimpan time step TOTP hanya kalau lebih baru dari yang tersimpan,
+// false berarti kode untuk step ini (atau yang lebih baru) sudah dipakai request lain
+func (r *authRepository) UpdateTwoFactorLastUsedStep(id int64, step int64) (bool, error) {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[auth.TwoFactor](map[string]interface{}{"last_used_step": step}, "id", id, "last_used_step <", step)
+	return affected == 1, err
+}
+
+// DeleteTwoFactor menonaktifkan TOTP user beserta semua recovery code-nya
+func (r *authRepository) DeleteTwoFactor(userID int64) error {
+	if err := helpers.DeleteModelsByField[auth.RecoveryCode]("user_id", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	return helpers.DeleteModelsByField[auth.TwoFactor]("user_id", userID)
+}
+
+// ReplaceRecoveryCodes menghapus recovery code lama dan menyimpan hash recovery code yang baru
+func (r *authRepository) ReplaceRecoveryCodes(userID int64, codeHashes []string) error {
+	if err := helpers.DeleteModelsByField[auth.RecoveryCode]("user_id", userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]auth.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, auth.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return helpers.InsertModelBatch(codes)
+}
+
+// UseRecoveryCode menandai recovery code sebagai terpakai, error jika kode tidak ada atau sudah dipakai
+// Update bersyarat used = false, jadi kode yang sama tidak bisa dipakai dua request sekaligus
+func (r *authRepository) UseRecoveryCode(userID int64, codeHash string) error {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[auth.RecoveryCode](map[string]interface{}{"used": true}, "user_id", userID, "code_hash", codeHash, "used", false)
+	if err != nil {
+		return err
+	}
+	if affected != 1 {
+		return fmt.Errorf("recovery code not found")
+	}
+	return nil
+}
+
+// FindLoginAttempt mencari catatan login gagal berdasarkan key (account atau ip)
+func (r *authRepository) FindLoginAttempt(key string) (*auth.LoginAttempt, error) {
+	var attempt auth.LoginAttempt
+	if err := helpers.FindOneByField(&attempt, "attempt_key", key); err != nil {
+		return nil, fmt.Errorf("login attempt not found: %w", err)
+	}
+	return &attempt, nil
+}
+
+// SaveLoginAttempt menyimpan catatan login gagal, insert jika baru dan update jika sudah ada.
+// Update hanya berhasil jika jumlah gagal di database masih previousFailures, sehingga dua
+// request paralel tidak saling menimpa hitungan. Mengembalikan false jika kalah balapan.
+func (r *authRepository) SaveLoginAttempt(attempt *auth.LoginAttempt, previousFailures int) (bool, error) {
+	if attempt.ID == 0 {
+		// Key unik, insert paralel yang kalah dianggap konflik dan diulang oleh pemanggil
+		err := helpers.InsertModel(attempt)
+		if helpers.IsUniqueViolation(err) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[auth.LoginAttempt](map[string]interface{}{
+		"failures":       attempt.Failures,
+		"last_failed_at": attempt.LastFailedAt,
+		"locked_until":   attempt.LockedUntil,
+	}, "id", attempt.ID, "failures", previousFailures)
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// ClaimLoginAttempt menandai percobaan login baru dengan menggeser last_failed_at ke now,
+// hanya jika backoff sudah lewat (last_failed_at <= notAfter) dan hitungan belum berubah.
+// Dari beberapa request paralel hanya satu yang berhasil.
+func (r *authRepository) ClaimLoginAttempt(attempt *auth.LoginAttempt, notAfter time.Time, now time.Time) (bool, error) {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[auth.LoginAttempt](map[string]interface{}{
+		"last_failed_at": now,
+	}, "id", attempt.ID, "failures", attempt.Failures, "last_failed_at <=", notAfter)
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// FindExpiredLoginLocks mengambil catatan yang lock-nya sudah habis tapi belum dilepas
+func (r *authRepository) FindExpiredLoginLocks(now time.Time) ([]auth.LoginAttempt, error) {
+	var attempts []auth.LoginAttempt
+	if err := helpers.FindAllByField(&attempts, "locked_until <=", now); err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
+
+// ReleaseLoginLock melepas lock yang sudah habis dan mengosongkan hitungan gagal.
+// Mengembalikan false jika lock sudah dilepas oleh proses lain.
+func (r *authRepository) ReleaseLoginLock(id int64, now time.Time) (bool, error) {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[auth.LoginAttempt](map[string]interface{}{
+		"failures":     0,
+		"locked_until": (*time.Time)(nil),
+	}, "id", id, "locked_until <=", now)
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// DeleteLoginAttempt menghapus catatan login gagal, dipakai setelah login berhasil atau reset password
+func (r *authRepository) DeleteLoginAttempt(key string) error {
+	return helpers.DeleteModelsByField[auth.LoginAttempt]("attempt_key", key)
+}
+
+func (r *authRepository) CreateLoginEvent(event *auth.LoginEvent) error {
+	return helpers.InsertModel(event)
+}
+
+// FindLoginEventsByUserID mengambil riwayat login user, yang terbaru lebih dulu
+func (r *authRepository) FindLoginEventsByUserID(userID int64) ([]auth.LoginEvent, error) {
+	var events []auth.LoginEvent
+	if err := helpers.FindAllByField(&events, "user_id", userID); err != nil {
+		return nil, fmt.Errorf("failed to fetch login history: %w", err)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].ID > events[j].ID
+	})
+	return events, nil
+}
+
+func (r *authRepository) CreatePersonalAccessToken(token *auth.PersonalAccessToken) error {
+	return helpers.InsertModel(token)
+}
+
+// FindPersonalAccessTokensByUserID mengambil semua personal access token aktif milik user
+func (r *authRepository) FindPersonalAccessTokensByUserID(userID int64) ([]auth.PersonalAccessToken, error) {
+	var tokens []auth.PersonalAccessToken
+	if err := helpers.FindAllByField(&tokens, "user_id", userID, "revoked", false); err != nil {
+		return nil, fmt.Errorf("failed to fetch personal access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// FindPersonalAccessTokenByUUID mencari personal access token aktif milik user berdasarkan uuid
+func (r *authRepository) FindPersonalAccessTokenByUUID(userID int64, uuid string) (*auth.PersonalAccessToken, error) {
+	var token auth.PersonalAccessToken
+	if err := helpers.FindOneByField(&token, "user_id", userID, "uuid", uuid, "revoked", false); err != nil {
+		return nil, fmt.Errorf("personal access token not found: %w", err)
+	}
+	return &token, nil
+}
+
+func (r *authRepository) RevokePersonalAccessToken(id int64) error {
+	return helpers.UpdateModelByIDWithMap[auth.PersonalAccessToken](map[string]interface{}{"revoked": true}, id)
+}
+
+// RevokeAllPersonalAccessTokens mencabut semua personal access token milik user
+func (r *authRepository) RevokeAllPersonalAccessTokens(userID int64) error {
+	return helpers.UpdateModelsByFieldWithMap[auth.PersonalAccessToken](map[string]interface{}{"revoked": true}, "user_id", userID, "revoked", false)
+}
+
+func (r *authRepository) CreateOIDCState(state *auth.OIDCState) error {
+	return helpers.InsertModel(state)
+}
+
+// FindOIDCState mencari state login OIDC yang belum terpakai berdasarkan hash
+func (r *authRepository) FindOIDCState(stateHash string) (*auth.OIDCState, error) {
+	var state auth.OIDCState
+	if err := helpers.FindOneByField(&state, "state_hash", stateHash, "used", false); err != nil {
+		return nil, fmt.Errorf("login state not found: %w", err)
+	}
+	return &state, nil
+}
+
+// MarkOIDCStateAsUsed menandai state terpakai hanya kalau belum terpakai,
+// callback yang kalah balapan dengan state yang sama mendapat error
+func (r *authRepository) MarkOIDCStateAsUsed(id int64) error {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[auth.OIDCState](map[string]interface{}{"used": true}, "id", id, "used", false)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("login state already used")
+	}
+	return nil
+}
+
+// FindExternalIdentity mencari identitas eksternal berdasarkan provider dan subject
+func (r *authRepository) FindExternalIdentity(provider string, subject string) (*auth.ExternalIdentity, error) {
+	var identity auth.ExternalIdentity
+	if err := helpers.FindOneByField(&identity, "provider", provider, "subject", subject); err != nil {
+		return nil, fmt.Errorf("identity not found: %w", err)
+	}
+	return &identity, nil
+}
+
+func (r *authRepository) CreateExternalIdentity(identity *auth.ExternalIdentity) error {
+	return helpers.InsertModel(identity)
+}
+
+func (r *authRepository) FindExternalIdentitiesByUserID(userID int64) ([]auth.ExternalIdentity, error) {
+	var identities []auth.ExternalIdentity
+	if err := helpers.FindAllByField(&identities, "user_id", userID); err != nil {
+		return nil, fmt.Errorf("failed to fetch identities: %w", err)
+	}
+	return identities, nil
+}
+
+// FindExternalIdentityByUUID mencari identitas eksternal milik user berdasarkan uuid
+func (r *authRepository) FindExternalIdentityByUUID(userID int64, uuid string) (*auth.ExternalIdentity, error) {
+	var identity auth.ExternalIdentity
+	if err := helpers.FindOneByField(&identity, "user_id", userID, "uuid", uuid); err != nil {
+		return nil, fmt.Errorf("identity not found: %w", err)
+	}
+	return &identity, nil
+}
+
+func (r *authRepository) DeleteExternalIdentity(id int64) error {
+	return helpers.DeleteModelByID(&auth.ExternalIdentity{}, id)
+}
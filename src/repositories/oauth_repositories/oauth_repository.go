@@ -0,0 +1,107 @@
+package oauth_repositories
+
+import (
+	"fmt"
+	"gin/src/entities/auth"
+	"gin/src/entities/oauth"
+	"gin/src/helpers"
+)
+
+type OAuthRepositoryInterface interface {
+	CreateClient(client *oauth.Client) error
+	FindClientByClientID(clientID string) (*oauth.Client, error)
+	FindClientByID(id int64) (*oauth.Client, error)
+	FindClientsByUserID(userID int64) ([]oauth.Client, error)
+	FindClientByUUID(userID int64, uuid string) (*oauth.Client, error)
+	RevokeClient(id int64) error
+	CreateAuthorizationCode(code *oauth.AuthorizationCode) error
+	FindAuthorizationCode(codeHash string) (*oauth.AuthorizationCode, error)
+	ClaimAuthorizationCode(id int64) (bool, error)
+	SetAuthorizationCodeSession(id int64, sessionID int64) error
+}
+
+type oauthRepository struct{}
+
+func NewOAuthRepository() *oauthRepository {
+	return &oauthRepository{}
+}
+
+func (r *oauthRepository) CreateClient(client *oauth.Client) error {
+	return helpers.InsertModel(client)
+}
+
+// FindClientByClientID mencari client aktif berdasarkan client_id publik
+func (r *oauthRepository) FindClientByClientID(clientID string) (*oauth.Client, error) {
+	var client oauth.Client
+	if err := helpers.FindOneByField(&client, "client_id", clientID, "revoked", false); err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+	return &client, nil
+}
+
+func (r *oauthRepository) FindClientByID(id int64) (*oauth.Client, error) {
+	var client oauth.Client
+	if err := helpers.GetModelByID(&client, id); err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+	return &client, nil
+}
+
+// FindClientsByUserID mengambil semua client aktif yang didaftarkan user
+func (r *oauthRepository) FindClientsByUserID(userID int64) ([]oauth.Client, error) {
+	var clients []oauth.Client
+	if err := helpers.FindAllByField(&clients, "user_id", userID, "revoked", false); err != nil {
+		return nil, fmt.Errorf("failed to fetch clients: %w", err)
+	}
+	return clients, nil
+}
+
+// FindClientByUUID mencari client aktif milik user berdasarkan uuid
+func (r *oauthRepository) FindClientByUUID(userID int64, uuid string) (*oauth.Client, error) {
+	var client oauth.Client
+	if err := helpers.FindOneByField(&client, "user_id", userID, "uuid", uuid, "revoked", false); err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+	return &client, nil
+}
+
+// RevokeClient menonaktifkan client beserta semua token yang pernah diterbitkan untuknya
+func (r *oauthRepository) RevokeClient(id int64) error {
+	if err := helpers.UpdateModelByIDWithMap[oauth.Client](map[string]interface{}{"revoked": true}, id); err != nil {
+		return fmt.Errorf("failed to revoke client: %w", err)
+	}
+	if err := helpers.UpdateModelsByFieldWithMap[auth.AccessToken](map[string]interface{}{"revoked": true}, "oauth_client_id", id); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return helpers.UpdateModelsByFieldWithMap[auth.RefreshToken](map[string]interface{}{"revoked": true}, "oauth_client_id", id)
+}
+
+func (r *oauthRepository) CreateAuthorizationCode(code *oauth.AuthorizationCode) error {
+	return helpers.InsertModel(code)
+}
+
+// FindAuthorizationCode mencari authorization code berdasarkan hash, termasuk yang sudah terpakai
+// supaya pemakaian ulang bisa dikenali
+func (r *oauthRepository) FindAuthorizationCode(codeHash string) (*oauth.AuthorizationCode, error) {
+	var code oauth.AuthorizationCode
+	if err := helpers.FindOneByField(&code, "code_hash", codeHash); err != nil {
+		return nil, fmt.Errorf("authorization code not found: %w", err)
+	}
+	return &code, nil
+}
+
+// ClaimAuthorizationCode menandai authorization code terpakai hanya jika belum terpakai.
+// Mengembalikan false jika code sudah ditukar oleh request lain.
+func (r *oauthRepository) ClaimAuthorizationCode(id int64) (bool, error) {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[oauth.AuthorizationCode](map[string]interface{}{"used": true}, "id", id, "used", false)
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
+
+// SetAuthorizationCodeSession mencatat sesi yang dibuat dari authorization code,
+// supaya tokennya bisa dicabut jika code dipakai ulang
+func (r *oauthRepository) SetAuthorizationCodeSession(id int64, sessionID int64) error {
+	return helpers.UpdateModelByIDWithMap[oauth.AuthorizationCode](map[string]interface{}{"session_id": sessionID}, id)
+}
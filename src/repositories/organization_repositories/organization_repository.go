@@ -0,0 +1,122 @@
+package organization_repositories
+
+import (
+	"fmt"
+	"gin/src/entities/organizations"
+	"gin/src/helpers"
+	"sort"
+	"time"
+)
+
+type OrganizationRepositoryInterface interface {
+	CreateOrganization(organization *organizations.Organization) error
+	FindOrganizationByUUID(uuid string) (*organizations.Organization, error)
+	FindOrganizationByID(id int64) (*organizations.Organization, error)
+	CreateMembership(membership *organizations.Membership) error
+	FindMembership(organizationID int64, userID int64) (*organizations.Membership, error)
+	FindMembershipsByUserID(userID int64) ([]organizations.Membership, error)
+	FindMembershipsByOrganizationID(organizationID int64) ([]organizations.Membership, error)
+	CreateInvitation(invitation *organizations.Invitation) error
+	FindInvitationByTokenHash(tokenHash string) (*organizations.Invitation, error)
+	FindPendingInvitations(organizationID int64) ([]organizations.Invitation, error)
+	MarkInvitationAsAccepted(invitation *organizations.Invitation) error
+}
+
+type organizationRepository struct{}
+
+func NewOrganizationRepository() *organizationRepository {
+	return &organizationRepository{}
+}
+
+func (r *organizationRepository) CreateOrganization(organization *organizations.Organization) error {
+	return helpers.InsertModel(organization)
+}
+
+func (r *organizationRepository) FindOrganizationByUUID(uuid string) (*organizations.Organization, error) {
+	var organization organizations.Organization
+	if err := helpers.FindOneByField(&organization, "uuid", uuid); err != nil {
+		return nil, fmt.Errorf("organization not found: %w", err)
+	}
+	return &organization, nil
+}
+
+func (r *organizationRepository) FindOrganizationByID(id int64) (*organizations.Organization, error) {
+	var organization organizations.Organization
+	if err := helpers.GetModelByID(&organization, id); err != nil {
+		return nil, fmt.Errorf("organization not found: %w", err)
+	}
+	return &organization, nil
+}
+
+func (r *organizationRepository) CreateMembership(membership *organizations.Membership) error {
+	return helpers.InsertModel(membership, helpers.ForOrganization(membership.OrganizationID))
+}
+
+// FindMembership mencari keanggotaan user di organisasi
+func (r *organizationRepository) FindMembership(organizationID int64, userID int64) (*organizations.Membership, error) {
+	var membership organizations.Membership
+	if err := helpers.FindOneByField(&membership, helpers.ForOrganization(organizationID), "user_id", userID); err != nil {
+		return nil, fmt.Errorf("membership not found: %w", err)
+	}
+	return &membership, nil
+}
+
+// FindMembershipsByUserID mengambil keanggotaan user di semua organisasi, karena itu tidak dibatasi satu organisasi
+func (r *organizationRepository) FindMembershipsByUserID(userID int64) ([]organizations.Membership, error) {
+	var memberships []organizations.Membership
+	if err := helpers.FindAllByField(&memberships, helpers.Unscoped(), "user_id", userID); err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+// FindMembershipsByOrganizationID mengambil semua anggota organisasi, yang paling lama bergabung lebih dulu
+func (r *organizationRepository) FindMembershipsByOrganizationID(organizationID int64) ([]organizations.Membership, error) {
+	var memberships []organizations.Membership
+	if err := helpers.FindAllByField(&memberships, helpers.ForOrganization(organizationID)); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].CreatedAt.Before(memberships[j].CreatedAt)
+	})
+	return memberships, nil
+}
+
+func (r *organizationRepository) CreateInvitation(invitation *organizations.Invitation) error {
+	return helpers.InsertModel(invitation, helpers.ForOrganization(invitation.OrganizationID))
+}
+
+// FindInvitationByTokenHash mencari undangan lewat token, organisasinya baru diketahui dari undangan itu sendiri
+func (r *organizationRepository) FindInvitationByTokenHash(tokenHash string) (*organizations.Invitation, error) {
+	var invitation organizations.Invitation
+	if err := helpers.FindOneByField(&invitation, helpers.Unscoped(), "token_hash", tokenHash); err != nil {
+		return nil, fmt.Errorf("invitation not found: %w", err)
+	}
+	return &invitation, nil
+}
+
+// FindPendingInvitations mengambil undangan yang belum diterima dan belum kedaluwarsa, yang terbaru lebih dulu
+func (r *organizationRepository) FindPendingInvitations(organizationID int64) ([]organizations.Invitation, error) {
+	var invitations []organizations.Invitation
+	if err := helpers.FindAllByField(&invitations, helpers.ForOrganization(organizationID)); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pending := make([]organizations.Invitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		if invitation.IsPending(now) {
+			pending = append(pending, invitation)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].CreatedAt.After(pending[j].CreatedAt)
+	})
+	return pending, nil
+}
+
+func (r *organizationRepository) MarkInvitationAsAccepted(invitation *organizations.Invitation) error {
+	return helpers.UpdateModelByIDWithMap[organizations.Invitation](map[string]interface{}{"accepted_at": time.Now()}, invitation.ID, helpers.ForOrganization(invitation.OrganizationID))
+}
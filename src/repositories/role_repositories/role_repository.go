@@ -0,0 +1,116 @@
+package role_repositories
+
+import (
+	"fmt"
+	"gin/src/entities/roles"
+	"gin/src/helpers"
+)
+
+type RoleRepositoryInterface interface {
+	FindRoleByName(name string) (*roles.Role, error)
+	FindRoleByID(id int64) (*roles.Role, error)
+	CreateRole(role *roles.Role) error
+	FindPermissionByName(name string) (*roles.Permission, error)
+	CreatePermission(permission *roles.Permission) error
+	AttachPermission(roleID int64, permissionID int64) error
+	AssignRole(userID int64, roleID int64) error
+	RemoveRole(userID int64, roleID int64) error
+	FindRolesByUserID(userID int64) ([]roles.Role, error)
+	FindPermissionNamesByRoleID(roleID int64) ([]string, error)
+}
+
+type roleRepository struct{}
+
+func NewRoleRepository() *roleRepository {
+	return &roleRepository{}
+}
+
+func (r *roleRepository) FindRoleByName(name string) (*roles.Role, error) {
+	var role roles.Role
+	if err := helpers.FindOneByField(&role, "name", name); err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) FindRoleByID(id int64) (*roles.Role, error) {
+	var role roles.Role
+	if err := helpers.GetModelByID(&role, id); err != nil {
+		return nil, fmt.Errorf("role not found: %w", err)
+	}
+	return &role, nil
+}
+
+func (r *roleRepository) CreateRole(role *roles.Role) error {
+	return helpers.InsertModel(role)
+}
+
+func (r *roleRepository) FindPermissionByName(name string) (*roles.Permission, error) {
+	var permission roles.Permission
+	if err := helpers.FindOneByField(&permission, "name", name); err != nil {
+		return nil, fmt.Errorf("permission not found: %w", err)
+	}
+	return &permission, nil
+}
+
+func (r *roleRepository) CreatePermission(permission *roles.Permission) error {
+	return helpers.InsertModel(permission)
+}
+
+// AttachPermission memberikan permission ke role, tidak melakukan apa-apa jika sudah terpasang
+func (r *roleRepository) AttachPermission(roleID int64, permissionID int64) error {
+	var existing roles.RolePermission
+	if err := helpers.FindOneByField(&existing, "role_id", roleID, "permission_id", permissionID); err == nil {
+		return nil
+	}
+	return helpers.InsertModel(&roles.RolePermission{RoleID: roleID, PermissionID: permissionID})
+}
+
+// AssignRole memberikan role ke user, tidak melakukan apa-apa jika user sudah memiliki role tersebut
+func (r *roleRepository) AssignRole(userID int64, roleID int64) error {
+	var existing roles.UserRole
+	if err := helpers.FindOneByField(&existing, "user_id", userID, "role_id", roleID); err == nil {
+		return nil
+	}
+	return helpers.InsertModel(&roles.UserRole{UserID: userID, RoleID: roleID})
+}
+
+func (r *roleRepository) RemoveRole(userID int64, roleID int64) error {
+	return helpers.DeleteModelsByField[roles.UserRole]("user_id", userID, "role_id", roleID)
+}
+
+// FindRolesByUserID mengambil semua role milik user
+func (r *roleRepository) FindRolesByUserID(userID int64) ([]roles.Role, error) {
+	var userRoles []roles.UserRole
+	if err := helpers.FindAllByField(&userRoles, "user_id", userID); err != nil {
+		return nil, fmt.Errorf("failed to fetch user roles: %w", err)
+	}
+
+	result := make([]roles.Role, 0, len(userRoles))
+	for _, userRole := range userRoles {
+		role, err := r.FindRoleByID(userRole.RoleID)
+		if err != nil {
+			continue
+		}
+		result = append(result, *role)
+	}
+	return result, nil
+}
+
+// FindPermissionNamesByRoleID mengambil nama semua permission yang dimiliki role
+func (r *roleRepository) FindPermissionNamesByRoleID(roleID int64) ([]string, error) {
+	var rolePermissions []roles.RolePermission
+	if err := helpers.FindAllByField(&rolePermissions, "role_id", roleID); err != nil {
+		return nil, fmt.Errorf("failed to fetch role permissions: %w", err)
+	}
+
+	names := make([]string, 0, len(rolePermissions))
+	for _, rolePermission := range rolePermissions {
+		var permission roles.Permission
+		if err := helpers.GetModelByID(&permission, rolePermission.PermissionID); err != nil {
+			continue
+		}
+		names = append(names, permission.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,58 @@
+package upload_repositories
+
+import (
+	"fmt"
+	"gin/src/entities/uploads"
+	"gin/src/helpers"
+)
+
+type UploadRepositoryInterface interface {
+	CreateUpload(upload *uploads.Upload) error
+	FindUploadByUUID(uuid string) (*uploads.Upload, error)
+	FindAllUploads() ([]uploads.Upload, error)
+	UpdateUpload(id int64, updatedFields map[string]interface{}) error
+	AdvanceUpload(id int64, offset int64, updatedFields map[string]interface{}) (bool, error)
+	DeleteUpload(id int64) error
+}
+
+type uploadRepository struct{}
+
+func NewUploadRepository() *uploadRepository {
+	return &uploadRepository{}
+}
+
+func (r *uploadRepository) CreateUpload(upload *uploads.Upload) error {
+	return helpers.InsertModel(upload)
+}
+
+func (r *uploadRepository) FindUploadByUUID(uuid string) (*uploads.Upload, error) {
+	var upload uploads.Upload
+	if err := helpers.FindOneByField(&upload, "uuid", uuid); err != nil {
+		return nil, fmt.Errorf("upload not found: %w", err)
+	}
+	return &upload, nil
+}
+
+// FindAllUploads mengambil semua upload, dipakai job pembersih upload yang kedaluwarsa
+func (r *uploadRepository) FindAllUploads() ([]uploads.Upload, error) {
+	var list []uploads.Upload
+	if err := helpers.FindAllByField(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *uploadRepository) UpdateUpload(id int64, updatedFields map[string]interface{}) error {
+	return helpers.UpdateModelByIDWithMap[uploads.Upload](updatedFields, id)
+}
+
+// AdvanceUpload mengubah upload hanya kalau offset-nya masih sama, false berarti
+// request lain sudah menulis chunk di offset tersebut lebih dulu
+func (r *uploadRepository) AdvanceUpload(id int64, offset int64, updatedFields map[string]interface{}) (bool, error) {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[uploads.Upload](updatedFields, "id", id, "upload_offset", offset)
+	return affected == 1, err
+}
+
+func (r *uploadRepository) DeleteUpload(id int64) error {
+	return helpers.DeleteModelByID(&uploads.Upload{}, id)
+}
@@ -2,8 +2,12 @@ package repositories
 
 import (
 	"fmt"
+	"gin/src/entities/audits"
+	"gin/src/entities/auth"
 	"gin/src/entities/users"
 	"gin/src/helpers"
+	"sort"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/net/context"
@@ -12,7 +16,29 @@ import (
 type UserRepository interface {
 	GetAll(ctx *gin.Context, limit int, offset int) ([]users.User, error)
 	CountAll() (int64, error)
-	UpdateAvatar(ctx context.Context, userID int64, avatarURL string) error
+	FindAll() ([]users.User, error)
+	UpdateAvatar(ctx context.Context, userID int64, avatar users.AvatarVariants) error
+	FindByID(id int64) (*users.User, error)
+	Create(user *users.User) error
+	Update(userID int64, fields map[string]interface{}) error
+	FindByUUID(uuid string) (*users.User, error)
+	FindByEmail(email string) (*users.User, error)
+	FindByUsername(username string) (*users.User, error)
+	UpdateUsername(userID int64, username string) error
+	UpdatePassword(userID int64, hashedPassword string) error
+	UpdateEmail(userID int64, email string) error
+	ScheduleDeletion(userID int64, at time.Time) error
+	FindPendingDeletions() ([]users.User, error)
+	Anonymize(user *users.User, now time.Time) (bool, error)
+	FindAllSessions(userID int64) ([]auth.Session, error)
+	FindAuditLogs(userID int64) ([]audits.AuditLog, error)
+	CreateDataExport(export *users.DataExport) error
+	FindLatestDataExport(userID int64) (*users.DataExport, error)
+	FindDataExportByUUID(uuid string) (*users.DataExport, error)
+	FindDataExportsByUserID(userID int64) ([]users.DataExport, error)
+	FindDataExportsByStatus(status string) ([]users.DataExport, error)
+	UpdateDataExport(id int64, fields map[string]interface{}) error
+	DeleteDataExport(id int64) error
 }
 
 type userRepository struct{}
@@ -32,19 +58,205 @@ func (r *userRepository) CountAll() (int64, error) {
 	return helpers.CountModel[users.User]()
 }
 
-func (r *userRepository) UpdateAvatar(ctx context.Context, userID int64, avatarURL string) error {
+// FindAll mengambil semua user tanpa paginasi, dipakai job pembersih file yatim
+func (r *userRepository) FindAll() ([]users.User, error) {
+	var usersList []users.User
+	if err := helpers.FindAllByField(&usersList); err != nil {
+		return nil, err
+	}
+	return usersList, nil
+}
+
+func (r *userRepository) UpdateAvatar(ctx context.Context, userID int64, avatar users.AvatarVariants) error {
 	var user users.User
 	if err := helpers.GetModelByID(&user, userID); err != nil {
 		return fmt.Errorf("failed to find user by ID: %w", err)
 	}
-	// Update avatar URL
+	// Update semua varian avatar
 
 	// Simpan perubahan
 	updatedFields := map[string]interface{}{
-		"avatar": avatarURL,
+		"avatar": avatar,
 	}
 
 	// Panggil helper untuk update berdasarkan ID dan field yang ingin diupdate
 	// Kita memastikan tipe model yang digunakan eksplisit
 	return helpers.UpdateModelByIDWithMap[users.User](updatedFields, userID)
 }
+
+func (r *userRepository) Create(user *users.User) error {
+	return helpers.InsertModel(user)
+}
+
+// Update mengubah field user sesuai map, dipakai oleh admin untuk mengedit field apa saja
+func (r *userRepository) Update(userID int64, fields map[string]interface{}) error {
+	return helpers.UpdateModelByIDWithMap[users.User](fields, userID)
+}
+
+func (r *userRepository) FindByID(id int64) (*users.User, error) {
+	var user users.User
+	if err := helpers.GetModelByID(&user, id); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUUID(uuid string) (*users.User, error) {
+	var user users.User
+	if err := helpers.FindOneByField(&user, "uuid", uuid); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(email string) (*users.User, error) {
+	var user users.User
+	if err := helpers.FindOneByField(&user, "email", email); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(username string) (*users.User, error) {
+	var user users.User
+	if err := helpers.FindOneByField(&user, "username", username); err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) UpdateUsername(userID int64, username string) error {
+	return helpers.UpdateModelByIDWithMap[users.User](map[string]interface{}{"username": username}, userID)
+}
+
+// UpdatePassword menyimpan password user yang sudah di-hash
+func (r *userRepository) UpdatePassword(userID int64, hashedPassword string) error {
+	return helpers.UpdateModelByIDWithMap[users.User](map[string]interface{}{"password": hashedPassword}, userID)
+}
+
+// UpdateEmail mengganti email user, alamat baru sudah dikonfirmasi lewat link
+// sehingga langsung ditandai terverifikasi
+func (r *userRepository) UpdateEmail(userID int64, email string) error {
+	return helpers.UpdateModelByIDWithMap[users.User](map[string]interface{}{
+		"email":             email,
+		"email_verified_at": time.Now(),
+	}, userID)
+}
+
+// ScheduleDeletion menandai akun untuk dihapus, data pribadi baru dianonimkan setelah waktu `at`
+func (r *userRepository) ScheduleDeletion(userID int64, at time.Time) error {
+	return helpers.UpdateModelByIDWithMap[users.User](map[string]interface{}{
+		"deletion_pending":      true,
+		"deletion_scheduled_at": at,
+	}, userID)
+}
+
+// FindPendingDeletions mengambil semua akun yang menunggu dihapus
+func (r *userRepository) FindPendingDeletions() ([]users.User, error) {
+	var pending []users.User
+	if err := helpers.FindAllByField(&pending, "deletion_pending", true); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+// Anonymize mengganti data pribadi user dengan nilai acak yang tidak bisa dipakai login lagi.
+// Baris user tetap ada supaya relasi (audit log, role) tidak rusak. Hanya berlaku kalau
+// penghapusan masih tertunda dan sudah jatuh tempo, false berarti user sempat membatalkannya
+func (r *userRepository) Anonymize(user *users.User, now time.Time) (bool, error) {
+	affected, err := helpers.UpdateModelsByFieldWithMapCount[users.User](map[string]interface{}{
+		"email":             fmt.Sprintf("deleted-%s@deleted.invalid", user.UUID),
+		"username":          fmt.Sprintf("deleted-%s", user.UUID),
+		"password":          "",
+		"avatar":            "",
+		"email_verified_at": nil,
+		"deletion_pending":  false,
+		"anonymized_at":     now,
+	}, "id", user.ID, "deletion_pending", true, "deletion_scheduled_at <=", now)
+	return affected == 1, err
+}
+
+// FindAllSessions mengambil semua sesi user termasuk yang sudah dicabut
+func (r *userRepository) FindAllSessions(userID int64) ([]auth.Session, error) {
+	var sessions []auth.Session
+	if err := helpers.FindAllByField(&sessions, "user_id", userID); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// FindAuditLogs mengambil audit log tentang user maupun yang dilakukan oleh user, urut dari yang terlama
+func (r *userRepository) FindAuditLogs(userID int64) ([]audits.AuditLog, error) {
+	var about, by []audits.AuditLog
+	if err := helpers.FindAllByField(&about, "user_id", userID); err != nil {
+		return nil, err
+	}
+	if err := helpers.FindAllByField(&by, "actor_id", userID); err != nil {
+		return nil, err
+	}
+
+	seen := map[int64]bool{}
+	var logs []audits.AuditLog
+	for _, entry := range append(about, by...) {
+		if !seen[entry.ID] {
+			seen[entry.ID] = true
+			logs = append(logs, entry)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		return logs[i].ID < logs[j].ID
+	})
+	return logs, nil
+}
+
+func (r *userRepository) CreateDataExport(export *users.DataExport) error {
+	return helpers.InsertModel(export)
+}
+
+// FindLatestDataExport mengambil export terakhir milik user
+func (r *userRepository) FindLatestDataExport(userID int64) (*users.DataExport, error) {
+	exports, err := r.FindDataExportsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(exports) == 0 {
+		return nil, fmt.Errorf("data export not found")
+	}
+	return &exports[0], nil
+}
+
+func (r *userRepository) FindDataExportByUUID(uuid string) (*users.DataExport, error) {
+	var export users.DataExport
+	if err := helpers.FindOneByField(&export, "uuid", uuid); err != nil {
+		return nil, fmt.Errorf("data export not found: %w", err)
+	}
+	return &export, nil
+}
+
+// FindDataExportsByUserID mengambil semua export milik user, yang terbaru lebih dulu
+func (r *userRepository) FindDataExportsByUserID(userID int64) ([]users.DataExport, error) {
+	var exports []users.DataExport
+	if err := helpers.FindAllByField(&exports, "user_id", userID); err != nil {
+		return nil, err
+	}
+	sort.Slice(exports, func(i, j int) bool {
+		return exports[i].ID > exports[j].ID
+	})
+	return exports, nil
+}
+
+func (r *userRepository) FindDataExportsByStatus(status string) ([]users.DataExport, error) {
+	var exports []users.DataExport
+	if err := helpers.FindAllByField(&exports, "status", status); err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *userRepository) UpdateDataExport(id int64, fields map[string]interface{}) error {
+	return helpers.UpdateModelByIDWithMap[users.DataExport](fields, id)
+}
+
+func (r *userRepository) DeleteDataExport(id int64) error {
+	return helpers.DeleteModelByID(&users.DataExport{}, id)
+}
@@ -2,13 +2,36 @@ package routes
 
 import (
 	"gin/src/configs/database"
+	"gin/src/configs/security"
+	"gin/src/controllers/api/v1/admin"
+	"gin/src/controllers/api/v1/attachment"
 	"gin/src/controllers/api/v1/auth"
+	"gin/src/controllers/api/v1/file"
+	oauthControllers "gin/src/controllers/api/v1/oauth"
+	"gin/src/controllers/api/v1/organization"
+	"gin/src/controllers/api/v1/upload"
 	"gin/src/controllers/api/v1/user"
+	"gin/src/entities/attachments"
+	authEntities "gin/src/entities/auth"
+	"gin/src/entities/roles"
+	"gin/src/entities/uploads"
 	"gin/src/middleware"
+	"gin/src/repositories/attachment_repositories"
 	"gin/src/repositories/auth_repositories"
+	"gin/src/repositories/oauth_repositories"
+	"gin/src/repositories/organization_repositories"
+	"gin/src/repositories/upload_repositories"
 	repositories "gin/src/repositories/user_repositories"
+	"gin/src/services/attachment_services"
 	"gin/src/services/auth_services"
+	"gin/src/services/oauth_services"
+	"gin/src/services/organization_services"
+	"gin/src/services/role_services"
+	"gin/src/services/upload_services"
 	services "gin/src/services/user_services"
+	"gin/src/utils/mailers"
+	"gin/src/utils/schedulers"
+	"gin/src/utils/storages"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -16,24 +39,106 @@ import (
 
 // API sets up the routes and handlers for the application.
 // It initializes the authentication and user services using their respective repositories.
+// Uploaded files are served from the storage under GET /files/*key with Range, ETag and
+// Cache-Control support; keys below private/ need a signed link that expires.
 // The function defines a versioned API group (/api/v1) and registers various endpoints:
 // - GET /ping: Responds with a "pong" message for health checks.
 // - POST /user/register: Registers a new user using the provided authentication service.
 // - POST /user/login: Authenticates a user with the provided credentials.
-// - Secures routes with JWT middleware, ensuring protected endpoints require valid tokens:
+// - POST /user/login/mfa: Second login step for users with two-factor authentication.
+// - POST /user/login/magic-link: Emails a single-use passwordless login link, throttled per address.
+// - POST /user/login/magic-link/verify: Exchanges the login link parameters for tokens.
+// - GET /user/login/oidc: Lists the external OpenID Connect login providers.
+// - GET /user/login/oidc/:provider, POST /user/login/oidc/:provider/callback: Sign in with an external provider.
+// - POST /user/password/forgot: Sends a password reset link to the user's email.
+// - POST /user/password/reset: Sets a new password using the emailed reset token.
+// - POST /user/email/verify: Verifies the user's email using the signed link parameters.
+// - POST /user/email/resend: Sends a new verification link, throttled per address.
+// - POST /user/email/confirm: Swaps the user's email using the signed link sent to the new address.
+// - POST /oauth/token: OAuth2 token endpoint (authorization_code with PKCE, client_credentials, refresh_token).
+// - POST /oauth/introspect, /oauth/revoke: OAuth2 token introspection (RFC 7662) and revocation (RFC 7009).
+// - Secures routes with JWT middleware, ensuring protected endpoints require valid tokens
+//   (personal access tokens and OAuth tokens are accepted too and limited to the scopes they were granted):
 //   - GET /user/profile: Returns the profile of the authenticated user.
-//   - GET /users: Retrieves a list of users using the user service.
-//   - POST /user/upload/avatar: Allows users to upload avatars.
+//   - PATCH /user/profile: Updates the username of the authenticated user.
+//   - POST /user/password: Changes the password (requires the current one) and signs out the other sessions.
+//   - POST /user/email: Sends a confirmation link to the new email address, the email changes once it is confirmed.
+//   - DELETE /user: Schedules the account for deletion; it is anonymised after the grace period unless the user signs in again.
+//   - GET /user/export: Starts or returns the ZIP export of the user's data, with a signed download link once it is ready.
+//   - GET /users: Retrieves a list of users using the user service (requires the users.list permission).
+//   - POST /user/upload/avatar: Allows users to upload avatars, stored as re-encoded thumbnail variants (requires a verified email when enforced).
+//   - OPTIONS/POST /uploads, HEAD/PATCH/DELETE /uploads/:uuid: Resumable tus 1.0 uploads (creation, termination and
+//     expiration extensions); the finished file goes to the flow named by "purpose" in Upload-Metadata, e.g. avatar.
+//   - GET/POST /attachments/:owner_type/:owner_uuid, DELETE /attachments/:uuid: Files attached to a user or an
+//     organization the current user has access to, stored once per SHA-256 and served through signed links.
 //   - POST /token/refresh: Refreshes JWT tokens.
 //   - POST /user/logout: Logs out the user, revoking the current token.
+//   - GET /user/sessions: Lists the active sessions (devices) of the user.
+//   - DELETE /user/sessions/:uuid: Revokes a single session of the user.
+//   - GET /user/identities, DELETE /user/identities/:uuid: List and unlink external provider accounts.
+//   - GET /user/tokens, POST /user/tokens, DELETE /user/tokens/:uuid: Manage personal access tokens (API keys).
+//   - POST /user/mfa/enroll, /user/mfa/confirm, /user/mfa/disable: Manage TOTP two-factor authentication.
+//   - GET /oauth/authorize, POST /oauth/authorize: Validate and approve an OAuth2 authorization request.
+//   - GET /oauth/clients, POST /oauth/clients, DELETE /oauth/clients/:uuid: Manage the user's OAuth clients.
+//   - GET /orgs, POST /orgs: List the organizations of the user and create a new one (the user becomes its owner).
+//   - GET /orgs/current: Returns the current organization, selected with the X-Organization-ID header or the "org" token claim.
+//   - POST /orgs/:uuid/token: Issues a short-lived access token of the current session with the "org" claim of the organization (refused for API keys and other tokens without a session).
+//   - GET /orgs/:uuid/members: Lists the members of an organization the user belongs to.
+//   - GET /orgs/:uuid/invitations, POST /orgs/:uuid/invitations: List pending invitations and email a new one (owners and admins).
+//   - POST /orgs/invitations/accept: Accepts an invitation with the token from the emailed link.
+//   - POST /admin/users/:uuid/impersonate: Issues a short-lived token acting as the user (requires the users.impersonate permission).
+//     Credential and session changes are refused while impersonating and every impersonated request is audited.
+//   - GET /admin/users, GET /admin/users/:uuid, GET /admin/users/:uuid/logins: List (filterable and sortable),
+//     show and inspect the login history of users (requires users.list / users.view).
+//   - POST /admin/users, PATCH /admin/users/:uuid, DELETE /admin/users/:uuid: Create, update and delete users
+//     (requires users.create / users.update / users.delete, and roles.manage to change roles).
+//   - POST /admin/users/:uuid/suspend, /ban, /reactivate, /password-reset, /sessions/revoke: Change the status
+//     of a user, force a password reset or sign the user out everywhere (requires users.update).
+//     Every admin action is written to the audit log.
+//   - POST /user/logout-all: Revokes every token and session of the user.
 // Returns the configured Gin engine instance.
 
 func API(db *database.DBConnection, ginEngine *gin.Engine) *gin.Engine {
+	mailer := mailers.NewMailer()
+
 	authRepo := auth_repositories.NewAuthRepository()
-	authService := auth_services.NewAuthService(authRepo)
+	authService := auth_services.NewAuthService(authRepo, mailer)
+
+	oauthRepo := oauth_repositories.NewOAuthRepository()
+	oauthService := oauth_services.NewOAuthService(oauthRepo, authRepo, authService)
 
 	userRepo := repositories.NewUserRepository()
-	userService := services.NewUserService(userRepo)
+	orgRepo := organization_repositories.NewOrganizationRepository()
+
+	// Lampiran bisa dipasang ke setiap owner type yang punya policy
+	attachmentService := attachment_services.NewAttachmentService(attachment_repositories.NewAttachmentRepository(), storages.Default())
+	attachmentService.RegisterOwner(attachments.OwnerUser, attachment_services.UserOwner(userRepo))
+	attachmentService.RegisterOwner(attachments.OwnerOrganization, attachment_services.OrganizationOwner(orgRepo))
+
+	userService := services.NewUserService(userRepo, authRepo, mailer, attachmentService)
+	organizationService := organization_services.NewOrganizationService(orgRepo, userRepo, mailer)
+	adminUserService := services.NewAdminUserService(userRepo, authRepo, mailer, role_services.Default(), authService, attachmentService)
+
+	// Upload tus yang selesai diteruskan ke proses sesuai purpose di Upload-Metadata
+	uploadService := upload_services.NewUploadService(upload_repositories.NewUploadRepository(), storages.Default())
+	uploadService.RegisterHandler(uploads.PurposeAvatar, upload_services.AvatarHandler(userService))
+	uploadService.RegisterHandler(uploads.PurposeAttachment, upload_services.AttachmentHandler(attachmentService))
+
+	// Anonimkan akun yang masa tenggangnya habis dan hapus export yang kedaluwarsa
+	accountConfig := security.LoadAccountConfig()
+	schedulers.Every(accountConfig.PurgeInterval, "purge deleted accounts", userService.PurgeDeletedAccounts)
+	schedulers.Every(accountConfig.PurgeInterval, "purge expired data exports", userService.PurgeExpiredExports)
+	schedulers.Every(security.LoadLockoutConfig().UnlockInterval, "release expired login locks", authService.ReleaseExpiredLoginLocks)
+	schedulers.Every(security.LoadUploadConfig().PurgeInterval, "purge expired uploads", uploadService.PurgeExpiredUploads)
+
+	// Hapus file di storage yang tidak dirujuk avatar, upload maupun lampiran mana pun
+	fileConfig := security.LoadFileConfig()
+	janitor := storages.NewJanitor(storages.Default(), fileConfig.JanitorGrace, userService.StoredFileKeys, uploadService.StoredFileKeys, attachmentService.StoredFileKeys)
+	schedulers.Every(fileConfig.JanitorInterval, "remove orphaned files", janitor.Run)
+
+	// File hasil upload, file privat butuh link bertanda tangan dari storages.SignedURL
+	ginEngine.GET("/files/*key", file.ServeFile(storages.Default()))
+	ginEngine.HEAD("/files/*key", file.ServeFile(storages.Default()))
 
 	v1 := ginEngine.Group("/api/v1")
 	{
@@ -45,15 +150,86 @@ func API(db *database.DBConnection, ginEngine *gin.Engine) *gin.Engine {
 
 		v1.POST("/user/register", auth.Register(authService))
 		v1.POST("/user/login", auth.Login(authService))
+		v1.POST("/user/login/mfa", auth.LoginMFA(authService))
+		v1.POST("/user/login/magic-link", auth.SendMagicLink(authService))
+		v1.POST("/user/login/magic-link/verify", auth.VerifyMagicLink(authService))
+		v1.GET("/user/login/oidc", auth.GetOIDCProviders(authService))
+		v1.GET("/user/login/oidc/:provider", auth.StartOIDCLogin(authService))
+		v1.POST("/user/login/oidc/:provider/callback", auth.OIDCCallback(authService))
+		v1.POST("/user/password/forgot", auth.ForgotPassword(authService))
+		v1.POST("/user/password/reset", auth.ResetPassword(authService))
+		v1.POST("/user/email/verify", auth.VerifyEmail(authService))
+		v1.POST("/user/email/resend", auth.ResendVerification(authService))
+		v1.POST("/user/email/confirm", user.ConfirmEmailChange(userService))
+
+		v1.OPTIONS("/uploads", upload.Options(uploadService))
+		v1.OPTIONS("/uploads/:uuid", upload.Options(uploadService))
+
+		v1.POST("/oauth/token", oauthControllers.Token(oauthService))
+		v1.POST("/oauth/introspect", oauthControllers.Introspect(oauthService))
+		v1.POST("/oauth/revoke", oauthControllers.Revoke(oauthService))
 
 		v1.Use(middleware.JWTAuthMiddleware())
 		{
-			v1.GET("/user/profile", user.GetProfile)
-			v1.GET("/users", user.GetAllUsers(userService))
-			v1.POST("/user/upload/avatar", user.UploadAvatar(userService))
+			v1.GET("/user/profile", middleware.RequireScope(authEntities.ScopeProfileRead), user.GetProfile)
+			v1.GET("/users", middleware.RequireScope(authEntities.ScopeUsersRead), middleware.RequirePermission(roles.PermissionUsersList), user.GetAllUsers(userService))
+			v1.PATCH("/user/profile", middleware.RequireScope(authEntities.ScopeProfileWrite), user.UpdateProfile(userService))
+			v1.POST("/user/password", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.DenyImpersonation(), user.ChangePassword(userService))
+			v1.POST("/user/email", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.DenyImpersonation(), user.ChangeEmail(userService))
+			v1.DELETE("/user", middleware.RequireScope(authEntities.ScopeAll), middleware.DenyImpersonation(), user.DeleteAccount(userService))
+			v1.GET("/user/export", middleware.RequireScope(authEntities.ScopeProfileRead), middleware.DenyImpersonation(), user.ExportData(userService))
+			v1.POST("/user/upload/avatar", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.RequireVerifiedEmail(), user.UploadAvatar(userService))
+			v1.POST("/uploads", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.RequireVerifiedEmail(), upload.CreateUpload(uploadService))
+			v1.HEAD("/uploads/:uuid", middleware.RequireScope(authEntities.ScopeProfileWrite), upload.GetUploadOffset(uploadService))
+			v1.PATCH("/uploads/:uuid", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.RequireVerifiedEmail(), upload.UploadChunk(uploadService))
+			v1.DELETE("/uploads/:uuid", middleware.RequireScope(authEntities.ScopeProfileWrite), upload.TerminateUpload(uploadService))
+			v1.GET("/attachments/:owner_type/:owner_uuid", middleware.RequireScope(authEntities.ScopeProfileRead), attachment.GetAttachments(attachmentService))
+			v1.POST("/attachments/:owner_type/:owner_uuid", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.RequireVerifiedEmail(), attachment.UploadAttachment(attachmentService))
+			v1.DELETE("/attachments/:uuid", middleware.RequireScope(authEntities.ScopeProfileWrite), attachment.DeleteAttachment(attachmentService))
 
 			v1.POST("/token/refresh", auth.RefreshToken(authService))
 			v1.POST("/user/logout", auth.Logout(authService))
+			v1.POST("/user/logout-all", middleware.RequireScope(authEntities.ScopeTokensManage), middleware.DenyImpersonation(), auth.LogoutAll(authService))
+			v1.GET("/user/sessions", middleware.RequireScope(authEntities.ScopeTokensManage), auth.GetSessions(authService))
+			v1.DELETE("/user/sessions/:uuid", middleware.RequireScope(authEntities.ScopeTokensManage), middleware.DenyImpersonation(), auth.RevokeSession(authService))
+
+			v1.GET("/user/identities", middleware.RequireScope(authEntities.ScopeTokensManage), auth.GetIdentities(authService))
+			v1.DELETE("/user/identities/:uuid", middleware.RequireScope(authEntities.ScopeTokensManage), middleware.DenyImpersonation(), auth.UnlinkIdentity(authService))
+
+			v1.GET("/user/tokens", middleware.RequireScope(authEntities.ScopeTokensManage), auth.GetPersonalAccessTokens(authService))
+			v1.POST("/user/tokens", middleware.RequireScope(authEntities.ScopeTokensManage), middleware.DenyImpersonation(), auth.CreatePersonalAccessToken(authService))
+			v1.DELETE("/user/tokens/:uuid", middleware.RequireScope(authEntities.ScopeTokensManage), middleware.DenyImpersonation(), auth.RevokePersonalAccessToken(authService))
+
+			v1.POST("/user/mfa/enroll", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.DenyImpersonation(), auth.EnrollMFA(authService))
+			v1.POST("/user/mfa/confirm", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.DenyImpersonation(), auth.ConfirmMFA(authService))
+			v1.POST("/user/mfa/disable", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.DenyImpersonation(), auth.DisableMFA(authService))
+
+			v1.GET("/oauth/authorize", middleware.RequireScope(authEntities.ScopeTokensManage), oauthControllers.GetAuthorize(oauthService))
+			v1.POST("/oauth/authorize", middleware.RequireScope(authEntities.ScopeTokensManage), middleware.DenyImpersonation(), oauthControllers.ApproveAuthorize(oauthService))
+			v1.GET("/oauth/clients", middleware.RequireScope(authEntities.ScopeTokensManage), oauthControllers.GetClients(oauthService))
+			v1.POST("/oauth/clients", middleware.RequireScope(authEntities.ScopeTokensManage), middleware.DenyImpersonation(), oauthControllers.RegisterClient(oauthService))
+			v1.DELETE("/oauth/clients/:uuid", middleware.RequireScope(authEntities.ScopeTokensManage), middleware.DenyImpersonation(), oauthControllers.RevokeClient(oauthService))
+
+			v1.GET("/orgs", middleware.RequireScope(authEntities.ScopeProfileRead), organization.GetOrganizations(organizationService))
+			v1.POST("/orgs", middleware.RequireScope(authEntities.ScopeProfileWrite), organization.CreateOrganization(organizationService))
+			v1.GET("/orgs/current", middleware.RequireScope(authEntities.ScopeProfileRead), middleware.RequireOrganization(), organization.GetCurrentOrganization(organizationService))
+			v1.POST("/orgs/:uuid/token", middleware.RequireScope(authEntities.ScopeProfileRead), middleware.DenyImpersonation(), organization.IssueOrganizationToken(organizationService, authService))
+			v1.POST("/orgs/invitations/accept", middleware.RequireScope(authEntities.ScopeProfileWrite), middleware.DenyImpersonation(), organization.AcceptInvitation(organizationService))
+			v1.GET("/orgs/:uuid/members", middleware.RequireScope(authEntities.ScopeProfileRead), organization.GetMembers(organizationService))
+			v1.GET("/orgs/:uuid/invitations", middleware.RequireScope(authEntities.ScopeProfileRead), organization.GetInvitations(organizationService))
+			v1.POST("/orgs/:uuid/invitations", middleware.RequireScope(authEntities.ScopeProfileWrite), organization.InviteMember(organizationService))
+			v1.POST("/admin/users/:uuid/impersonate", middleware.RequireScope(authEntities.ScopeAll), middleware.RequirePermission(roles.PermissionUsersImpersonate), admin.Impersonate(authService))
+			v1.GET("/admin/users", middleware.RequireScope(authEntities.ScopeAll), middleware.RequirePermission(roles.PermissionUsersList), admin.ListUsers(adminUserService))
+			v1.POST("/admin/users", middleware.RequireScope(authEntities.ScopeAll), middleware.DenyImpersonation(), middleware.RequirePermission(roles.PermissionUsersCreate), admin.CreateUser(adminUserService))
+			v1.GET("/admin/users/:uuid", middleware.RequireScope(authEntities.ScopeAll), middleware.RequirePermission(roles.PermissionUsersView), admin.GetUser(adminUserService))
+			v1.PATCH("/admin/users/:uuid", middleware.RequireScope(authEntities.ScopeAll), middleware.DenyImpersonation(), middleware.RequirePermission(roles.PermissionUsersUpdate), admin.UpdateUser(adminUserService))
+			v1.DELETE("/admin/users/:uuid", middleware.RequireScope(authEntities.ScopeAll), middleware.DenyImpersonation(), middleware.RequirePermission(roles.PermissionUsersDelete), admin.DeleteUser(adminUserService))
+			v1.POST("/admin/users/:uuid/suspend", middleware.RequireScope(authEntities.ScopeAll), middleware.DenyImpersonation(), middleware.RequirePermission(roles.PermissionUsersUpdate), admin.SuspendUser(adminUserService))
+			v1.POST("/admin/users/:uuid/ban", middleware.RequireScope(authEntities.ScopeAll), middleware.DenyImpersonation(), middleware.RequirePermission(roles.PermissionUsersUpdate), admin.BanUser(adminUserService))
+			v1.POST("/admin/users/:uuid/reactivate", middleware.RequireScope(authEntities.ScopeAll), middleware.DenyImpersonation(), middleware.RequirePermission(roles.PermissionUsersUpdate), admin.ReactivateUser(adminUserService))
+			v1.POST("/admin/users/:uuid/password-reset", middleware.RequireScope(authEntities.ScopeAll), middleware.DenyImpersonation(), middleware.RequirePermission(roles.PermissionUsersUpdate), admin.ForcePasswordReset(adminUserService))
+			v1.POST("/admin/users/:uuid/sessions/revoke", middleware.RequireScope(authEntities.ScopeAll), middleware.DenyImpersonation(), middleware.RequirePermission(roles.PermissionUsersUpdate), admin.RevokeUserSessions(adminUserService))
+			v1.GET("/admin/users/:uuid/logins", middleware.RequireScope(authEntities.ScopeAll), middleware.RequirePermission(roles.PermissionUsersView), admin.LoginHistory(adminUserService))
 		}
 	}
 
@@ -0,0 +1,98 @@
+package role_seeders
+
+import (
+	"fmt"
+	"gin/src/configs/database"
+	"gin/src/entities/roles"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	"gin/src/repositories/role_repositories"
+	"log"
+)
+
+var defaultPermissions = map[string]string{
+	roles.PermissionUsersList:        "List all users",
+	roles.PermissionUsersView:        "View any user",
+	roles.PermissionUsersCreate:      "Create users",
+	roles.PermissionUsersUpdate:      "Update any user",
+	roles.PermissionUsersDelete:      "Delete users",
+	roles.PermissionUsersImpersonate: "Impersonate users",
+	roles.PermissionRolesManage:      "Assign and remove roles",
+}
+
+var defaultRoles = []struct {
+	name        string
+	description string
+	permissions []string
+}{
+	{
+		name:        roles.RoleAdmin,
+		description: "Full access to user management",
+		permissions: []string{
+			roles.PermissionUsersList,
+			roles.PermissionUsersView,
+			roles.PermissionUsersCreate,
+			roles.PermissionUsersUpdate,
+			roles.PermissionUsersDelete,
+			roles.PermissionUsersImpersonate,
+			roles.PermissionRolesManage,
+		},
+	},
+	{
+		name:        roles.RoleSupport,
+		description: "Read access to users for the support team",
+		permissions: []string{
+			roles.PermissionUsersList,
+			roles.PermissionUsersView,
+		},
+	},
+}
+
+// SeedRoles seeds the default permissions and roles and links them together.
+//
+// Existing permissions, roles and links are kept, so the seeder can run on every start.
+// The admin role is given to the user with the given email, if that user exists.
+func SeedRoles(db *database.DBConnection, adminEmail string) {
+	repo := role_repositories.NewRoleRepository()
+
+	permissionIDs := make(map[string]int64, len(defaultPermissions))
+	for name, description := range defaultPermissions {
+		permission, err := repo.FindPermissionByName(name)
+		if err != nil {
+			permission = &roles.Permission{Name: name, Description: description}
+			if err := repo.CreatePermission(permission); err != nil {
+				log.Println("❌ Error creating permission:", name, err)
+				continue
+			}
+		}
+		permissionIDs[name] = permission.ID
+	}
+
+	for _, def := range defaultRoles {
+		role, err := repo.FindRoleByName(def.name)
+		if err != nil {
+			role = &roles.Role{Name: def.name, Description: def.description}
+			if err := repo.CreateRole(role); err != nil {
+				log.Println("❌ Error creating role:", def.name, err)
+				continue
+			}
+		}
+
+		for _, name := range def.permissions {
+			if err := repo.AttachPermission(role.ID, permissionIDs[name]); err != nil {
+				log.Println("❌ Error attaching permission:", name, err)
+			}
+		}
+	}
+
+	var admin users.User
+	if err := helpers.FindOneByField(&admin, "email", adminEmail); err == nil {
+		if role, err := repo.FindRoleByName(roles.RoleAdmin); err == nil {
+			if err := repo.AssignRole(admin.ID, role.ID); err != nil {
+				log.Println("❌ Error assigning admin role:", err)
+			}
+		}
+	}
+
+	fmt.Println("✅ Roles and permissions seeded.")
+}
@@ -2,9 +2,11 @@ package seeders
 
 import (
 	"gin/src/configs/database"
+	"gin/src/seeders/role_seeders"
 	"gin/src/seeders/user_seeders"
 )
 
 func Run(db *database.DBConnection) {
 	user_seeders.SeedUsers(db, 5000)
+	role_seeders.SeedRoles(db, user_seeders.DefaultUserEmail)
 }
@@ -5,14 +5,17 @@ import (
 	"gin/src/configs/database"
 	"gin/src/entities/users"
 	"gin/src/helpers"
+	"gin/src/utils/hashers"
 	"log"
 	"math/rand"
 	"time"
 
 	"github.com/bxcodec/faker/v4"
-	"golang.org/x/crypto/bcrypt"
 )
 
+// DefaultUserEmail is the email of the first seeded user, it receives the admin role.
+const DefaultUserEmail = "ahmadsaubani@testing.com"
+
 // SeedUsers seeds users in the database, given a target count.
 //
 // If the target count is less than or equal to the current user count,
@@ -39,21 +42,22 @@ func SeedUsers(db *database.DBConnection, target int64) {
 	fmt.Printf("🔄 Seeding %d users...\n", target-int64(userCount))
 
 	var usersBatch []users.User
-	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	hashedPassword, _ := hashers.Default().Hash("password123")
 	now := time.Now()
 
 	for i := int64(userCount); i < target; i++ {
 		email := faker.Email()
 		if i == 0 {
-			email = "ahmadsaubani@testing.com"
+			email = DefaultUserEmail
 		}
 
 		usersBatch = append(usersBatch, users.User{
-			Email:     email,
-			Username:  fmt.Sprintf("%s_%d", faker.Username(), rand.Intn(10000)),
-			Password:  string(hashedPassword),
-			CreatedAt: now,
-			UpdatedAt: now,
+			Email:           email,
+			Username:        fmt.Sprintf("%s_%d", faker.Username(), rand.Intn(10000)),
+			Password:        string(hashedPassword),
+			EmailVerifiedAt: &now,
+			CreatedAt:       now,
+			UpdatedAt:       now,
 		})
 	}
 
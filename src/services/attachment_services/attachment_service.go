@@ -0,0 +1,383 @@
+package attachment_services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gin/src/configs/security"
+	"gin/src/entities/attachments"
+	"gin/src/repositories/attachment_repositories"
+	"gin/src/utils/loggers"
+	"gin/src/utils/storages"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type AttachmentServiceInterface interface {
+	RegisterOwner(ownerType string, policy OwnerPolicy)
+	MaxSize() int64
+	CheckAccess(ctx context.Context, userID int64, ownerType string, ownerUUID string) error
+	CreateAttachment(ctx context.Context, userID int64, ownerType string, ownerUUID string, file io.Reader, metadata map[string]string) (*attachments.AttachmentResponse, error)
+	ListAttachments(ctx context.Context, userID int64, ownerType string, ownerUUID string) ([]attachments.AttachmentResponse, error)
+	DeleteAttachment(ctx context.Context, userID int64, attachmentUUID string) error
+	StoredFileKeys(ctx context.Context) ([]string, error)
+	UserAttachments(ctx context.Context, userID int64) ([]attachments.Attachment, error)
+	RemoveUserAttachments(ctx context.Context, userID int64) error
+}
+
+type AttachmentService struct {
+	repo     attachment_repositories.AttachmentRepositoryInterface
+	storage  storages.Storage
+	config   security.AttachmentConfig
+	policies map[string]OwnerPolicy
+	locks    [64]sync.Mutex // by checksum, guards sharing and removing a stored file
+}
+
+// NewAttachmentService creates the attachment service. The owner types files can be attached
+// to are added with RegisterOwner.
+func NewAttachmentService(repo attachment_repositories.AttachmentRepositoryInterface, storage storages.Storage) *AttachmentService {
+	return &AttachmentService{
+		repo:     repo,
+		storage:  storage,
+		config:   security.LoadAttachmentConfig(),
+		policies: make(map[string]OwnerPolicy),
+	}
+}
+
+// RegisterOwner lets files be attached to owners of the given type. It is meant to be called
+// while setting up the routes.
+func (s *AttachmentService) RegisterOwner(ownerType string, policy OwnerPolicy) {
+	s.policies[ownerType] = policy
+}
+
+// MaxSize returns the largest file accepted, ATTACHMENT_MAX_BYTES.
+func (s *AttachmentService) MaxSize() int64 {
+	return s.config.MaxBytes
+}
+
+// CheckAccess returns nil when the user may attach files to the owner, so a resumable upload
+// can be refused before the file is sent.
+func (s *AttachmentService) CheckAccess(ctx context.Context, userID int64, ownerType string, ownerUUID string) error {
+	_, err := s.findOwner(ctx, userID, ownerType, ownerUUID)
+	return err
+}
+
+// CreateAttachment stores the file and attaches it to the owner. The content type is sniffed
+// from the content and must be in ATTACHMENT_ALLOWED_TYPES. A file with the same SHA-256 as
+// an existing attachment is not stored again but shares its key; when the owner already has
+// that file, the existing attachment is returned.
+func (s *AttachmentService) CreateAttachment(ctx context.Context, userID int64, ownerType string, ownerUUID string, file io.Reader, metadata map[string]string) (*attachments.AttachmentResponse, error) {
+	ownerID, err := s.findOwner(ctx, userID, ownerType, ownerUUID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMetadata(metadata); err != nil {
+		return nil, err
+	}
+
+	// File disalin ke file sementara sambil dihitung checksum-nya, baru disimpan kalau isinya belum ada
+	tmp, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to buffer file: %w", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(file, s.config.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if size == 0 {
+		return nil, ErrEmptyFile
+	}
+	if size > s.config.MaxBytes {
+		return nil, ErrFileTooLarge
+	}
+
+	head := make([]byte, 512)
+	n, err := tmp.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	mimeType := http.DetectContentType(head[:n])
+	if !s.config.Allows(mimeType) {
+		return nil, ErrUnsupportedType
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+
+	lock := s.lockFor(checksum)
+	lock.Lock()
+	defer lock.Unlock()
+
+	existing, err := s.repo.FindAttachmentsByChecksum(checksum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+	for i := range existing {
+		if existing[i].OwnerType == ownerType && existing[i].OwnerID == ownerID {
+			response := s.toResponse(&existing[i], ownerUUID)
+			return &response, nil
+		}
+	}
+
+	key := attachments.StorageKey(checksum, extensionFor(mimeType))
+	if len(existing) > 0 {
+		key = existing[0].StorageKey
+	}
+
+	// Simpan file kalau belum ada di storage, file yang sama dipakai bersama
+	stored := false
+	if _, err := s.storage.Stat(ctx, key); errors.Is(err, storages.ErrNotFound) {
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		if err := s.storage.Put(ctx, key, tmp, mimeType); err != nil {
+			return nil, fmt.Errorf("failed to store file: %w", err)
+		}
+		stored = true
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to check stored file: %w", err)
+	}
+
+	attachment := attachments.Attachment{
+		OwnerType:  ownerType,
+		OwnerID:    ownerID,
+		UserID:     userID,
+		StorageKey: key,
+		MimeType:   mimeType,
+		Size:       size,
+		Checksum:   checksum,
+		Metadata:   metadata,
+	}
+	if err := s.repo.CreateAttachment(&attachment); err != nil {
+		if stored && len(existing) == 0 {
+			s.removeFile(ctx, key)
+		}
+		return nil, fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	response := s.toResponse(&attachment, ownerUUID)
+	return &response, nil
+}
+
+// ListAttachments returns the attachments of the owner, oldest first.
+func (s *AttachmentService) ListAttachments(ctx context.Context, userID int64, ownerType string, ownerUUID string) ([]attachments.AttachmentResponse, error) {
+	ownerID, err := s.findOwner(ctx, userID, ownerType, ownerUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.repo.FindAttachmentsByOwner(ownerType, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+	response := make([]attachments.AttachmentResponse, 0, len(list))
+	for i := range list {
+		response = append(response, s.toResponse(&list[i], ownerUUID))
+	}
+	return response, nil
+}
+
+// DeleteAttachment removes the attachment. The uploader may delete it, other users need
+// AccessManage on the owner. The file is removed once no attachment shares it anymore.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, userID int64, attachmentUUID string) error {
+	attachment, err := s.repo.FindAttachmentByUUID(attachmentUUID)
+	if err != nil {
+		return ErrAttachmentNotFound
+	}
+	policy, ok := s.policies[attachment.OwnerType]
+	if !ok {
+		return ErrAttachmentNotFound
+	}
+	access, err := policy.Access(ctx, userID, attachment.OwnerID)
+	if err != nil {
+		return fmt.Errorf("failed to check access: %w", err)
+	}
+	if access == AccessNone {
+		return ErrAttachmentNotFound
+	}
+	if access != AccessManage && attachment.UserID != userID {
+		return ErrNotAllowed
+	}
+	return s.remove(ctx, attachment)
+}
+
+// UserAttachments returns the attachments the user uploaded and the ones attached to the
+// account of the user, oldest first, for the data export.
+func (s *AttachmentService) UserAttachments(ctx context.Context, userID int64) ([]attachments.Attachment, error) {
+	uploaded, err := s.repo.FindAttachmentsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+	owned, err := s.repo.FindAttachmentsByOwner(attachments.OwnerUser, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+
+	// Lampiran yang diupload ke akun sendiri muncul di kedua daftar
+	seen := make(map[int64]bool, len(uploaded))
+	list := make([]attachments.Attachment, 0, len(uploaded)+len(owned))
+	for _, attachment := range append(uploaded, owned...) {
+		if !seen[attachment.ID] {
+			seen[attachment.ID] = true
+			list = append(list, attachment)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// RemoveUserAttachments is called when the account of the user is anonymised. The attachments
+// of the account are deleted, files the user attached to other owners stay with those owners
+// but no longer refer to the user.
+func (s *AttachmentService) RemoveUserAttachments(ctx context.Context, userID int64) error {
+	owned, err := s.repo.FindAttachmentsByOwner(attachments.OwnerUser, userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+	for i := range owned {
+		if err := s.remove(ctx, &owned[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.DetachAttachmentsFromUser(userID); err != nil {
+		return fmt.Errorf("failed to detach attachments: %w", err)
+	}
+	return nil
+}
+
+// StoredFileKeys returns the keys of all attached files, a reference source for the
+// storages.Janitor.
+func (s *AttachmentService) StoredFileKeys(ctx context.Context) ([]string, error) {
+	list, err := s.repo.FindAllAttachments()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch attachments: %w", err)
+	}
+	keys := make([]string, 0, len(list))
+	for _, attachment := range list {
+		keys = append(keys, attachment.StorageKey)
+	}
+	return keys, nil
+}
+
+// findOwner resolves the owner and checks that the user may contribute to it. Owners the
+// user has no access to are reported as not found.
+func (s *AttachmentService) findOwner(ctx context.Context, userID int64, ownerType string, ownerUUID string) (int64, error) {
+	policy, ok := s.policies[ownerType]
+	if !ok {
+		return 0, ErrUnknownOwnerType
+	}
+	ownerID, err := policy.Resolve(ctx, ownerUUID)
+	if err != nil {
+		return 0, ErrOwnerNotFound
+	}
+	access, err := policy.Access(ctx, userID, ownerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check access: %w", err)
+	}
+	if access == AccessNone {
+		return 0, ErrOwnerNotFound
+	}
+	return ownerID, nil
+}
+
+// remove deletes the attachment and its file once no attachment shares the file anymore.
+func (s *AttachmentService) remove(ctx context.Context, attachment *attachments.Attachment) error {
+	lock := s.lockFor(attachment.Checksum)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := s.repo.DeleteAttachment(attachment.ID); err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	remaining, err := s.repo.FindAttachmentsByChecksum(attachment.Checksum)
+	if err != nil {
+		// File dibiarkan, job pembersih akan menghapusnya kalau memang tidak dipakai
+		return nil
+	}
+	if len(remaining) == 0 {
+		s.removeFile(ctx, attachment.StorageKey)
+	}
+	return nil
+}
+
+func (s *AttachmentService) lockFor(checksum string) *sync.Mutex {
+	index := 0
+	if decoded, err := hex.DecodeString(checksum[:2]); err == nil {
+		index = int(decoded[0])
+	}
+	return &s.locks[index%len(s.locks)]
+}
+
+// removeFile deletes a file no attachment refers to. Failures are only logged, the
+// janitor removes the file later.
+func (s *AttachmentService) removeFile(ctx context.Context, key string) {
+	if err := s.storage.Delete(ctx, key); err != nil {
+		loggers.Log.Error("failed to delete attachment file", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+	}
+}
+
+func (s *AttachmentService) toResponse(attachment *attachments.Attachment, ownerUUID string) attachments.AttachmentResponse {
+	return attachments.AttachmentResponse{
+		UUID:      attachment.UUID,
+		OwnerType: attachment.OwnerType,
+		OwnerUUID: ownerUUID,
+		MimeType:  attachment.MimeType,
+		Size:      attachment.Size,
+		Checksum:  attachment.Checksum,
+		Metadata:  attachment.Metadata,
+		URL:       s.storage.URL(attachment.StorageKey),
+		CreatedAt: attachment.CreatedAt,
+	}
+}
+
+func validateMetadata(metadata map[string]string) error {
+	if len(metadata) > 20 {
+		return ErrInvalidMetadata
+	}
+	for key, value := range metadata {
+		if key == "" || len(key) > 64 || len(value) > 1024 {
+			return ErrInvalidMetadata
+		}
+	}
+	return nil
+}
+
+// extensionFor returns the file extension of the allowed content types by default, the
+// stored file keeps a type the local driver can serve it with.
+func extensionFor(mimeType string) string {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	switch strings.TrimSpace(mediaType) {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "application/pdf":
+		return ".pdf"
+	case "text/plain":
+		return ".txt"
+	case "application/zip":
+		return ".zip"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,16 @@
+package attachment_services
+
+import "errors"
+
+var (
+	// ErrOwnerNotFound is also returned to users without access to the owner, so the
+	// existence of rows they cannot see is not revealed.
+	ErrOwnerNotFound      = errors.New("owner not found")
+	ErrUnknownOwnerType   = errors.New("files cannot be attached to this type")
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrNotAllowed         = errors.New("only the uploader or a manager of the owner can delete this attachment")
+	ErrEmptyFile          = errors.New("file is empty")
+	ErrFileTooLarge       = errors.New("file is too large")
+	ErrUnsupportedType    = errors.New("file type is not allowed")
+	ErrInvalidMetadata    = errors.New("metadata must be at most 20 entries with keys up to 64 and values up to 1024 characters")
+)
@@ -0,0 +1,66 @@
+package attachment_services
+
+import (
+	"context"
+	"gin/src/repositories/organization_repositories"
+	repositories "gin/src/repositories/user_repositories"
+)
+
+// Access is what a user may do with the attachments of an owner.
+type Access int
+
+const (
+	AccessNone       Access = iota
+	AccessContribute        // list, upload and delete own attachments
+	AccessManage            // also delete the attachments of others
+)
+
+// OwnerPolicy connects an owner type to its table. Resolve turns the public UUID of the owner
+// into its ID, Access decides what the user may do with the attachments of the owner.
+type OwnerPolicy struct {
+	Resolve func(ctx context.Context, ownerUUID string) (int64, error)
+	Access  func(ctx context.Context, userID int64, ownerID int64) (Access, error)
+}
+
+// UserOwner lets users attach files to their own account only.
+func UserOwner(userRepo repositories.UserRepository) OwnerPolicy {
+	return OwnerPolicy{
+		Resolve: func(ctx context.Context, ownerUUID string) (int64, error) {
+			user, err := userRepo.FindByUUID(ownerUUID)
+			if err != nil {
+				return 0, err
+			}
+			return user.ID, nil
+		},
+		Access: func(ctx context.Context, userID int64, ownerID int64) (Access, error) {
+			if userID == ownerID {
+				return AccessManage, nil
+			}
+			return AccessNone, nil
+		},
+	}
+}
+
+// OrganizationOwner lets every member attach files to the organization, owners and admins
+// may also delete the files of other members.
+func OrganizationOwner(orgRepo organization_repositories.OrganizationRepositoryInterface) OwnerPolicy {
+	return OwnerPolicy{
+		Resolve: func(ctx context.Context, ownerUUID string) (int64, error) {
+			organization, err := orgRepo.FindOrganizationByUUID(ownerUUID)
+			if err != nil {
+				return 0, err
+			}
+			return organization.ID, nil
+		},
+		Access: func(ctx context.Context, userID int64, ownerID int64) (Access, error) {
+			membership, err := orgRepo.FindMembership(ownerID, userID)
+			if err != nil {
+				return AccessNone, nil
+			}
+			if membership.CanManageMembers() {
+				return AccessManage, nil
+			}
+			return AccessContribute, nil
+		},
+	}
+}
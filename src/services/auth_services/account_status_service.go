@@ -0,0 +1,16 @@
+package auth_services
+
+import (
+	"fmt"
+	"time"
+)
+
+// checkAccountStatus loads the user and refuses it with a users.AccountStatusError when the
+// account is suspended or banned. An expired suspension counts as active again.
+func (s *AuthService) checkAccountStatus(userID int64) error {
+	user, err := s.authRepo.FindByID(userID)
+	if err != nil {
+		return fmt.Errorf("user not found: %w", err)
+	}
+	return user.StatusError(time.Now())
+}
@@ -2,23 +2,61 @@ package auth_services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"gin/src/configs/security"
+	"gin/src/entities/auth"
+	"gin/src/entities/users"
+	"gin/src/helpers"
 	"gin/src/repositories/auth_repositories"
+	"gin/src/utils/hashers"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"gin/src/utils/oidc"
+	"gin/src/utils/passwords"
+	"gin/src/utils/throttles"
+	"gin/src/utils/useragents"
 	"os"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthServiceInterface interface {
 	Register(ctx context.Context, email string, username string, password string) (map[string]interface{}, error)
-	Login(ctx context.Context, email string, password string) (gin.H, error)
-	GenerateTokens(userID int64) (*TokenResult, error)
+	Login(ctx context.Context, email string, password string, opts ...TokenOptions) (gin.H, error)
+	GenerateTokens(userID int64, opts ...TokenOptions) (*TokenResult, error)
 	RefreshToken(ctx context.Context, refreshTokenString string) (*TokenResult, error)
+	RefreshClientToken(ctx context.Context, refreshTokenString string, oauthClientID int64, accessScopes []string) (*TokenResult, error)
 	VerifyToken(token string) (int64, error)
 	RevokeToken(ctx context.Context, tokenString string) error
+	ListSessions(ctx context.Context, userID int64, currentSessionID int64) ([]auth.SessionResponse, error)
+	RevokeSession(ctx context.Context, userID int64, sessionUUID string) error
+	LogoutAll(ctx context.Context, userID int64) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, newPassword string) error
+	SendVerificationEmail(ctx context.Context, user *users.User) error
+	VerifyEmail(ctx context.Context, userUUID string, expires string, signature string) error
+	ResendVerificationEmail(ctx context.Context, email string) error
+	EnrollMFA(ctx context.Context, userID int64) (*auth.MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, userID int64, code string) ([]string, error)
+	DisableMFA(ctx context.Context, userID int64, password string, code string) error
+	VerifyMFALogin(ctx context.Context, mfaToken string, code string, opts ...TokenOptions) (gin.H, error)
+	GenerateAccessToken(userID int64, opts ...TokenOptions) (*TokenResult, error)
+	Impersonate(ctx context.Context, adminID int64, targetUUID string, ipAddress string, userAgent string) (*TokenResult, error)
+	SendMagicLink(ctx context.Context, email string) error
+	MagicLinkLogin(ctx context.Context, token string, expires string, signature string, opts ...TokenOptions) (gin.H, error)
+	OIDCProviders() []string
+	StartOIDCLogin(ctx context.Context, providerName string) (gin.H, error)
+	OIDCLogin(ctx context.Context, providerName string, code string, state string, opts ...TokenOptions) (gin.H, error)
+	ListExternalIdentities(ctx context.Context, userID int64) ([]auth.ExternalIdentityResponse, error)
+	UnlinkExternalIdentity(ctx context.Context, userID int64, identityUUID string) error
+	CreatePersonalAccessToken(ctx context.Context, userID int64, name string, scopes []string, grantedScopes []string, expiresIn time.Duration) (*auth.PersonalAccessTokenResponse, error)
+	ListPersonalAccessTokens(ctx context.Context, userID int64) ([]auth.PersonalAccessTokenResponse, error)
+	RevokePersonalAccessToken(ctx context.Context, userID int64, tokenUUID string) error
 }
 
 func getJWTSecret() string {
@@ -30,11 +68,71 @@ func getJWTSecret() string {
 }
 
 type AuthService struct {
-	authRepo auth_repositories.AuthRepositoryInterface
+	authRepo                auth_repositories.AuthRepositoryInterface
+	mailer                  mailers.Mailer
+	tokenConfig             security.TokenConfig
+	passwordResetConfig     security.PasswordResetConfig
+	emailVerificationConfig security.EmailVerificationConfig
+	mfaConfig               security.MFAConfig
+	lockoutConfig           security.LockoutConfig
+	verificationThrottle    *throttles.Throttle
+	magicLinkConfig         security.MagicLinkConfig
+	impersonationConfig     security.ImpersonationConfig
+	magicLinkThrottle       *throttles.Throttle
+	oidcConfig              security.OIDCConfig
+	oidcProviders           map[string]*oidc.Provider
 }
 
-func NewAuthService(repo auth_repositories.AuthRepositoryInterface) *AuthService {
-	return &AuthService{authRepo: repo}
+func NewAuthService(repo auth_repositories.AuthRepositoryInterface, mailer mailers.Mailer) *AuthService {
+	emailVerificationConfig := security.LoadEmailVerificationConfig()
+	magicLinkConfig := security.LoadMagicLinkConfig()
+
+	oidcConfig := security.LoadOIDCConfig()
+	oidcProviders := make(map[string]*oidc.Provider, len(oidcConfig.Providers))
+	for _, provider := range oidcConfig.Providers {
+		oidcProviders[provider.Name] = oidc.NewProvider(provider.Name, provider.Issuer, provider.ClientID, provider.ClientSecret, provider.RedirectURL, provider.Scopes)
+	}
+
+	return &AuthService{
+		authRepo:                repo,
+		mailer:                  mailer,
+		tokenConfig:             security.LoadTokenConfig(),
+		passwordResetConfig:     security.LoadPasswordResetConfig(),
+		emailVerificationConfig: emailVerificationConfig,
+		mfaConfig:               security.LoadMFAConfig(),
+		lockoutConfig:           security.LoadLockoutConfig(),
+		verificationThrottle:    throttles.NewThrottle(emailVerificationConfig.ResendInterval),
+		magicLinkConfig:         magicLinkConfig,
+		impersonationConfig:     security.LoadImpersonationConfig(),
+		magicLinkThrottle:       throttles.NewThrottle(magicLinkConfig.ResendInterval),
+		oidcConfig:              oidcConfig,
+		oidcProviders:           oidcProviders,
+	}
+}
+
+// TokenOptions describes the session a token pair is generated for.
+// ClientType selects the configured lifetimes (web, mobile, service) and RememberMe
+// extends the refresh lifetime. UserAgent and IPAddress are recorded on the session.
+// SessionID, SessionStartedAt and RefreshExpiresAt are carried over from the previous
+// refresh token when a session is refreshed. OauthClientID and Scopes limit tokens issued
+// to an OAuth client, and DeviceName overrides the name derived from the user agent.
+// LoginMethod is recorded in the login history of a new session (default password).
+// Organization (a UUID) is added to the access token of GenerateAccessToken as the "org"
+// claim, which selects the current organization (see middleware.RequireOrganization).
+type TokenOptions struct {
+	ClientType       string
+	RememberMe       bool
+	UserAgent        string
+	IPAddress        string
+	DeviceName       string
+	SessionID        int64
+	SessionStartedAt time.Time
+	RefreshExpiresAt time.Time
+	OauthClientID    int64
+	Scopes           []string
+	AccessScopes     []string // narrows the scopes of the access token, the refresh token keeps Scopes
+	LoginMethod      string
+	Organization     string
 }
 
 type TokenResult struct {
@@ -42,46 +140,242 @@ type TokenResult struct {
 	RefreshToken     string    `json:"refresh_token"`
 	AccessExpiresAt  time.Time `json:"access_expires_at"`
 	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	SessionID        int64     `json:"-"`
 }
 
 // Register handles the registration logic
 func (s *AuthService) Register(ctx context.Context, email string, username string, password string) (map[string]interface{}, error) {
+	if err := passwords.Default().Validate(password, passwords.Input{Email: email, Username: username}); err != nil {
+		return nil, err
+	}
+
 	response, err := s.authRepo.Register(ctx, email, username, password)
 	if err != nil {
 		return nil, fmt.Errorf("could not register user: %w", err)
 	}
+
+	// Kirim link verifikasi email, gagal kirim tidak membatalkan registrasi
+	// karena user masih bisa meminta link baru lewat /user/email/resend
+	if user, err := s.authRepo.FindByEmail(email); err == nil {
+		passwords.Default().Remember(s.authRepo, user.ID, user.Password)
+
+		if err := s.SendVerificationEmail(ctx, user); err != nil {
+			loggers.Log.Error("failed to send verification email", map[string]interface{}{
+				"user_id": user.ID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
 	return response, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, email string, password string) (gin.H, error) {
+func (s *AuthService) Login(ctx context.Context, email string, password string, opts ...TokenOptions) (gin.H, error) {
+	var ip string
+	if len(opts) > 0 {
+		ip = opts[0].IPAddress
+	}
+
+	// Tolak lebih dulu jika akun / IP sedang dikunci atau masih dalam masa backoff
+	if err := s.checkLoginAllowed(email, ip); err != nil {
+		return nil, err
+	}
 
 	user, err := s.authRepo.FindByEmail(email)
 	if err != nil {
+		// Email yang tidak terdaftar dihitung sama seperti akun, lock tidak membocorkan email terdaftar
+		s.recordLoginFailure(ctx, email, ip)
 		return nil, fmt.Errorf("invalid email: %w", err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, fmt.Errorf("invalid password: %w", err)
+	if ok, err := hashers.Default().Verify(password, user.Password); err != nil || !ok {
+		s.recordLoginFailure(ctx, email, ip)
+		s.recordLoginEvent(user.ID, auth.LoginMethodPassword, false, "invalid password", opts...)
+		return nil, fmt.Errorf("invalid password")
 	}
 
-	tokens, err := s.GenerateTokens(user.ID)
+	s.clearLoginFailures(ctx, email)
+	s.rehashPasswordIfNeeded(user.ID, user.Password, password)
+
+	// Akun yang disuspend / diblokir ditolak setelah password terbukti benar
+	if err := user.StatusError(time.Now()); err != nil {
+		s.recordLoginEvent(user.ID, auth.LoginMethodPassword, false, "account "+user.CurrentStatus(time.Now()), opts...)
+		return nil, err
+	}
+
+	if s.emailVerificationConfig.Enforce == security.EnforceVerificationLogin && !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	// User dengan TOTP aktif harus melanjutkan ke langkah kedua (VerifyMFALogin)
+	if twoFactor, err := s.authRepo.FindTwoFactorByUserID(user.ID); err == nil && twoFactor.IsEnabled() {
+		var opt TokenOptions
+		if len(opts) > 0 {
+			opt = opts[0]
+		}
+		return s.mfaChallengeResponse(user.ID, opt)
+	}
+
+	tokens, err := s.GenerateTokens(user.ID, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed generate tokens: %w", err)
 	}
 
+	return tokenResponse(tokens), nil
+}
+
+// rehashPasswordIfNeeded upgrades a stored hash made with an outdated algorithm or cost once the
+// plain password is known. Failures are only logged, the login itself already succeeded.
+func (s *AuthService) rehashPasswordIfNeeded(userID int64, encoded string, password string) {
+	hasher := hashers.Default()
+	if !hasher.NeedsRehash(encoded) {
+		return
+	}
+
+	hashed, err := hasher.Hash(password)
+	if err == nil {
+		err = s.authRepo.UpdatePassword(userID, hashed)
+	}
+	if err != nil {
+		loggers.Log.Error("failed to rehash password", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// recordLoginEvent adds a successful or failed sign-in to the login history of the user.
+// Failures are only logged, the history must never block a login.
+func (s *AuthService) recordLoginEvent(userID int64, method string, success bool, reason string, opts ...TokenOptions) {
+	event := auth.LoginEvent{
+		UserID:  userID,
+		Method:  method,
+		Success: success,
+		Reason:  reason,
+	}
+	if len(opts) > 0 {
+		event.IPAddress = opts[0].IPAddress
+		event.UserAgent = opts[0].UserAgent
+	}
+	if event.Method == "" {
+		event.Method = auth.LoginMethodPassword
+	}
+
+	if err := s.authRepo.CreateLoginEvent(&event); err != nil {
+		loggers.Log.Error("failed to save login event", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// cancelPendingDeletion cancels a scheduled account deletion when the user signs in again
+// during the grace period. Failures are only logged, the login itself already succeeded.
+func (s *AuthService) cancelPendingDeletion(userID int64) {
+	user, err := s.authRepo.FindByID(userID)
+	if err != nil || !user.DeletionPending {
+		return
+	}
+
+	if err := s.authRepo.CancelAccountDeletion(userID); err != nil {
+		loggers.Log.Error("failed to cancel account deletion", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// tokenResponse formats a token pair as the login response.
+func tokenResponse(tokens *TokenResult) gin.H {
 	return gin.H{
 		"token_type":         "Bearer",
 		"access_token":       tokens.AccessToken,
 		"access_expires_at":  tokens.AccessExpiresAt,
 		"refresh_token":      tokens.RefreshToken,
 		"refresh_expires_at": tokens.RefreshExpiresAt,
-	}, nil
+	}
 }
 
-func (s *AuthService) GenerateTokens(userID int64) (*TokenResult, error) {
+// GenerateTokens creates a new access and refresh token pair for the given user and saves
+// it to the database. The lifetimes come from the token config of the client type.
+// With sliding sessions enabled every refresh extends the refresh token again, otherwise the
+// refresh token keeps the expiry of the session. The refresh token never outlives the max
+// session age counted from the first login of the session.
+func (s *AuthService) GenerateTokens(userID int64, opts ...TokenOptions) (*TokenResult, error) {
+	var opt TokenOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.ClientType == "" {
+		opt.ClientType = security.ClientWeb
+	}
+
+	// Login baru, bukan refresh maupun token untuk OAuth client
+	newLogin := opt.SessionID == 0 && opt.OauthClientID == 0
+
+	// Tidak ada token baru (login maupun refresh) untuk akun yang disuspend / diblokir
+	if err := s.checkAccountStatus(userID); err != nil {
+		var statusErr *users.AccountStatusError
+		if newLogin && errors.As(err, &statusErr) {
+			s.recordLoginEvent(userID, opt.LoginMethod, false, "account "+statusErr.Status, opt)
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if opt.SessionStartedAt.IsZero() {
+		opt.SessionStartedAt = now
+	}
+
+	lifetime := s.tokenConfig.LifetimeFor(opt.ClientType)
+	refreshTTL := lifetime.Refresh
+	if opt.RememberMe && s.tokenConfig.RememberMeRefresh > refreshTTL {
+		refreshTTL = s.tokenConfig.RememberMeRefresh
+	}
+
+	refreshTokenLifetime := now.Add(refreshTTL)
+	if !s.tokenConfig.SlidingSession && !opt.RefreshExpiresAt.IsZero() {
+		refreshTokenLifetime = opt.RefreshExpiresAt
+	}
+	if s.tokenConfig.MaxSessionAge > 0 {
+		maxSessionEnd := opt.SessionStartedAt.Add(s.tokenConfig.MaxSessionAge)
+		if refreshTokenLifetime.After(maxSessionEnd) {
+			refreshTokenLifetime = maxSessionEnd
+		}
+	}
+	if !refreshTokenLifetime.After(now) {
+		return nil, fmt.Errorf("session has expired, please login again")
+	}
+
+	accessTokenLifetime := now.Add(lifetime.Access)
+	if accessTokenLifetime.After(refreshTokenLifetime) {
+		accessTokenLifetime = refreshTokenLifetime
+	}
+
+	// Login baru membatalkan penghapusan akun yang masih dalam masa tenggang
+	if newLogin {
+		s.cancelPendingDeletion(userID)
+	}
 
-	accessTokenLifetime := time.Now().Add(50 * time.Minute)
-	refreshTokenLifetime := time.Now().Add(24 * 24 * time.Minute)
+	// Buat sesi baru saat login, atau perbarui sesi lama saat refresh
+	if opt.SessionID == 0 {
+		deviceName := opt.DeviceName
+		if deviceName == "" {
+			deviceName = useragents.DeviceName(opt.UserAgent)
+		}
+		session := auth.Session{
+			UserID:     userID,
+			UserAgent:  opt.UserAgent,
+			IPAddress:  opt.IPAddress,
+			DeviceName: deviceName,
+		}
+		if err := s.authRepo.CreateSession(&session); err != nil {
+			return nil, fmt.Errorf("failed to create session: %w", err)
+		}
+		opt.SessionID = session.ID
+	} else if err := s.authRepo.TouchSession(opt.SessionID, opt.IPAddress); err != nil {
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
 
 	accessTokenString, err := s.createJWTToken(userID, accessTokenLifetime)
 	if err != nil {
@@ -93,30 +387,130 @@ func (s *AuthService) GenerateTokens(userID int64) (*TokenResult, error) {
 		return nil, fmt.Errorf("failed to create JWT token for refresh token: %w", err)
 	}
 
+	accessScopes := opt.Scopes
+	if len(opt.AccessScopes) > 0 {
+		accessScopes = opt.AccessScopes
+	}
+
 	// Simpan ke database via repository
-	err = s.authRepo.SaveTokens(userID, accessTokenString, accessTokenLifetime, refreshTokenString, refreshTokenLifetime)
+	access := auth.AccessToken{
+		UserID:        userID,
+		SessionID:     opt.SessionID,
+		Token:         accessTokenString,
+		OauthClientID: opt.OauthClientID,
+		Scopes:        strings.Join(accessScopes, ","),
+		ExpiresAt:     accessTokenLifetime,
+	}
+	refresh := auth.RefreshToken{
+		UserID:           userID,
+		SessionID:        opt.SessionID,
+		Token:            refreshTokenString,
+		ExpiresAt:        refreshTokenLifetime,
+		ClientType:       opt.ClientType,
+		RememberMe:       opt.RememberMe,
+		OauthClientID:    opt.OauthClientID,
+		Scopes:           strings.Join(opt.Scopes, ","),
+		SessionStartedAt: opt.SessionStartedAt,
+	}
+	err = s.authRepo.SaveTokens(&access, &refresh)
 	if err != nil {
 		return nil, fmt.Errorf("save token to database error: %w", err)
 	}
 
+	if newLogin {
+		s.recordLoginEvent(userID, opt.LoginMethod, true, "", opt)
+	}
+
 	return &TokenResult{
 		AccessToken:      accessTokenString,
 		RefreshToken:     refreshTokenString,
 		AccessExpiresAt:  accessTokenLifetime,
 		RefreshExpiresAt: refreshTokenLifetime,
+		SessionID:        opt.SessionID,
 	}, nil
 }
 
-func (s *AuthService) createJWTToken(userID int64, exp time.Time) (string, error) {
+// GenerateAccessToken creates a single access token without a refresh token, for grants that must
+// not be refreshed such as the OAuth client credentials grant or an organization token. The token
+// belongs to opt.SessionID when given, so signing out of that session revokes it too. The lifetime
+// is the access lifetime of the client type.
+func (s *AuthService) GenerateAccessToken(userID int64, opts ...TokenOptions) (*TokenResult, error) {
+	var opt TokenOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.ClientType == "" {
+		opt.ClientType = security.ClientService
+	}
+
+	if err := s.checkAccountStatus(userID); err != nil {
+		return nil, err
+	}
+
+	var extraClaims []jwt.MapClaims
+	if opt.Organization != "" {
+		extraClaims = append(extraClaims, jwt.MapClaims{"org": opt.Organization})
+	}
+
+	expiresAt := time.Now().Add(s.tokenConfig.LifetimeFor(opt.ClientType).Access)
+	tokenString, err := s.createJWTToken(userID, expiresAt, extraClaims...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWT token for access token: %w", err)
+	}
+
+	access := auth.AccessToken{
+		UserID:        userID,
+		SessionID:     opt.SessionID,
+		Token:         tokenString,
+		OauthClientID: opt.OauthClientID,
+		Scopes:        strings.Join(opt.Scopes, ","),
+		ExpiresAt:     expiresAt,
+	}
+	if err := s.authRepo.SaveAccessToken(&access); err != nil {
+		return nil, fmt.Errorf("save token to database error: %w", err)
+	}
+
+	return &TokenResult{
+		AccessToken:     tokenString,
+		AccessExpiresAt: expiresAt,
+	}, nil
+}
+
+// createJWTToken signs a token for the given user. The jti claim keeps tokens unique even
+// when two tokens of the same user are created with the same expiry. Extra claims are added as given.
+func (s *AuthService) createJWTToken(userID int64, exp time.Time, extraClaims ...jwt.MapClaims) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID,
 		"exp":     exp.Unix(),
+		"jti":     helpers.GenerateUUID(),
+	}
+	for _, extra := range extraClaims {
+		for key, value := range extra {
+			claims[key] = value
+		}
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(getJWTSecret()))
 }
 
+// RefreshToken exchanges a refresh token for a new token pair of the same session. Suspended
+// and banned users are refused with a users.AccountStatusError by GenerateTokens.
+// Only first-party refresh tokens are accepted, tokens issued to OAuth clients have to be
+// refreshed by their client through RefreshClientToken.
 func (s *AuthService) RefreshToken(ctx context.Context, refreshTokenString string) (*TokenResult, error) {
+	return s.rotateRefreshToken(ctx, refreshTokenString, 0, nil)
+}
+
+// RefreshClientToken works like RefreshToken for a refresh token issued to the given OAuth client.
+// A non-empty accessScopes narrows the new access token to those scopes, the new refresh token
+// keeps the scopes of the original grant (RFC 6749 section 6).
+func (s *AuthService) RefreshClientToken(ctx context.Context, refreshTokenString string, oauthClientID int64, accessScopes []string) (*TokenResult, error) {
+	return s.rotateRefreshToken(ctx, refreshTokenString, oauthClientID, accessScopes)
+}
+
+// rotateRefreshToken exchanges the refresh token when it was issued to oauthClientID,
+// 0 meaning the first-party clients.
+func (s *AuthService) rotateRefreshToken(ctx context.Context, refreshTokenString string, oauthClientID int64, accessScopes []string) (*TokenResult, error) {
 
 	userID, err := s.VerifyToken(refreshTokenString)
 	if err != nil {
@@ -128,16 +522,39 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshTokenString strin
 		return nil, fmt.Errorf("refresh token not found: %w", err)
 
 	}
+	// Refresh token milik OAuth client tidak bisa ditukar di endpoint lain (dan sebaliknya)
+	if refreshTokenRecord.OauthClientID != oauthClientID {
+		return nil, fmt.Errorf("refresh token not found")
+	}
 	if refreshTokenRecord.Claimed {
-		return nil, fmt.Errorf("refresh token already claimed and used: %w", err)
+		return nil, errors.New("refresh token already claimed and used")
+	}
+	if refreshTokenRecord.Revoked {
+		return nil, fmt.Errorf("refresh token has been revoked")
 	}
 
-	tokenResult, err := s.GenerateTokens(userID)
+	// Klaim dulu sebelum token baru dibuat, refresh paralel dengan token yang sama hanya menang sekali
+	claimed, err := s.authRepo.ClaimRefreshToken(refreshTokenRecord.ID)
 	if err != nil {
-		return nil, fmt.Errorf("error generate tokens: %w", err)
+		return nil, fmt.Errorf("failed to claim refresh token: %w", err)
+	}
+	if !claimed {
+		return nil, errors.New("refresh token already claimed and used")
 	}
 
-	_ = s.authRepo.MarkRefreshTokenAsUsed(refreshTokenRecord.ID)
+	tokenResult, err := s.GenerateTokens(userID, TokenOptions{
+		ClientType:       refreshTokenRecord.ClientType,
+		RememberMe:       refreshTokenRecord.RememberMe,
+		SessionID:        refreshTokenRecord.SessionID,
+		SessionStartedAt: refreshTokenRecord.SessionStartedAt,
+		RefreshExpiresAt: refreshTokenRecord.ExpiresAt,
+		OauthClientID:    refreshTokenRecord.OauthClientID,
+		Scopes:           auth.SplitScopes(refreshTokenRecord.Scopes),
+		AccessScopes:     accessScopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error generate tokens: %w", err)
+	}
 
 	return tokenResult, nil
 }
@@ -185,5 +602,60 @@ func (s *AuthService) RevokeToken(ctx context.Context, tokenString string) error
 		return fmt.Errorf("failed to mark token as revoked: %w", err)
 	}
 
+	// Logout juga mengakhiri sesi, supaya refresh token di sesi ini tidak bisa dipakai lagi
+	if tokenRecord.SessionID != 0 {
+		if err := s.authRepo.RevokeSession(tokenRecord.SessionID); err != nil {
+			return fmt.Errorf("failed to revoke session: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ListSessions returns the active sessions (devices) of the user, most recently used first.
+// The session with currentSessionID is flagged as the current one.
+func (s *AuthService) ListSessions(ctx context.Context, userID int64, currentSessionID int64) ([]auth.SessionResponse, error) {
+	sessions, err := s.authRepo.FindSessionsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list sessions: %w", err)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	response := make([]auth.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, auth.SessionResponse{
+			UUID:       session.UUID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			LastUsedAt: session.LastUsedAt,
+			CreatedAt:  session.CreatedAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return response, nil
+}
+
+// RevokeSession revokes a single session of the user and every token issued for it.
+func (s *AuthService) RevokeSession(ctx context.Context, userID int64, sessionUUID string) error {
+	session, err := s.authRepo.FindSessionByUUID(userID, sessionUUID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authRepo.RevokeSession(session.ID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// LogoutAll revokes every access token, refresh token and session of the user.
+func (s *AuthService) LogoutAll(ctx context.Context, userID int64) error {
+	if err := s.authRepo.RevokeAllUserTokens(userID); err != nil {
+		return fmt.Errorf("failed to revoke all sessions: %w", err)
+	}
 	return nil
 }
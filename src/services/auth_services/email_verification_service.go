@@ -0,0 +1,92 @@
+package auth_services
+
+import (
+	"context"
+	"fmt"
+	"gin/src/entities/users"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"gin/src/utils/signers"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SendVerificationEmail emails a signed verification link to the user. The link carries the
+// user UUID, an expiry and an HMAC signature over both plus the current email, so it stops
+// working once it expires or the email address changes.
+func (s *AuthService) SendVerificationEmail(ctx context.Context, user *users.User) error {
+	expires := strconv.FormatInt(time.Now().Add(s.emailVerificationConfig.LinkTTL).Unix(), 10)
+	signature := signers.Sign("verify-email", user.UUID, strings.ToLower(user.Email), expires)
+
+	query := url.Values{}
+	query.Set("user", user.UUID)
+	query.Set("expires", expires)
+	query.Set("signature", signature)
+	link := s.emailVerificationConfig.VerifyURL + "?" + query.Encode()
+
+	err := s.mailer.Send(ctx, mailers.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Username, link, s.emailVerificationConfig.LinkTTL,
+		),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+// VerifyEmail checks the signed link parameters and marks the email of the user as verified.
+// Verifying an already verified email is not an error.
+func (s *AuthService) VerifyEmail(ctx context.Context, userUUID string, expires string, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return fmt.Errorf("invalid or expired verification link")
+	}
+
+	user, err := s.authRepo.FindByUUID(userUUID)
+	if err != nil {
+		return fmt.Errorf("invalid or expired verification link")
+	}
+
+	if !signers.Verify(signature, "verify-email", user.UUID, strings.ToLower(user.Email), expires) {
+		return fmt.Errorf("invalid or expired verification link")
+	}
+
+	if user.IsEmailVerified() {
+		return nil
+	}
+
+	if err := s.authRepo.MarkEmailAsVerified(user.ID); err != nil {
+		return fmt.Errorf("failed to verify email: %w", err)
+	}
+	return nil
+}
+
+// ResendVerificationEmail sends a new verification link, throttled per address.
+// Like ForgotPassword it does not reveal whether the email is registered or already verified.
+func (s *AuthService) ResendVerificationEmail(ctx context.Context, email string) error {
+	if ok, wait := s.verificationThrottle.Allow(strings.ToLower(email)); !ok {
+		return &ThrottleError{
+			Message:    "verification email was sent recently, please try again later",
+			RetryAfter: wait,
+		}
+	}
+
+	user, err := s.authRepo.FindByEmail(email)
+	if err != nil || user.IsEmailVerified() {
+		return nil
+	}
+
+	if err := s.SendVerificationEmail(ctx, user); err != nil {
+		loggers.Log.Error("failed to resend verification email", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+	return nil
+}
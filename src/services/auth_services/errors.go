@@ -0,0 +1,33 @@
+package auth_services
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrEmailNotVerified = errors.New("email address is not verified, please check your inbox for the verification link")
+
+// ErrScopeNotGranted is returned when a token is requested with a scope the current token does not hold.
+var ErrScopeNotGranted = errors.New("cannot grant a scope the current token does not hold")
+
+// ThrottleError is returned when an action is refused because it was attempted too often.
+// RetryAfter tells the client how long to wait before trying again.
+type ThrottleError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *ThrottleError) Error() string {
+	return e.Message
+}
+
+// LockedError is returned when an account is temporarily locked after too many failed logins.
+// RetryAfter tells the client when the lock expires.
+type LockedError struct {
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return e.Message
+}
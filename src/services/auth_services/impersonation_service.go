@@ -0,0 +1,96 @@
+package auth_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin/src/entities/audits"
+	"gin/src/entities/auth"
+	"gin/src/entities/roles"
+	"gin/src/services/role_services"
+	"gin/src/utils/auditors"
+	"gin/src/utils/loggers"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var ErrImpersonationNotAllowed = errors.New("this user cannot be impersonated")
+
+// Impersonate issues a short-lived access token that acts as the target user. The token carries an
+// "act" claim naming the admin and cannot be refreshed. Users who may impersonate others cannot be
+// impersonated themselves, and neither can users holding any permission the admin lacks, so an
+// impersonation never gains more permissions than the admin has.
+func (s *AuthService) Impersonate(ctx context.Context, adminID int64, targetUUID string, ipAddress string, userAgent string) (*TokenResult, error) {
+	admin, err := s.authRepo.FindByID(adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.authRepo.FindByUUID(targetUUID)
+	if err != nil {
+		return nil, err
+	}
+	if target.ID == admin.ID {
+		return nil, fmt.Errorf("you cannot impersonate yourself")
+	}
+
+	privileged, err := role_services.Default().HasPermission(target.ID, roles.PermissionUsersImpersonate)
+	if err != nil {
+		return nil, fmt.Errorf("could not check permissions: %w", err)
+	}
+	if privileged {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	// Permission target harus bagian dari permission admin
+	covered, err := role_services.Default().CoversPermissions(admin.ID, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("could not check permissions: %w", err)
+	}
+	if !covered {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	expiresAt := time.Now().Add(s.impersonationConfig.TokenTTL)
+	tokenString, err := s.createJWTToken(target.ID, expiresAt, jwt.MapClaims{
+		"act": map[string]interface{}{
+			"sub":     admin.UUID,
+			"user_id": admin.ID,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWT token for access token: %w", err)
+	}
+
+	access := auth.AccessToken{
+		UserID:         target.ID,
+		Token:          tokenString,
+		ImpersonatorID: admin.ID,
+		ExpiresAt:      expiresAt,
+	}
+	if err := s.authRepo.SaveAccessToken(&access); err != nil {
+		return nil, fmt.Errorf("save token to database error: %w", err)
+	}
+
+	err = auditors.Default().Record(ctx, audits.AuditLog{
+		Event:     audits.EventImpersonationStarted,
+		ActorID:   admin.ID,
+		UserID:    target.ID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Details:   auditors.Details(map[string]interface{}{"expires_at": expiresAt}),
+	})
+	if err != nil {
+		loggers.Log.Error("failed to record impersonation", map[string]interface{}{
+			"admin_id": admin.ID,
+			"user_id":  target.ID,
+			"error":    err.Error(),
+		})
+	}
+
+	return &TokenResult{
+		AccessToken:     tokenString,
+		AccessExpiresAt: expiresAt,
+	}, nil
+}
@@ -0,0 +1,316 @@
+package auth_services
+
+import (
+	"context"
+	"fmt"
+	"gin/src/entities/auth"
+	"gin/src/helpers"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"strings"
+	"time"
+)
+
+const (
+	accountAttemptPrefix = "account:"
+	ipAttemptPrefix      = "ip:"
+)
+
+func accountAttemptKey(email string) string {
+	return accountAttemptPrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ip string) string {
+	return ipAttemptPrefix + ip
+}
+
+// loginAttemptRetries bounds how often a login attempt record is re-read after losing a
+// race against a parallel request.
+const loginAttemptRetries = 5
+
+// attemptKeys returns the keys failed logins are tracked under, the account (when the email
+// is given) and, when known, the client IP.
+func attemptKeys(email string, ip string) []string {
+	var keys []string
+	if email != "" {
+		keys = append(keys, accountAttemptKey(email))
+	}
+	if ip != "" {
+		keys = append(keys, ipAttemptKey(ip))
+	}
+	return keys
+}
+
+// checkLoginAllowed refuses a login attempt while the account or IP is locked (LockedError for
+// the account, registered or not, ThrottleError for the IP) or still inside the exponential backoff of its last
+// failure (ThrottleError). Once the backoff of the account has passed the attempt claims it,
+// so parallel guesses cannot all slip through the same gap.
+func (s *AuthService) checkLoginAllowed(email string, ip string) error {
+	for _, key := range attemptKeys(email, ip) {
+		if err := s.checkAttemptKey(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkAttemptKey applies the lock and backoff of a single key, see checkLoginAllowed.
+func (s *AuthService) checkAttemptKey(key string) error {
+	isAccount := strings.HasPrefix(key, accountAttemptPrefix)
+
+	for try := 0; try < loginAttemptRetries; try++ {
+		now := time.Now()
+
+		attempt, err := s.authRepo.FindLoginAttempt(key)
+		if helpers.IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			// Tanpa catatan yang bisa dibaca lock tidak bisa diperiksa, login ditolak
+			return fmt.Errorf("failed to check login attempts: %w", err)
+		}
+
+		if attempt.IsLocked(now) {
+			wait := attempt.LockedUntil.Sub(now)
+			if isAccount {
+				return &LockedError{
+					Message:    "account is temporarily locked because of too many failed login attempts",
+					RetryAfter: wait,
+				}
+			}
+			return &ThrottleError{
+				Message:    "too many failed login attempts from this address, please try again later",
+				RetryAfter: wait,
+			}
+		}
+
+		if now.Sub(attempt.LastFailedAt) > s.lockoutConfig.FailureWindow {
+			return nil
+		}
+
+		backoff := s.lockoutConfig.Backoff(attempt.Failures)
+		if wait := attempt.LastFailedAt.Add(backoff).Sub(now); wait > 0 {
+			return &ThrottleError{
+				Message:    "too many failed login attempts, please wait before trying again",
+				RetryAfter: wait,
+			}
+		}
+
+		// Backoff IP tidak diklaim, klaim menggeser last_failed_at dan akan menahan
+		// langkah berikutnya (misalnya kode MFA) dari IP yang sama setelah login berhasil
+		if !isAccount || backoff == 0 {
+			return nil
+		}
+
+		claimed, err := s.authRepo.ClaimLoginAttempt(attempt, now.Add(-backoff), now)
+		if err != nil {
+			return fmt.Errorf("failed to claim login attempt: %w", err)
+		}
+		if claimed {
+			return nil
+		}
+		// Kalah balapan dengan request paralel, baca ulang dan periksa lagi
+	}
+
+	return &ThrottleError{
+		Message:    "too many failed login attempts, please wait before trying again",
+		RetryAfter: s.lockoutConfig.BackoffBase,
+	}
+}
+
+// recordLoginFailure counts a failed login for the account and the IP. When a key reaches its
+// limit it is locked for the lockout duration, the event is logged and the account owner is
+// notified by email. Emails without an account are counted the same way, so the lock does
+// not tell which emails are registered.
+func (s *AuthService) recordLoginFailure(ctx context.Context, email string, ip string) {
+	for _, key := range attemptKeys(email, ip) {
+		s.countLoginFailure(ctx, key, email, ip)
+	}
+}
+
+// countLoginFailure adds one failure to the key. The counter is only written when nobody
+// changed it since it was read, a lost race is retried with the fresh value.
+func (s *AuthService) countLoginFailure(ctx context.Context, key string, email string, ip string) {
+	isAccount := strings.HasPrefix(key, accountAttemptPrefix)
+	maxFailures := s.lockoutConfig.MaxIPFailures
+	if isAccount {
+		maxFailures = s.lockoutConfig.MaxAccountFailures
+	}
+
+	for try := 0; try < loginAttemptRetries; try++ {
+		now := time.Now()
+
+		attempt, err := s.authRepo.FindLoginAttempt(key)
+		if helpers.IsNotFound(err) {
+			attempt = &auth.LoginAttempt{AttemptKey: key}
+		} else if err != nil {
+			loggers.Log.Error("failed to read login attempt", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+			return
+		}
+		previousFailures := attempt.Failures
+		wasLocked := attempt.IsLocked(now)
+
+		// Lock yang sudah habis atau kegagalan lama dimulai dari nol lagi
+		expiredLock := attempt.LockedUntil != nil && !wasLocked
+		if expiredLock || now.Sub(attempt.LastFailedAt) > s.lockoutConfig.FailureWindow {
+			attempt.Failures = 0
+			attempt.LockedUntil = nil
+		}
+
+		attempt.Failures++
+		attempt.LastFailedAt = now
+
+		locking := !wasLocked && attempt.Failures >= maxFailures
+		if locking {
+			lockedUntil := now.Add(s.lockoutConfig.LockoutDuration)
+			attempt.LockedUntil = &lockedUntil
+		}
+
+		saved, err := s.authRepo.SaveLoginAttempt(attempt, previousFailures)
+		if err != nil {
+			loggers.Log.Error("failed to save login attempt", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+			return
+		}
+		if !saved {
+			continue
+		}
+
+		// Hanya request yang menyimpan lock yang mencatat dan mengirim notifikasi
+		if locking {
+			loggers.Log.Warn("Login lockout", map[string]interface{}{
+				"key":          key,
+				"failures":     attempt.Failures,
+				"locked_until": *attempt.LockedUntil,
+				"client_ip":    ip,
+			})
+
+			if isAccount {
+				s.sendLockoutNotification(ctx, email, *attempt.LockedUntil)
+			}
+		}
+		return
+	}
+
+	loggers.Log.Error("failed to save login attempt", map[string]interface{}{
+		"key":   key,
+		"error": "too many concurrent updates",
+	})
+}
+
+// clearLoginFailures forgets the failed logins of the account after a successful login
+// or a password reset, which also lifts an active lock and tells the owner about it.
+func (s *AuthService) clearLoginFailures(ctx context.Context, email string) {
+	key := accountAttemptKey(email)
+
+	attempt, err := s.authRepo.FindLoginAttempt(key)
+	if err != nil {
+		return
+	}
+
+	if err := s.authRepo.DeleteLoginAttempt(key); err != nil {
+		loggers.Log.Error("failed to clear login attempts", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if attempt.LockedUntil != nil {
+		loggers.Log.Info("Login lockout cleared", map[string]interface{}{
+			"key": key,
+		})
+	}
+	if attempt.IsLocked(time.Now()) {
+		s.sendUnlockNotification(ctx, email)
+	}
+}
+
+// ReleaseExpiredLoginLocks releases the locks whose lockout duration has passed and tells the
+// account owners that they can sign in again. It is meant to run periodically.
+func (s *AuthService) ReleaseExpiredLoginLocks(ctx context.Context) error {
+	now := time.Now()
+
+	attempts, err := s.authRepo.FindExpiredLoginLocks(now)
+	if err != nil {
+		return fmt.Errorf("failed to find expired login locks: %w", err)
+	}
+
+	for _, attempt := range attempts {
+		// Instance lain bisa melepas lock yang sama, hanya yang berhasil mengirim email
+		released, err := s.authRepo.ReleaseLoginLock(attempt.ID, now)
+		if err != nil {
+			loggers.Log.Error("failed to release login lock", map[string]interface{}{
+				"key":   attempt.AttemptKey,
+				"error": err.Error(),
+			})
+			continue
+		}
+		if !released {
+			continue
+		}
+
+		loggers.Log.Info("Login lockout ended", map[string]interface{}{
+			"key": attempt.AttemptKey,
+		})
+
+		if email, ok := strings.CutPrefix(attempt.AttemptKey, accountAttemptPrefix); ok {
+			s.sendUnlockNotification(ctx, email)
+		}
+	}
+
+	return nil
+}
+
+// sendLockoutNotification tells the account owner that the account was locked and when it
+// unlocks again. Nothing is sent when the email does not belong to a user.
+func (s *AuthService) sendLockoutNotification(ctx context.Context, email string, lockedUntil time.Time) {
+	user, err := s.authRepo.FindByEmail(email)
+	if err != nil {
+		return
+	}
+
+	err = s.mailer.Send(ctx, mailers.Message{
+		To:      user.Email,
+		Subject: "Your account has been temporarily locked",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe noticed several failed login attempts on your account, so it has been locked until %s.\n\nIt will unlock automatically at that time. If these attempts were not made by you, reset your password to unlock the account right away and keep it safe.\n",
+			user.Username, lockedUntil.Format(time.RFC1123),
+		),
+	})
+	if err != nil {
+		loggers.Log.Error("failed to send lockout notification", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// sendUnlockNotification tells the account owner that the lock was lifted and the account can
+// be used again. Nothing is sent when the email does not belong to a user.
+func (s *AuthService) sendUnlockNotification(ctx context.Context, email string) {
+	user, err := s.authRepo.FindByEmail(email)
+	if err != nil {
+		return
+	}
+
+	err = s.mailer.Send(ctx, mailers.Message{
+		To:      user.Email,
+		Subject: "Your account has been unlocked",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe temporary lock on your account has ended and you can sign in again.\n\nIf you did not cause the failed login attempts, consider changing your password.\n",
+			user.Username,
+		),
+	})
+	if err != nil {
+		loggers.Log.Error("failed to send unlock notification", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package auth_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin/src/entities/auth"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	"gin/src/repositories/auth_repositories"
+	"gin/src/utils/hashers"
+	"gin/src/utils/mailers"
+	"sync"
+	"testing"
+	"time"
+)
+
+// loginGuardTestRepo keeps the login attempts in memory, see oidcTestRepo for the embedding.
+type loginGuardTestRepo struct {
+	auth_repositories.AuthRepositoryInterface
+
+	mu       sync.Mutex
+	nextID   int64
+	users    map[string]*users.User
+	attempts map[string]*auth.LoginAttempt
+	readErr  error
+}
+
+func newLoginGuardTestRepo() *loginGuardTestRepo {
+	return &loginGuardTestRepo{users: map[string]*users.User{}, attempts: map[string]*auth.LoginAttempt{}}
+}
+
+func (r *loginGuardTestRepo) FindByEmail(email string) (*users.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[email]; ok {
+		return user, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *loginGuardTestRepo) FindLoginAttempt(key string) (*auth.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.readErr != nil {
+		return nil, r.readErr
+	}
+	attempt, ok := r.attempts[key]
+	if !ok {
+		return nil, fmt.Errorf("login attempt not found: %w", helpers.ErrNotFound)
+	}
+	copied := *attempt
+	return &copied, nil
+}
+
+func (r *loginGuardTestRepo) SaveLoginAttempt(attempt *auth.LoginAttempt, previousFailures int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.attempts[attempt.AttemptKey]
+	if attempt.ID == 0 {
+		if ok {
+			return false, nil
+		}
+		r.nextID++
+		attempt.ID = r.nextID
+	} else if !ok || stored.Failures != previousFailures {
+		return false, nil
+	}
+	copied := *attempt
+	r.attempts[attempt.AttemptKey] = &copied
+	return true, nil
+}
+
+func (r *loginGuardTestRepo) ClaimLoginAttempt(attempt *auth.LoginAttempt, notAfter time.Time, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.attempts[attempt.AttemptKey]
+	if !ok || stored.Failures != attempt.Failures || stored.LastFailedAt.After(notAfter) {
+		return false, nil
+	}
+	stored.LastFailedAt = now
+	return true, nil
+}
+
+func (r *loginGuardTestRepo) CreateLoginEvent(event *auth.LoginEvent) error {
+	return nil
+}
+
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mailers.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, message mailers.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func newLoginGuardTest(t *testing.T) (*AuthService, *loginGuardTestRepo, *recordingMailer) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "login-guard-test-secret")
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "3")
+	t.Setenv("LOGIN_BACKOFF_BASE", "1ns")
+	t.Setenv("LOGIN_BACKOFF_MAX", "1ns")
+
+	hashed, err := hashers.Default().Hash("correct-password")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	repo := newLoginGuardTestRepo()
+	repo.users["known@example.com"] = &users.User{ID: 1, Email: "known@example.com", Username: "known", Password: hashed}
+	mailer := &recordingMailer{}
+	return NewAuthService(repo, mailer), repo, mailer
+}
+
+func TestLoginLockDoesNotRevealRegisteredEmails(t *testing.T) {
+	for _, email := range []string{"known@example.com", "unknown@example.com"} {
+		t.Run(email, func(t *testing.T) {
+			service, _, mailer := newLoginGuardTest(t)
+			ctx := context.Background()
+
+			for i := 0; i < 3; i++ {
+				_, err := service.Login(ctx, email, "wrong-password")
+				var lockedErr *LockedError
+				if err == nil || errors.As(err, &lockedErr) {
+					t.Fatalf("attempt %d: expected a failed login, got %v", i+1, err)
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			_, err := service.Login(ctx, email, "wrong-password")
+			var lockedErr *LockedError
+			if !errors.As(err, &lockedErr) {
+				t.Fatalf("expected LockedError once the limit is reached, got %v", err)
+			}
+
+			// Hanya pemilik akun yang benar-benar terdaftar yang diberi tahu
+			wantMails := 0
+			if email == "known@example.com" {
+				wantMails = 1
+			}
+			if len(mailer.messages) != wantMails {
+				t.Errorf("expected %d lockout emails, got %d", wantMails, len(mailer.messages))
+			}
+		})
+	}
+}
+
+func TestLoginGuardFailsClosed(t *testing.T) {
+	service, repo, _ := newLoginGuardTest(t)
+	repo.readErr = errors.New("connection refused")
+
+	_, err := service.Login(context.Background(), "known@example.com", "correct-password")
+	if err == nil {
+		t.Fatalf("a login must be refused while the login attempts cannot be read")
+	}
+}
@@ -0,0 +1,138 @@
+package auth_services
+
+import (
+	"context"
+	"fmt"
+	"gin/src/entities/auth"
+	"gin/src/helpers"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"gin/src/utils/signers"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SendMagicLink emails a single-use, signed login link to the given address, throttled per address.
+// Like ForgotPassword it does not reveal whether the email is registered: after the throttle check
+// it returns nil at once and the lookup, the token and the email are handled in the background.
+// Failures are only written to the log.
+func (s *AuthService) SendMagicLink(ctx context.Context, email string) error {
+	if ok, wait := s.magicLinkThrottle.Allow(strings.ToLower(email)); !ok {
+		return &ThrottleError{
+			Message:    "a login link was sent recently, please try again later",
+			RetryAfter: wait,
+		}
+	}
+
+	// Context request tidak dipakai untuk membatalkan, email tetap dikirim setelah response
+	go s.sendMagicLink(context.WithoutCancel(ctx), email)
+	return nil
+}
+
+func (s *AuthService) sendMagicLink(ctx context.Context, email string) {
+	user, err := s.authRepo.FindByEmail(email)
+	if err != nil {
+		return
+	}
+
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		logMagicLinkError("failed to generate magic link token", user.ID, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(s.magicLinkConfig.TokenTTL)
+	magicLink := auth.MagicLinkToken{
+		UserID:    user.ID,
+		TokenHash: helpers.HashToken(token),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.authRepo.CreateMagicLinkToken(&magicLink); err != nil {
+		logMagicLinkError("failed to save magic link token", user.ID, err)
+		return
+	}
+
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{}
+	query.Set("token", token)
+	query.Set("expires", expires)
+	query.Set("signature", signers.Sign("magic-link", token, expires))
+	link := s.magicLinkConfig.LoginURL + "?" + query.Encode()
+
+	err = s.mailer.Send(ctx, mailers.Message{
+		To:      user.Email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below to log in:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not request this, you can ignore this email.\n",
+			user.Username, link, s.magicLinkConfig.TokenTTL,
+		),
+	})
+	if err != nil {
+		logMagicLinkError("failed to send magic link email", user.ID, err)
+	}
+}
+
+// MagicLinkLogin exchanges the parameters of a login link for the usual token pair. The link is
+// single-use, and since it proves ownership of the email the address is marked verified.
+// Users with two-factor authentication continue with VerifyMFALogin.
+func (s *AuthService) MagicLinkLogin(ctx context.Context, token string, expires string, signature string, opts ...TokenOptions) (gin.H, error) {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return nil, fmt.Errorf("invalid or expired login link")
+	}
+	if !signers.Verify(signature, "magic-link", token, expires) {
+		return nil, fmt.Errorf("invalid or expired login link")
+	}
+
+	magicLink, err := s.authRepo.FindMagicLinkToken(helpers.HashToken(token))
+	if err != nil || time.Now().After(magicLink.ExpiresAt) {
+		return nil, fmt.Errorf("invalid or expired login link")
+	}
+
+	// Tandai token terpakai lebih dulu, dari request paralel hanya satu yang berhasil
+	marked, err := s.authRepo.MarkMagicLinkTokenAsUsed(magicLink.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use login link: %w", err)
+	}
+	if !marked {
+		return nil, fmt.Errorf("invalid or expired login link")
+	}
+
+	user, err := s.authRepo.FindByID(magicLink.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired login link")
+	}
+
+	if !user.IsEmailVerified() {
+		if err := s.authRepo.MarkEmailAsVerified(user.ID); err != nil {
+			return nil, fmt.Errorf("failed to verify email: %w", err)
+		}
+	}
+
+	var opt TokenOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if twoFactor, err := s.authRepo.FindTwoFactorByUserID(user.ID); err == nil && twoFactor.IsEnabled() {
+		return s.mfaChallengeResponse(user.ID, opt)
+	}
+
+	opt.LoginMethod = auth.LoginMethodMagicLink
+	tokens, err := s.GenerateTokens(user.ID, opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed generate tokens: %w", err)
+	}
+	return tokenResponse(tokens), nil
+}
+
+func logMagicLinkError(message string, userID int64, err error) {
+	loggers.Log.Error(message, map[string]interface{}{
+		"user_id": userID,
+		"error":   err.Error(),
+	})
+}
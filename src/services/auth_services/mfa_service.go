@@ -0,0 +1,270 @@
+package auth_services
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"gin/src/entities/auth"
+	"gin/src/helpers"
+	"gin/src/utils/crypts"
+	"gin/src/utils/hashers"
+	"gin/src/utils/totp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/skip2/go-qrcode"
+)
+
+// EnrollMFA starts the TOTP enrolment of the user. It returns the secret, the otpauth URI
+// and a QR code PNG of the URI. The enrolment stays inactive until it is confirmed with
+// ConfirmMFA, starting again replaces an unconfirmed secret.
+func (s *AuthService) EnrollMFA(ctx context.Context, userID int64) (*auth.MFAEnrollment, error) {
+	user, err := s.authRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, err := s.authRepo.FindTwoFactorByUserID(userID); err == nil && existing.IsEnabled() {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	encryptedSecret, err := crypts.Encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+
+	if err := s.authRepo.SaveTwoFactor(&auth.TwoFactor{UserID: userID, Secret: encryptedSecret}); err != nil {
+		return nil, fmt.Errorf("failed to save two-factor enrolment: %w", err)
+	}
+
+	uri := totp.URI(s.mfaConfig.Issuer, user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate qr code: %w", err)
+	}
+
+	return &auth.MFAEnrollment{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// ConfirmMFA activates the pending enrolment when the code from the authenticator app is valid.
+// It returns the one-time recovery codes, they are stored hashed and shown only this once.
+func (s *AuthService) ConfirmMFA(ctx context.Context, userID int64, code string) ([]string, error) {
+	twoFactor, err := s.authRepo.FindTwoFactorByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("two-factor enrolment not started")
+	}
+	if twoFactor.IsEnabled() {
+		return nil, fmt.Errorf("two-factor authentication is already enabled")
+	}
+
+	secret, err := crypts.Decrypt(twoFactor.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret: %w", err)
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("invalid authentication code")
+	}
+
+	if err := s.authRepo.ConfirmTwoFactor(twoFactor.ID, step); err != nil {
+		return nil, fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+
+	return s.regenerateRecoveryCodes(userID)
+}
+
+// DisableMFA turns off two-factor authentication after checking the password and a current
+// authentication or recovery code.
+func (s *AuthService) DisableMFA(ctx context.Context, userID int64, password string, code string) error {
+	user, err := s.authRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if ok, err := hashers.Default().Verify(password, user.Password); err != nil || !ok {
+		return fmt.Errorf("invalid password")
+	}
+
+	twoFactor, err := s.authRepo.FindTwoFactorByUserID(userID)
+	if err != nil || !twoFactor.IsEnabled() {
+		return fmt.Errorf("two-factor authentication is not enabled")
+	}
+
+	if err := s.verifyMFACode(twoFactor, code); err != nil {
+		return err
+	}
+
+	if err := s.authRepo.DeleteTwoFactor(userID); err != nil {
+		return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+	}
+	return nil
+}
+
+// VerifyMFALogin is the second step of the login for users with two-factor authentication.
+// It exchanges the challenge token from Login plus an authentication or recovery code for
+// the real token pair.
+func (s *AuthService) VerifyMFALogin(ctx context.Context, mfaToken string, code string, opts ...TokenOptions) (gin.H, error) {
+	userID, challengeOpts, err := s.parseMFAChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.authRepo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid or expired mfa token")
+	}
+
+	twoFactor, err := s.authRepo.FindTwoFactorByUserID(userID)
+	if err != nil || !twoFactor.IsEnabled() {
+		return nil, fmt.Errorf("invalid or expired mfa token")
+	}
+
+	// Kode authenticator dihitung sebagai percobaan login, sama seperti password
+	var ip string
+	if len(opts) > 0 {
+		ip = opts[0].IPAddress
+	}
+	if err := s.checkLoginAllowed(user.Email, ip); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyMFACode(twoFactor, code); err != nil {
+		s.recordLoginFailure(ctx, user.Email, ip)
+		s.recordLoginEvent(user.ID, auth.LoginMethodMFA, false, "invalid code", opts...)
+		return nil, err
+	}
+	s.clearLoginFailures(ctx, user.Email)
+
+	// Client type dan remember me berasal dari langkah password, UA dan IP dari request ini
+	if len(opts) > 0 {
+		challengeOpts.UserAgent = opts[0].UserAgent
+		challengeOpts.IPAddress = opts[0].IPAddress
+	}
+
+	challengeOpts.LoginMethod = auth.LoginMethodMFA
+	tokens, err := s.GenerateTokens(userID, challengeOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed generate tokens: %w", err)
+	}
+	return tokenResponse(tokens), nil
+}
+
+// mfaChallengeResponse is returned by the password step of the login for enrolled users.
+func (s *AuthService) mfaChallengeResponse(userID int64, opts TokenOptions) (gin.H, error) {
+	expiresAt := time.Now().Add(s.mfaConfig.ChallengeTTL)
+	claims := jwt.MapClaims{
+		"mfa_user_id": userID,
+		"client_type": opts.ClientType,
+		"remember_me": opts.RememberMe,
+		"exp":         expiresAt.Unix(),
+		"jti":         helpers.GenerateUUID(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(getJWTSecret()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mfa token: %w", err)
+	}
+
+	return gin.H{
+		"mfa_required":   true,
+		"mfa_token":      token,
+		"mfa_expires_at": expiresAt,
+	}, nil
+}
+
+// parseMFAChallenge validates a challenge token and returns the user and the token options of
+// the password step. The claim is named mfa_user_id so a challenge token can never be used as
+// an access or refresh token.
+func (s *AuthService) parseMFAChallenge(mfaToken string) (int64, TokenOptions, error) {
+	parsed, err := jwt.Parse(mfaToken, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method")
+		}
+		return []byte(getJWTSecret()), nil
+	})
+	if err != nil || !parsed.Valid {
+		return 0, TokenOptions{}, fmt.Errorf("invalid or expired mfa token")
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, TokenOptions{}, fmt.Errorf("invalid or expired mfa token")
+	}
+
+	userID, ok := claims["mfa_user_id"].(float64)
+	if !ok {
+		return 0, TokenOptions{}, fmt.Errorf("invalid or expired mfa token")
+	}
+
+	clientType, _ := claims["client_type"].(string)
+	rememberMe, _ := claims["remember_me"].(bool)
+	return int64(userID), TokenOptions{ClientType: clientType, RememberMe: rememberMe}, nil
+}
+
+// verifyMFACode accepts either a TOTP code or an unused recovery code. A TOTP code is rejected
+// if its time step was already used, so a captured code cannot be replayed.
+func (s *AuthService) verifyMFACode(twoFactor *auth.TwoFactor, code string) error {
+	secret, err := crypts.Decrypt(twoFactor.Secret)
+	if err != nil {
+		return fmt.Errorf("failed to read secret: %w", err)
+	}
+
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		if step <= twoFactor.LastUsedStep {
+			return fmt.Errorf("authentication code already used")
+		}
+		// Step disimpan dengan update bersyarat, request paralel dengan kode yang sama kalah
+		saved, err := s.authRepo.UpdateTwoFactorLastUsedStep(twoFactor.ID, step)
+		if err != nil {
+			return fmt.Errorf("failed to save authentication code: %w", err)
+		}
+		if !saved {
+			return fmt.Errorf("authentication code already used")
+		}
+		return nil
+	}
+
+	if err := s.authRepo.UseRecoveryCode(twoFactor.UserID, helpers.HashToken(normalizeRecoveryCode(code))); err != nil {
+		return fmt.Errorf("invalid authentication code")
+	}
+	return nil
+}
+
+// regenerateRecoveryCodes replaces the recovery codes of the user and returns the new plain codes.
+func (s *AuthService) regenerateRecoveryCodes(userID int64) ([]string, error) {
+	codes := make([]string, 0, s.mfaConfig.RecoveryCodeCount)
+	hashes := make([]string, 0, s.mfaConfig.RecoveryCodeCount)
+
+	for i := 0; i < s.mfaConfig.RecoveryCodeCount; i++ {
+		raw, err := helpers.GenerateRandomToken(5)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		hashes = append(hashes, helpers.HashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := s.authRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode lets users type recovery codes without the dash or in upper case.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
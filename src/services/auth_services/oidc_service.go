@@ -0,0 +1,265 @@
+package auth_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin/src/entities/auth"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	"gin/src/utils/hashers"
+	"gin/src/utils/oidc"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	ErrUnknownOIDCProvider = errors.New("unknown login provider")
+	ErrOIDCEmailRequired   = errors.New("the login provider did not return a verified email address")
+	ErrOIDCAccountConflict = errors.New("an account with this email already exists but its email is not verified, sign in with your password and verify it first")
+)
+
+// SetOIDCProvider registers or replaces an external login provider, for example a fake provider in tests.
+func (s *AuthService) SetOIDCProvider(provider *oidc.Provider) {
+	s.oidcProviders[provider.Name] = provider
+}
+
+// OIDCProviders returns the names of the configured external login providers.
+func (s *AuthService) OIDCProviders() []string {
+	names := make([]string, 0, len(s.oidcProviders))
+	for name := range s.oidcProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartOIDCLogin starts the authorization code flow with PKCE at the provider. It returns the
+// authorization URL the client should open and the state it will get back on the redirect.
+func (s *AuthService) StartOIDCLogin(ctx context.Context, providerName string) (gin.H, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	state, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := helpers.GenerateRandomToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier, err := oidc.GenerateVerifier()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+
+	authorizationURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.Challenge(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authRepo.CreateOIDCState(&auth.OIDCState{
+		StateHash:    helpers.HashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.oidcConfig.StateTTL),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save login state: %w", err)
+	}
+
+	return gin.H{
+		"authorization_url": authorizationURL,
+		"state":             state,
+	}, nil
+}
+
+// OIDCLogin finishes the login at the provider. The code is exchanged with the stored PKCE verifier,
+// the ID token is validated against the provider JWKS and the user is found by the linked identity,
+// linked by verified email, or created. Users with two-factor authentication continue with
+// VerifyMFALogin, everyone else gets the normal token pair.
+func (s *AuthService) OIDCLogin(ctx context.Context, providerName string, code string, state string, opts ...TokenOptions) (gin.H, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	loginState, err := s.authRepo.FindOIDCState(helpers.HashToken(state))
+	if err != nil || loginState.Provider != providerName {
+		return nil, fmt.Errorf("invalid or expired login state")
+	}
+	if time.Now().After(loginState.ExpiresAt) {
+		return nil, fmt.Errorf("invalid or expired login state")
+	}
+	if err := s.authRepo.MarkOIDCStateAsUsed(loginState.ID); err != nil {
+		return nil, fmt.Errorf("failed to mark login state as used: %w", err)
+	}
+
+	tokens, err := provider.Exchange(ctx, code, loginState.CodeVerifier)
+	if err != nil {
+		return nil, fmt.Errorf("login at %s failed: %w", providerName, err)
+	}
+
+	claims, err := provider.VerifyIDToken(ctx, tokens.IDToken, loginState.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("login at %s failed: %w", providerName, err)
+	}
+
+	user, err := s.findOrCreateOIDCUser(providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	var opt TokenOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if twoFactor, err := s.authRepo.FindTwoFactorByUserID(user.ID); err == nil && twoFactor.IsEnabled() {
+		return s.mfaChallengeResponse(user.ID, opt)
+	}
+
+	opt.LoginMethod = auth.LoginMethodOIDC
+	result, err := s.GenerateTokens(user.ID, opt)
+	if err != nil {
+		return nil, fmt.Errorf("failed generate tokens: %w", err)
+	}
+	return tokenResponse(result), nil
+}
+
+// findOrCreateOIDCUser returns the user linked to the provider account. Unlinked accounts are linked
+// to the user with the same verified email, or a new user is created with the email marked verified.
+func (s *AuthService) findOrCreateOIDCUser(providerName string, claims *oidc.Claims) (*users.User, error) {
+	if identity, err := s.authRepo.FindExternalIdentity(providerName, claims.Subject); err == nil {
+		return s.authRepo.FindByID(identity.UserID)
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailRequired
+	}
+
+	user, err := s.authRepo.FindByEmail(claims.Email)
+	if err == nil {
+		// Akun lokal yang emailnya belum terverifikasi tidak ditautkan otomatis,
+		// supaya akun yang didaftarkan orang lain dengan email korban tidak ikut diambil alih
+		if !user.IsEmailVerified() {
+			return nil, ErrOIDCAccountConflict
+		}
+	} else {
+		user, err = s.createOIDCUser(claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.authRepo.CreateExternalIdentity(&auth.ExternalIdentity{
+		UserID:   user.ID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return user, nil
+}
+
+// createOIDCUser creates a user for a new provider account. The password is random, the user can set
+// one later with the forgot password flow.
+func (s *AuthService) createOIDCUser(claims *oidc.Claims) (*users.User, error) {
+	randomPassword, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+	hashedPassword, err := hashers.Default().Hash(randomPassword)
+	if err != nil {
+		return nil, fmt.Errorf("could not hash password: %w", err)
+	}
+
+	username, err := s.availableUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	user := &users.User{
+		Email:           claims.Email,
+		Username:        username,
+		Password:        string(hashedPassword),
+		EmailVerifiedAt: &now,
+	}
+	if err := s.authRepo.CreateUser(user); err != nil {
+		return nil, fmt.Errorf("could not insert user: %w", err)
+	}
+	return user, nil
+}
+
+// availableUsername derives a username from the preferred username or the email address,
+// adding a random suffix when it is already taken.
+func (s *AuthService) availableUsername(claims *oidc.Claims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+
+	var cleaned strings.Builder
+	for _, r := range strings.ToLower(base) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '.' || r == '_' || r == '-' {
+			cleaned.WriteRune(r)
+		}
+	}
+	base = cleaned.String()
+	if len(base) < 3 {
+		base = "user" + base
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		if _, err := s.authRepo.FindByUsername(candidate); err != nil {
+			return candidate, nil
+		}
+		suffix, err := helpers.GenerateRandomToken(3)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate username: %w", err)
+		}
+		candidate = base + "-" + suffix
+	}
+	return "", fmt.Errorf("could not find an available username")
+}
+
+// ListExternalIdentities returns the provider accounts linked to the user.
+func (s *AuthService) ListExternalIdentities(ctx context.Context, userID int64) ([]auth.ExternalIdentityResponse, error) {
+	identities, err := s.authRepo.FindExternalIdentitiesByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list identities: %w", err)
+	}
+
+	response := make([]auth.ExternalIdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		response = append(response, auth.ExternalIdentityResponse{
+			UUID:      identity.UUID,
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// UnlinkExternalIdentity removes a provider account from the user.
+func (s *AuthService) UnlinkExternalIdentity(ctx context.Context, userID int64, identityUUID string) error {
+	identity, err := s.authRepo.FindExternalIdentityByUUID(userID, identityUUID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authRepo.DeleteExternalIdentity(identity.ID); err != nil {
+		return fmt.Errorf("failed to unlink identity: %w", err)
+	}
+	return nil
+}
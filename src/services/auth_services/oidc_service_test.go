@@ -0,0 +1,360 @@
+package auth_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin/src/entities/auth"
+	"gin/src/entities/users"
+	"gin/src/internal/testutil"
+	"gin/src/repositories/auth_repositories"
+	"gin/src/utils/oidc"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oidcTestRepo keeps the records touched by the OIDC login flow in memory. Methods the flow
+// does not use fall through to the nil embedded interface and panic, which flags new calls.
+type oidcTestRepo struct {
+	auth_repositories.AuthRepositoryInterface
+
+	mu         sync.Mutex
+	nextID     int64
+	users      map[int64]*users.User
+	states     map[string]*auth.OIDCState
+	identities []auth.ExternalIdentity
+	sessions   int
+}
+
+func newOIDCTestRepo() *oidcTestRepo {
+	return &oidcTestRepo{
+		users:  map[int64]*users.User{},
+		states: map[string]*auth.OIDCState{},
+	}
+}
+
+func (r *oidcTestRepo) id() int64 {
+	r.nextID++
+	return r.nextID
+}
+
+func (r *oidcTestRepo) addUser(email string, verified bool) *users.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user := &users.User{ID: r.id(), Email: email, Username: strings.SplitN(email, "@", 2)[0]}
+	if verified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	r.users[user.ID] = user
+	return user
+}
+
+func (r *oidcTestRepo) FindByID(id int64) (*users.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if user, ok := r.users[id]; ok {
+		return user, nil
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *oidcTestRepo) FindByEmail(email string) (*users.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *oidcTestRepo) FindByUsername(username string) (*users.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, fmt.Errorf("user not found")
+}
+
+func (r *oidcTestRepo) CreateUser(user *users.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user.ID = r.id()
+	r.users[user.ID] = user
+	return nil
+}
+
+func (r *oidcTestRepo) CreateOIDCState(state *auth.OIDCState) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state.ID = r.id()
+	r.states[state.StateHash] = state
+	return nil
+}
+
+func (r *oidcTestRepo) FindOIDCState(stateHash string) (*auth.OIDCState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if state, ok := r.states[stateHash]; ok && !state.Used {
+		return state, nil
+	}
+	return nil, fmt.Errorf("login state not found")
+}
+
+func (r *oidcTestRepo) MarkOIDCStateAsUsed(id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, state := range r.states {
+		if state.ID == id && !state.Used {
+			state.Used = true
+			return nil
+		}
+	}
+	return fmt.Errorf("login state already used")
+}
+
+func (r *oidcTestRepo) FindExternalIdentity(provider string, subject string) (*auth.ExternalIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return &identity, nil
+		}
+	}
+	return nil, fmt.Errorf("identity not found")
+}
+
+func (r *oidcTestRepo) CreateExternalIdentity(identity *auth.ExternalIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	identity.ID = r.id()
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *oidcTestRepo) FindTwoFactorByUserID(userID int64) (*auth.TwoFactor, error) {
+	return nil, fmt.Errorf("two factor not found")
+}
+
+func (r *oidcTestRepo) CreateSession(session *auth.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session.ID = r.id()
+	r.sessions++
+	return nil
+}
+
+func (r *oidcTestRepo) SaveTokens(access *auth.AccessToken, refresh *auth.RefreshToken) error {
+	return nil
+}
+
+func (r *oidcTestRepo) CreateLoginEvent(event *auth.LoginEvent) error {
+	return nil
+}
+
+// oidcTest wires an AuthService to a FakeOIDCProvider served over HTTP.
+type oidcTest struct {
+	service  *AuthService
+	repo     *oidcTestRepo
+	provider *testutil.FakeOIDCProvider
+}
+
+func newOIDCTest(t *testing.T, user testutil.FakeOIDCUser) *oidcTest {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "oidc-test-secret")
+
+	provider, err := testutil.NewFakeOIDCProvider("test-client", "test-secret", user)
+	if err != nil {
+		t.Fatalf("failed to create fake provider: %v", err)
+	}
+	server := httptest.NewServer(provider)
+	t.Cleanup(server.Close)
+	provider.Issuer = server.URL
+
+	repo := newOIDCTestRepo()
+	service := NewAuthService(repo, nil)
+	service.SetOIDCProvider(oidc.NewProvider("fake", server.URL, "test-client", "test-secret", "http://localhost/callback", nil))
+
+	return &oidcTest{service: service, repo: repo, provider: provider}
+}
+
+// login runs the whole flow: start, approval at the provider and the callback.
+func (o *oidcTest) login(t *testing.T) (map[string]interface{}, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	started, err := o.service.StartOIDCLogin(ctx, "fake")
+	if err != nil {
+		t.Fatalf("StartOIDCLogin failed: %v", err)
+	}
+
+	location, err := o.provider.Authorize(started["authorization_url"].(string))
+	if err != nil {
+		t.Fatalf("provider refused the authorization request: %v", err)
+	}
+	redirect, err := url.Parse(location)
+	if err != nil {
+		t.Fatalf("invalid redirect %q: %v", location, err)
+	}
+	if redirect.Query().Get("state") != started["state"] {
+		t.Fatalf("state was not passed through the provider")
+	}
+
+	return o.service.OIDCLogin(ctx, "fake", redirect.Query().Get("code"), redirect.Query().Get("state"))
+}
+
+func TestOIDCLoginCreatesUser(t *testing.T) {
+	test := newOIDCTest(t, testutil.FakeOIDCUser{Subject: "sub-1", Email: "new@example.com", EmailVerified: true})
+
+	result, err := test.login(t)
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	if result["access_token"] == "" {
+		t.Fatalf("expected an access token, got %v", result)
+	}
+
+	user, err := test.repo.FindByEmail("new@example.com")
+	if err != nil {
+		t.Fatalf("user was not created: %v", err)
+	}
+	if !user.IsEmailVerified() {
+		t.Errorf("user created from a verified provider email should be verified")
+	}
+	if identity, err := test.repo.FindExternalIdentity("fake", "sub-1"); err != nil || identity.UserID != user.ID {
+		t.Errorf("identity was not linked to the new user: %v", err)
+	}
+}
+
+func TestOIDCLoginRejectsInvalidIDToken(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(claims jwt.MapClaims)
+		want   string
+	}{
+		{
+			name:   "nonce mismatch",
+			tamper: func(claims jwt.MapClaims) { claims["nonce"] = "another-nonce" },
+			want:   "nonce mismatch",
+		},
+		{
+			name:   "audience of another client",
+			tamper: func(claims jwt.MapClaims) { claims["aud"] = "another-client" },
+			want:   "invalid id token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := newOIDCTest(t, testutil.FakeOIDCUser{Subject: "sub-1", Email: "new@example.com", EmailVerified: true})
+			test.provider.TamperClaims = tt.tamper
+
+			_, err := test.login(t)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if len(test.repo.users) != 0 || len(test.repo.identities) != 0 || test.repo.sessions != 0 {
+				t.Errorf("a rejected ID token must not create users, identities or sessions")
+			}
+		})
+	}
+}
+
+func TestOIDCLoginLinksVerifiedEmail(t *testing.T) {
+	test := newOIDCTest(t, testutil.FakeOIDCUser{Subject: "sub-1", Email: "known@example.com", EmailVerified: true})
+	existing := test.repo.addUser("known@example.com", true)
+
+	if _, err := test.login(t); err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	identity, err := test.repo.FindExternalIdentity("fake", "sub-1")
+	if err != nil || identity.UserID != existing.ID {
+		t.Fatalf("identity should be linked to the existing user %d, got %+v (%v)", existing.ID, identity, err)
+	}
+	if len(test.repo.users) != 1 {
+		t.Errorf("no new user should be created, got %d users", len(test.repo.users))
+	}
+
+	// Login berikutnya memakai identity yang sudah tertaut
+	if _, err := test.login(t); err != nil {
+		t.Fatalf("second login failed: %v", err)
+	}
+	if len(test.repo.identities) != 1 {
+		t.Errorf("the identity should be linked once, got %d", len(test.repo.identities))
+	}
+}
+
+func TestOIDCLoginRefusesUnverifiedLocalAccount(t *testing.T) {
+	test := newOIDCTest(t, testutil.FakeOIDCUser{Subject: "sub-1", Email: "victim@example.com", EmailVerified: true})
+	test.repo.addUser("victim@example.com", false)
+
+	_, err := test.login(t)
+	if !errors.Is(err, ErrOIDCAccountConflict) {
+		t.Fatalf("expected ErrOIDCAccountConflict, got %v", err)
+	}
+	if len(test.repo.identities) != 0 || test.repo.sessions != 0 {
+		t.Errorf("an unverified local account must not be linked or signed in")
+	}
+}
+
+func TestOIDCLoginRequiresVerifiedProviderEmail(t *testing.T) {
+	test := newOIDCTest(t, testutil.FakeOIDCUser{Subject: "sub-1", Email: "known@example.com", EmailVerified: false})
+	test.repo.addUser("known@example.com", true)
+
+	_, err := test.login(t)
+	if !errors.Is(err, ErrOIDCEmailRequired) {
+		t.Fatalf("expected ErrOIDCEmailRequired, got %v", err)
+	}
+	if len(test.repo.identities) != 0 {
+		t.Errorf("an unverified provider email must not be linked")
+	}
+}
+
+func TestOIDCLoginRefusesReplayedState(t *testing.T) {
+	test := newOIDCTest(t, testutil.FakeOIDCUser{Subject: "sub-1", Email: "new@example.com", EmailVerified: true})
+	ctx := context.Background()
+
+	started, err := test.service.StartOIDCLogin(ctx, "fake")
+	if err != nil {
+		t.Fatalf("StartOIDCLogin failed: %v", err)
+	}
+	location, err := test.provider.Authorize(started["authorization_url"].(string))
+	if err != nil {
+		t.Fatalf("provider refused the authorization request: %v", err)
+	}
+	redirect, _ := url.Parse(location)
+	code, state := redirect.Query().Get("code"), redirect.Query().Get("state")
+
+	if _, err := test.service.OIDCLogin(ctx, "fake", code, state); err != nil {
+		t.Fatalf("first callback failed: %v", err)
+	}
+	if _, err := test.service.OIDCLogin(ctx, "fake", code, state); err == nil {
+		t.Fatalf("a replayed callback must be refused")
+	}
+	if test.repo.sessions != 1 {
+		t.Errorf("expected a single session, got %d", test.repo.sessions)
+	}
+}
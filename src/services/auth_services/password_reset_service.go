@@ -0,0 +1,118 @@
+package auth_services
+
+import (
+	"context"
+	"fmt"
+	"gin/src/entities/auth"
+	"gin/src/helpers"
+	"gin/src/utils/hashers"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"gin/src/utils/passwords"
+	"net/url"
+	"time"
+)
+
+// ForgotPassword sends a single-use password reset link to the given email.
+// To avoid revealing which emails are registered, it always returns nil at once: the lookup,
+// the token and the email are handled in the background, so registered and unknown addresses
+// take the same time. Failures are only written to the log.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	// Context request tidak dipakai untuk membatalkan, email tetap dikirim setelah response
+	go s.sendPasswordReset(context.WithoutCancel(ctx), email)
+	return nil
+}
+
+func (s *AuthService) sendPasswordReset(ctx context.Context, email string) {
+	user, err := s.authRepo.FindByEmail(email)
+	if err != nil {
+		return
+	}
+
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		logPasswordResetError("failed to generate reset token", user.ID, err)
+		return
+	}
+
+	resetToken := auth.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: helpers.HashToken(token),
+		ExpiresAt: time.Now().Add(s.passwordResetConfig.TokenTTL),
+	}
+	if err := s.authRepo.CreatePasswordResetToken(&resetToken); err != nil {
+		logPasswordResetError("failed to save reset token", user.ID, err)
+		return
+	}
+
+	link := s.passwordResetConfig.ResetURL + "?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(ctx, mailers.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not request this, you can ignore this email.\n",
+			user.Username, link, s.passwordResetConfig.TokenTTL,
+		),
+	})
+	if err != nil {
+		logPasswordResetError("failed to send reset email", user.ID, err)
+	}
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword. The token is
+// single-use and expires after the configured TTL. After a successful reset every
+// session of the user is revoked.
+func (s *AuthService) ResetPassword(ctx context.Context, token string, newPassword string) error {
+	resetToken, err := s.authRepo.FindPasswordResetToken(helpers.HashToken(token))
+	if err != nil {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+	if time.Now().After(resetToken.ExpiresAt) {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+
+	user, err := s.authRepo.FindByID(resetToken.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+
+	// Cek policy sebelum token dipakai supaya user bisa mencoba password lain
+	if err := passwords.Default().ValidateNew(s.authRepo, user, newPassword); err != nil {
+		return err
+	}
+
+	// Tandai token terpakai lebih dulu, hanya satu request yang berhasil menandai
+	marked, err := s.authRepo.MarkPasswordResetTokenAsUsed(resetToken.ID)
+	if err != nil {
+		return fmt.Errorf("failed to use reset token: %w", err)
+	}
+	if !marked {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+
+	hashedPassword, err := hashers.Default().Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("could not hash password: %w", err)
+	}
+
+	if err := s.authRepo.UpdatePassword(resetToken.UserID, hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	passwords.Default().Remember(s.authRepo, user.ID, hashedPassword)
+
+	if err := s.authRepo.RevokeAllUserTokens(resetToken.UserID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	// Reset password juga membuka akun yang terkunci karena login gagal
+	s.clearLoginFailures(ctx, user.Email)
+
+	return nil
+}
+
+func logPasswordResetError(message string, userID int64, err error) {
+	loggers.Log.Error(message, map[string]interface{}{
+		"user_id": userID,
+		"error":   err.Error(),
+	})
+}
@@ -0,0 +1,124 @@
+package auth_services
+
+import (
+	"context"
+	"fmt"
+	"gin/src/entities/auth"
+	"gin/src/helpers"
+	"sort"
+	"strings"
+	"time"
+)
+
+// personalAccessTokenDisplayLength is how many characters of the token are kept to recognise it in the list.
+const personalAccessTokenDisplayLength = 12
+
+// CreatePersonalAccessToken creates a long-lived token for machine clients. Only its hash is stored,
+// so the plain token is returned once in the response and cannot be shown again.
+// A zero expiresIn creates a token that never expires. grantedScopes are the scopes of the token
+// making the request, every requested scope must be covered by them (ErrScopeNotGranted), so a
+// scoped token cannot mint a broader one. Session tokens pass []string{auth.ScopeAll}.
+func (s *AuthService) CreatePersonalAccessToken(ctx context.Context, userID int64, name string, scopes []string, grantedScopes []string, expiresIn time.Duration) (*auth.PersonalAccessTokenResponse, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("token name is required")
+	}
+
+	scopes = uniqueScopes(scopes)
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !auth.IsKnownScope(scope) {
+			return nil, fmt.Errorf("unknown scope %q, allowed scopes: %s", scope, strings.Join(auth.KnownScopes, ", "))
+		}
+		// Scope harus tercakup token pemanggil, "*" hanya bisa diberikan oleh token "*"
+		if !auth.ScopesAllow(grantedScopes, scope) {
+			return nil, fmt.Errorf("%w: %s", ErrScopeNotGranted, scope)
+		}
+	}
+
+	secret, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate personal access token: %w", err)
+	}
+	plainToken := auth.PersonalAccessTokenPrefix + secret
+
+	token := &auth.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: helpers.HashToken(plainToken),
+		Prefix:    plainToken[:personalAccessTokenDisplayLength],
+		Scopes:    strings.Join(scopes, ","),
+	}
+	if expiresIn > 0 {
+		expiresAt := time.Now().Add(expiresIn)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.authRepo.CreatePersonalAccessToken(token); err != nil {
+		return nil, fmt.Errorf("failed to create personal access token: %w", err)
+	}
+
+	response := personalAccessTokenResponse(token)
+	response.Token = plainToken
+	return &response, nil
+}
+
+// ListPersonalAccessTokens returns the active personal access tokens of the user, newest first.
+func (s *AuthService) ListPersonalAccessTokens(ctx context.Context, userID int64) ([]auth.PersonalAccessTokenResponse, error) {
+	tokens, err := s.authRepo.FindPersonalAccessTokensByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list personal access tokens: %w", err)
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+
+	response := make([]auth.PersonalAccessTokenResponse, 0, len(tokens))
+	for i := range tokens {
+		response = append(response, personalAccessTokenResponse(&tokens[i]))
+	}
+	return response, nil
+}
+
+// RevokePersonalAccessToken revokes a personal access token of the user.
+func (s *AuthService) RevokePersonalAccessToken(ctx context.Context, userID int64, tokenUUID string) error {
+	token, err := s.authRepo.FindPersonalAccessTokenByUUID(userID, tokenUUID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authRepo.RevokePersonalAccessToken(token.ID); err != nil {
+		return fmt.Errorf("failed to revoke personal access token: %w", err)
+	}
+	return nil
+}
+
+func personalAccessTokenResponse(token *auth.PersonalAccessToken) auth.PersonalAccessTokenResponse {
+	return auth.PersonalAccessTokenResponse{
+		UUID:       token.UUID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.ScopeList(),
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+// uniqueScopes trims the scopes and drops empty and duplicate entries, keeping their order.
+func uniqueScopes(scopes []string) []string {
+	seen := make(map[string]bool, len(scopes))
+	result := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		result = append(result, scope)
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package oauth_services
+
+import "net/http"
+
+// OAuthError is an error response of the OAuth2 endpoints (RFC 6749 section 5.2). Code is the
+// "error" value sent to the client and Status the HTTP status of the response.
+type OAuthError struct {
+	Code        string
+	Description string
+	Status      int
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func invalidRequest(description string) *OAuthError {
+	return &OAuthError{Code: "invalid_request", Description: description, Status: http.StatusBadRequest}
+}
+
+func invalidClient(description string) *OAuthError {
+	return &OAuthError{Code: "invalid_client", Description: description, Status: http.StatusUnauthorized}
+}
+
+func invalidGrant(description string) *OAuthError {
+	return &OAuthError{Code: "invalid_grant", Description: description, Status: http.StatusBadRequest}
+}
+
+func invalidScope(description string) *OAuthError {
+	return &OAuthError{Code: "invalid_scope", Description: description, Status: http.StatusBadRequest}
+}
+
+func unauthorizedClient(description string) *OAuthError {
+	return &OAuthError{Code: "unauthorized_client", Description: description, Status: http.StatusBadRequest}
+}
+
+func unsupportedGrantType(description string) *OAuthError {
+	return &OAuthError{Code: "unsupported_grant_type", Description: description, Status: http.StatusBadRequest}
+}
+
+func unsupportedResponseType(description string) *OAuthError {
+	return &OAuthError{Code: "unsupported_response_type", Description: description, Status: http.StatusBadRequest}
+}
@@ -0,0 +1,612 @@
+package oauth_services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"gin/src/configs/security"
+	"gin/src/entities/auth"
+	"gin/src/entities/oauth"
+	"gin/src/helpers"
+	"gin/src/repositories/auth_repositories"
+	"gin/src/repositories/oauth_repositories"
+	"gin/src/services/auth_services"
+	"gin/src/utils/loggers"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type OAuthServiceInterface interface {
+	RegisterClient(ctx context.Context, userID int64, registration ClientRegistration) (*oauth.ClientResponse, error)
+	ListClients(ctx context.Context, userID int64) ([]oauth.ClientResponse, error)
+	RevokeClient(ctx context.Context, userID int64, clientUUID string) error
+	ValidateAuthorization(ctx context.Context, req AuthorizationRequest) (*oauth.AuthorizationPrompt, error)
+	Authorize(ctx context.Context, userID int64, req AuthorizationRequest) (string, error)
+	Token(ctx context.Context, req TokenRequest) (*oauth.TokenResponse, error)
+	Introspect(ctx context.Context, credentials ClientCredentials, token string) (*oauth.IntrospectionResponse, error)
+	Revoke(ctx context.Context, credentials ClientCredentials, token string) error
+}
+
+// ClientRegistration describes a new OAuth client. GrantTypes defaults to the authorization code
+// and refresh token grants, client credentials is only available to confidential clients.
+type ClientRegistration struct {
+	Name         string
+	RedirectURIs []string
+	Scopes       []string
+	GrantTypes   []string
+	Confidential bool
+}
+
+// AuthorizationRequest holds the parameters of the authorization endpoint (RFC 6749 section 4.1.1
+// with the PKCE parameters of RFC 7636). Scope is space separated.
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// ClientCredentials authenticates the client at the token, introspection and revocation endpoints.
+// Public clients only send their ClientID.
+type ClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+}
+
+// TokenRequest holds the parameters of the token endpoint for every supported grant.
+type TokenRequest struct {
+	ClientCredentials
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Scope        string
+	UserAgent    string
+	IPAddress    string
+}
+
+type OAuthService struct {
+	oauthRepo   oauth_repositories.OAuthRepositoryInterface
+	authRepo    auth_repositories.AuthRepositoryInterface
+	authService auth_services.AuthServiceInterface
+	config      security.OAuthConfig
+}
+
+func NewOAuthService(oauthRepo oauth_repositories.OAuthRepositoryInterface, authRepo auth_repositories.AuthRepositoryInterface, authService auth_services.AuthServiceInterface) *OAuthService {
+	return &OAuthService{
+		oauthRepo:   oauthRepo,
+		authRepo:    authRepo,
+		authService: authService,
+		config:      security.LoadOAuthConfig(),
+	}
+}
+
+// RegisterClient registers a new OAuth client owned by the user. The client secret of confidential
+// clients is returned once in the response, only its hash is stored.
+func (s *OAuthService) RegisterClient(ctx context.Context, userID int64, registration ClientRegistration) (*oauth.ClientResponse, error) {
+	name := strings.TrimSpace(registration.Name)
+	if name == "" {
+		return nil, fmt.Errorf("client name is required")
+	}
+
+	grantTypes := registration.GrantTypes
+	if len(grantTypes) == 0 {
+		grantTypes = []string{oauth.GrantAuthorizationCode, oauth.GrantRefreshToken}
+	}
+	for _, grantType := range grantTypes {
+		switch grantType {
+		case oauth.GrantAuthorizationCode, oauth.GrantRefreshToken:
+		case oauth.GrantClientCredentials:
+			if !registration.Confidential {
+				return nil, fmt.Errorf("the client_credentials grant requires a confidential client")
+			}
+		default:
+			return nil, fmt.Errorf("unsupported grant type %q", grantType)
+		}
+	}
+
+	for _, redirectURI := range registration.RedirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Fragment != "" {
+			return nil, fmt.Errorf("redirect uri %q must be an absolute URL without fragment", redirectURI)
+		}
+	}
+	if containsString(grantTypes, oauth.GrantAuthorizationCode) && len(registration.RedirectURIs) == 0 {
+		return nil, fmt.Errorf("at least one redirect uri is required for the authorization_code grant")
+	}
+
+	if len(registration.Scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range registration.Scopes {
+		if !auth.IsDelegableScope(scope) {
+			return nil, fmt.Errorf("scope %q cannot be granted to OAuth clients, allowed scopes: %s", scope, strings.Join(auth.DelegableScopes, ", "))
+		}
+	}
+
+	clientID, err := helpers.GenerateRandomToken(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate client id: %w", err)
+	}
+
+	client := &oauth.Client{
+		UserID:       userID,
+		Name:         name,
+		ClientID:     clientID,
+		RedirectURIs: strings.Join(registration.RedirectURIs, " "),
+		Scopes:       strings.Join(registration.Scopes, ","),
+		GrantTypes:   strings.Join(grantTypes, ","),
+		Confidential: registration.Confidential,
+	}
+
+	var clientSecret string
+	if registration.Confidential {
+		clientSecret, err = helpers.GenerateRandomToken(32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate client secret: %w", err)
+		}
+		client.SecretHash = helpers.HashToken(clientSecret)
+	}
+
+	if err := s.oauthRepo.CreateClient(client); err != nil {
+		return nil, fmt.Errorf("failed to register client: %w", err)
+	}
+
+	response := clientResponse(client)
+	response.ClientSecret = clientSecret
+	return &response, nil
+}
+
+// ListClients returns the active OAuth clients registered by the user, newest first.
+func (s *OAuthService) ListClients(ctx context.Context, userID int64) ([]oauth.ClientResponse, error) {
+	clients, err := s.oauthRepo.FindClientsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("could not list clients: %w", err)
+	}
+
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].CreatedAt.After(clients[j].CreatedAt)
+	})
+
+	response := make([]oauth.ClientResponse, 0, len(clients))
+	for i := range clients {
+		response = append(response, clientResponse(&clients[i]))
+	}
+	return response, nil
+}
+
+// RevokeClient disables a client of the user and revokes every token issued to it.
+func (s *OAuthService) RevokeClient(ctx context.Context, userID int64, clientUUID string) error {
+	client, err := s.oauthRepo.FindClientByUUID(userID, clientUUID)
+	if err != nil {
+		return err
+	}
+	return s.oauthRepo.RevokeClient(client.ID)
+}
+
+// ValidateAuthorization checks an authorization request and describes it for the consent screen.
+func (s *OAuthService) ValidateAuthorization(ctx context.Context, req AuthorizationRequest) (*oauth.AuthorizationPrompt, error) {
+	client, redirectURI, scopes, err := s.validateAuthorization(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth.AuthorizationPrompt{
+		ClientID:    client.ClientID,
+		ClientName:  client.Name,
+		RedirectURI: redirectURI,
+		Scopes:      scopes,
+		State:       req.State,
+	}, nil
+}
+
+// Authorize records the approval of the user and returns the redirect URI of the client with the
+// authorization code and state appended. The code is single-use and expires after OAUTH_CODE_TTL.
+func (s *OAuthService) Authorize(ctx context.Context, userID int64, req AuthorizationRequest) (string, error) {
+	client, redirectURI, scopes, err := s.validateAuthorization(req)
+	if err != nil {
+		return "", err
+	}
+
+	code, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+
+	authorizationCode := &oauth.AuthorizationCode{
+		OauthClientID:       client.ID,
+		UserID:              userID,
+		CodeHash:            helpers.HashToken(code),
+		RedirectURI:         redirectURI,
+		Scopes:              strings.Join(scopes, ","),
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		ExpiresAt:           time.Now().Add(s.config.CodeTTL),
+	}
+	if err := s.oauthRepo.CreateAuthorizationCode(authorizationCode); err != nil {
+		return "", fmt.Errorf("failed to save authorization code: %w", err)
+	}
+
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", invalidRequest("invalid redirect_uri")
+	}
+	query := redirect.Query()
+	query.Set("code", code)
+	if req.State != "" {
+		query.Set("state", req.State)
+	}
+	redirect.RawQuery = query.Encode()
+
+	return redirect.String(), nil
+}
+
+// validateAuthorization returns the client, the redirect URI and the scopes of a valid authorization request.
+// PKCE with the S256 method is required for every client.
+func (s *OAuthService) validateAuthorization(req AuthorizationRequest) (*oauth.Client, string, []string, error) {
+	if req.ResponseType != "code" {
+		return nil, "", nil, unsupportedResponseType("response_type must be code")
+	}
+
+	client, err := s.oauthRepo.FindClientByClientID(req.ClientID)
+	if err != nil {
+		return nil, "", nil, invalidRequest("unknown client_id")
+	}
+	if !client.AllowsGrant(oauth.GrantAuthorizationCode) {
+		return nil, "", nil, unauthorizedClient("client is not allowed to use the authorization_code grant")
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIList()) == 1 {
+		redirectURI = client.RedirectURIList()[0]
+	}
+	if !client.AllowsRedirectURI(redirectURI) {
+		return nil, "", nil, invalidRequest("redirect_uri is not registered for this client")
+	}
+
+	if req.CodeChallenge == "" {
+		return nil, "", nil, invalidRequest("code_challenge is required")
+	}
+	if req.CodeChallengeMethod != "S256" {
+		return nil, "", nil, invalidRequest("code_challenge_method must be S256")
+	}
+	if len(req.CodeChallenge) < 43 || len(req.CodeChallenge) > 128 {
+		return nil, "", nil, invalidRequest("code_challenge must be between 43 and 128 characters")
+	}
+
+	scopes, err := requestedScopes(client, req.Scope, client.ScopeList())
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return client, redirectURI, scopes, nil
+}
+
+// Token handles the token endpoint for the authorization code, client credentials and refresh token grants.
+func (s *OAuthService) Token(ctx context.Context, req TokenRequest) (*oauth.TokenResponse, error) {
+	switch req.GrantType {
+	case oauth.GrantAuthorizationCode:
+		return s.exchangeAuthorizationCode(req)
+	case oauth.GrantClientCredentials:
+		return s.clientCredentials(req)
+	case oauth.GrantRefreshToken:
+		return s.refreshToken(ctx, req)
+	case "":
+		return nil, invalidRequest("grant_type is required")
+	default:
+		return nil, unsupportedGrantType("grant_type " + req.GrantType + " is not supported")
+	}
+}
+
+func (s *OAuthService) exchangeAuthorizationCode(req TokenRequest) (*oauth.TokenResponse, error) {
+	client, err := s.authenticateClient(req.ClientCredentials, true)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(oauth.GrantAuthorizationCode) {
+		return nil, unauthorizedClient("client is not allowed to use the authorization_code grant")
+	}
+
+	code, err := s.oauthRepo.FindAuthorizationCode(helpers.HashToken(req.Code))
+	if err != nil || code.OauthClientID != client.ID {
+		return nil, invalidGrant("invalid authorization code")
+	}
+	if code.Used {
+		s.revokeReplayedCode(code.CodeHash)
+		return nil, invalidGrant("authorization code has already been used")
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return nil, invalidGrant("authorization code has expired")
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIList()) == 1 {
+		redirectURI = client.RedirectURIList()[0]
+	}
+	if redirectURI != code.RedirectURI {
+		return nil, invalidGrant("redirect_uri does not match the authorization request")
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return nil, invalidGrant("invalid code_verifier")
+	}
+
+	// Klaim code sebelum token dibuat, dari request paralel hanya satu yang berhasil
+	claimed, err := s.oauthRepo.ClaimAuthorizationCode(code.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mark authorization code as used: %w", err)
+	}
+	if !claimed {
+		s.revokeReplayedCode(code.CodeHash)
+		return nil, invalidGrant("authorization code has already been used")
+	}
+
+	scopes := auth.SplitScopes(code.Scopes)
+	tokens, err := s.authService.GenerateTokens(code.UserID, auth_services.TokenOptions{
+		ClientType:    security.ClientWeb,
+		UserAgent:     req.UserAgent,
+		IPAddress:     req.IPAddress,
+		DeviceName:    client.Name,
+		OauthClientID: client.ID,
+		Scopes:        scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed generate tokens: %w", err)
+	}
+
+	if err := s.oauthRepo.SetAuthorizationCodeSession(code.ID, tokens.SessionID); err != nil {
+		return nil, fmt.Errorf("failed to link authorization code to session: %w", err)
+	}
+
+	return tokenResponse(client, tokens, scopes), nil
+}
+
+// revokeReplayedCode revokes the tokens issued for an authorization code that is presented again,
+// as RFC 6749 section 4.1.2 recommends, since the code has most likely been intercepted.
+func (s *OAuthService) revokeReplayedCode(codeHash string) {
+	// Baca ulang, sesi dari penukaran pertama bisa tercatat setelah code dibaca
+	code, err := s.oauthRepo.FindAuthorizationCode(codeHash)
+	if err != nil || code.SessionID == 0 {
+		return
+	}
+
+	if err := s.authRepo.RevokeSession(code.SessionID); err != nil {
+		loggers.Log.Error("failed to revoke tokens of replayed authorization code", map[string]interface{}{
+			"code_id":    code.ID,
+			"session_id": code.SessionID,
+			"error":      err.Error(),
+		})
+		return
+	}
+
+	loggers.Log.Warn("Authorization code replayed, issued tokens revoked", map[string]interface{}{
+		"code_id":    code.ID,
+		"session_id": code.SessionID,
+	})
+}
+
+// clientCredentials issues an access token without refresh token to a confidential client. The token
+// acts as the user who registered the client, limited to the requested scopes.
+func (s *OAuthService) clientCredentials(req TokenRequest) (*oauth.TokenResponse, error) {
+	client, err := s.authenticateClient(req.ClientCredentials, false)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(oauth.GrantClientCredentials) {
+		return nil, unauthorizedClient("client is not allowed to use the client_credentials grant")
+	}
+
+	scopes, err := requestedScopes(client, req.Scope, client.ScopeList())
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.authService.GenerateAccessToken(client.UserID, auth_services.TokenOptions{
+		ClientType:    security.ClientService,
+		OauthClientID: client.ID,
+		Scopes:        scopes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed generate tokens: %w", err)
+	}
+
+	return tokenResponse(client, tokens, scopes), nil
+}
+
+// refreshToken rotates a refresh token issued to the client. A scope parameter narrows the new access
+// token to a subset of the original grant, the new refresh token keeps the original scopes (RFC 6749 section 6).
+func (s *OAuthService) refreshToken(ctx context.Context, req TokenRequest) (*oauth.TokenResponse, error) {
+	client, err := s.authenticateClient(req.ClientCredentials, true)
+	if err != nil {
+		return nil, err
+	}
+	if !client.AllowsGrant(oauth.GrantRefreshToken) {
+		return nil, unauthorizedClient("client is not allowed to use the refresh_token grant")
+	}
+
+	record, err := s.authRepo.FindRefreshToken(req.RefreshToken)
+	if err != nil || record.OauthClientID != client.ID {
+		return nil, invalidGrant("invalid refresh token")
+	}
+
+	scopes := auth.SplitScopes(record.Scopes)
+	narrowed := strings.Fields(req.Scope)
+	for _, scope := range narrowed {
+		if !containsString(scopes, scope) {
+			return nil, invalidScope("scope " + scope + " was not granted originally")
+		}
+	}
+	if len(narrowed) > 0 {
+		scopes = narrowed
+	}
+
+	tokens, err := s.authService.RefreshClientToken(ctx, req.RefreshToken, client.ID, narrowed)
+	if err != nil {
+		return nil, invalidGrant(err.Error())
+	}
+
+	return tokenResponse(client, tokens, scopes), nil
+}
+
+// Introspect describes a token issued to the client (RFC 7662). Tokens that are unknown, inactive or
+// issued to another client are reported as inactive only.
+func (s *OAuthService) Introspect(ctx context.Context, credentials ClientCredentials, token string) (*oauth.IntrospectionResponse, error) {
+	client, err := s.authenticateClient(credentials, false)
+	if err != nil {
+		return nil, err
+	}
+
+	inactive := &oauth.IntrospectionResponse{Active: false}
+	now := time.Now()
+
+	if access, err := s.authRepo.FindAccessToken(token); err == nil {
+		if access.OauthClientID != client.ID || access.Revoked || now.After(access.ExpiresAt) {
+			return inactive, nil
+		}
+		return s.introspectionResponse(client, access.UserID, access.ScopeList(), "Bearer", access.ExpiresAt, access.CreatedAt), nil
+	}
+
+	if refresh, err := s.authRepo.FindRefreshToken(token); err == nil {
+		if refresh.OauthClientID != client.ID || refresh.Revoked || refresh.Claimed || now.After(refresh.ExpiresAt) {
+			return inactive, nil
+		}
+		return s.introspectionResponse(client, refresh.UserID, auth.SplitScopes(refresh.Scopes), "refresh_token", refresh.ExpiresAt, refresh.CreatedAt), nil
+	}
+
+	return inactive, nil
+}
+
+func (s *OAuthService) introspectionResponse(client *oauth.Client, userID int64, scopes []string, tokenType string, expiresAt time.Time, issuedAt time.Time) *oauth.IntrospectionResponse {
+	response := &oauth.IntrospectionResponse{
+		Active:    true,
+		Scope:     strings.Join(scopes, " "),
+		ClientID:  client.ClientID,
+		TokenType: tokenType,
+		Exp:       expiresAt.Unix(),
+		Iat:       issuedAt.Unix(),
+	}
+	if user, err := s.authRepo.FindByID(userID); err == nil {
+		response.Username = user.Username
+		response.Sub = user.UUID
+	}
+	return response
+}
+
+// Revoke revokes a token issued to the client (RFC 7009). Revoking a refresh token ends the whole
+// grant, including its access tokens. Unknown tokens are ignored, as the RFC requires.
+func (s *OAuthService) Revoke(ctx context.Context, credentials ClientCredentials, token string) error {
+	client, err := s.authenticateClient(credentials, true)
+	if err != nil {
+		return err
+	}
+
+	if access, err := s.authRepo.FindAccessToken(token); err == nil {
+		if access.OauthClientID == client.ID {
+			return s.authRepo.MarkTokenAsRevoked(access.ID)
+		}
+		return nil
+	}
+
+	if refresh, err := s.authRepo.FindRefreshToken(token); err == nil && refresh.OauthClientID == client.ID {
+		// Refresh token tanpa sesi dicabut sendiri, sesi 0 bukan sesi siapa pun
+		if refresh.SessionID == 0 {
+			return s.authRepo.RevokeRefreshToken(refresh.ID)
+		}
+		return s.authRepo.RevokeSession(refresh.SessionID)
+	}
+
+	return nil
+}
+
+// authenticateClient checks the client credentials. Confidential clients must send a valid secret,
+// public clients are only accepted when allowPublic is set.
+func (s *OAuthService) authenticateClient(credentials ClientCredentials, allowPublic bool) (*oauth.Client, error) {
+	if credentials.ClientID == "" {
+		return nil, invalidClient("client authentication is required")
+	}
+
+	client, err := s.oauthRepo.FindClientByClientID(credentials.ClientID)
+	if err != nil {
+		return nil, invalidClient("client authentication failed")
+	}
+
+	if !client.Confidential {
+		if !allowPublic {
+			return nil, unauthorizedClient("public clients cannot use this endpoint")
+		}
+		return client, nil
+	}
+
+	secretHash := helpers.HashToken(credentials.ClientSecret)
+	if credentials.ClientSecret == "" || subtle.ConstantTimeCompare([]byte(secretHash), []byte(client.SecretHash)) != 1 {
+		return nil, invalidClient("client authentication failed")
+	}
+	return client, nil
+}
+
+// requestedScopes parses the space separated scope parameter, falling back to the default scopes.
+// Every scope must be allowed for the client.
+func requestedScopes(client *oauth.Client, scope string, defaults []string) ([]string, error) {
+	scopes := strings.Fields(scope)
+	if len(scopes) == 0 {
+		scopes = defaults
+	}
+	for _, requested := range scopes {
+		if !client.AllowsScope(requested) {
+			return nil, invalidScope("scope " + requested + " is not allowed for this client")
+		}
+	}
+	return scopes, nil
+}
+
+// verifyCodeChallenge checks the PKCE code verifier against the S256 code challenge (RFC 7636 section 4.6).
+func verifyCodeChallenge(verifier string, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func tokenResponse(client *oauth.Client, tokens *auth_services.TokenResult, scopes []string) *oauth.TokenResponse {
+	response := &oauth.TokenResponse{
+		AccessToken: tokens.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(tokens.AccessExpiresAt).Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}
+	if client.AllowsGrant(oauth.GrantRefreshToken) {
+		response.RefreshToken = tokens.RefreshToken
+	}
+	return response
+}
+
+func clientResponse(client *oauth.Client) oauth.ClientResponse {
+	return oauth.ClientResponse{
+		UUID:         client.UUID,
+		Name:         client.Name,
+		ClientID:     client.ClientID,
+		RedirectURIs: client.RedirectURIList(),
+		Scopes:       client.ScopeList(),
+		GrantTypes:   client.GrantTypeList(),
+		Confidential: client.Confidential,
+		CreatedAt:    client.CreatedAt,
+	}
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,14 @@
+package organization_services
+
+import "errors"
+
+var (
+	// ErrOrganizationNotFound is also returned to users that are not a member, so the
+	// existence of other organizations is not revealed.
+	ErrOrganizationNotFound   = errors.New("organization not found")
+	ErrNotOrganizationAdmin   = errors.New("only owners and admins of the organization can manage its members")
+	ErrInvalidInvitation      = errors.New("invitation is invalid or has expired")
+	ErrInvitationForOtherUser = errors.New("invitation was sent to another email address")
+	ErrAlreadyMember          = errors.New("user is already a member of the organization")
+	ErrEmailNotVerified       = errors.New("verify your email address before accepting the invitation")
+)
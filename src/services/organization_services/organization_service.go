@@ -0,0 +1,312 @@
+package organization_services
+
+import (
+	"context"
+	"fmt"
+	"gin/src/configs/security"
+	"gin/src/entities/organizations"
+	"gin/src/helpers"
+	"gin/src/repositories/organization_repositories"
+	repositories "gin/src/repositories/user_repositories"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type OrganizationServiceInterface interface {
+	CreateOrganization(ctx context.Context, userID int64, name string) (*organizations.OrganizationResponse, error)
+	ListOrganizations(ctx context.Context, userID int64) ([]organizations.OrganizationResponse, error)
+	GetOrganization(ctx context.Context, userID int64, organizationID int64) (*organizations.OrganizationResponse, error)
+	GetOrganizationByUUID(ctx context.Context, userID int64, organizationUUID string) (*organizations.OrganizationResponse, error)
+	ListMembers(ctx context.Context, userID int64, organizationUUID string) ([]organizations.MemberResponse, error)
+	InviteMember(ctx context.Context, userID int64, organizationUUID string, email string, role string) (*organizations.InvitationResponse, error)
+	ListInvitations(ctx context.Context, userID int64, organizationUUID string) ([]organizations.InvitationResponse, error)
+	AcceptInvitation(ctx context.Context, userID int64, token string) (*organizations.OrganizationResponse, error)
+}
+
+type OrganizationService struct {
+	orgRepo  organization_repositories.OrganizationRepositoryInterface
+	userRepo repositories.UserRepository
+	mailer   mailers.Mailer
+	config   security.OrganizationConfig
+}
+
+func NewOrganizationService(orgRepo organization_repositories.OrganizationRepositoryInterface, userRepo repositories.UserRepository, mailer mailers.Mailer) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:  orgRepo,
+		userRepo: userRepo,
+		mailer:   mailer,
+		config:   security.LoadOrganizationConfig(),
+	}
+}
+
+// CreateOrganization creates an organization with the user as its owner.
+func (s *OrganizationService) CreateOrganization(ctx context.Context, userID int64, name string) (*organizations.OrganizationResponse, error) {
+	organization := organizations.Organization{
+		Name:    strings.TrimSpace(name),
+		OwnerID: userID,
+	}
+	if err := s.orgRepo.CreateOrganization(&organization); err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	membership := organizations.Membership{
+		OrganizationID: organization.ID,
+		UserID:         userID,
+		Role:           organizations.RoleOwner,
+	}
+	if err := s.orgRepo.CreateMembership(&membership); err != nil {
+		return nil, fmt.Errorf("failed to add owner: %w", err)
+	}
+
+	response := organizationResponse(&organization, &membership)
+	return &response, nil
+}
+
+// ListOrganizations returns the organizations the user is a member of, with the role of the user.
+func (s *OrganizationService) ListOrganizations(ctx context.Context, userID int64) ([]organizations.OrganizationResponse, error) {
+	memberships, err := s.orgRepo.FindMembershipsByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch memberships: %w", err)
+	}
+
+	response := make([]organizations.OrganizationResponse, 0, len(memberships))
+	for i := range memberships {
+		organization, err := s.orgRepo.FindOrganizationByID(memberships[i].OrganizationID)
+		if err != nil {
+			continue
+		}
+		response = append(response, organizationResponse(organization, &memberships[i]))
+	}
+	return response, nil
+}
+
+// GetOrganization returns an organization the user is a member of, for example the current
+// organization selected by middleware.RequireOrganization.
+func (s *OrganizationService) GetOrganization(ctx context.Context, userID int64, organizationID int64) (*organizations.OrganizationResponse, error) {
+	membership, err := s.orgRepo.FindMembership(organizationID, userID)
+	if err != nil {
+		return nil, ErrOrganizationNotFound
+	}
+	organization, err := s.orgRepo.FindOrganizationByID(organizationID)
+	if err != nil {
+		return nil, ErrOrganizationNotFound
+	}
+
+	response := organizationResponse(organization, membership)
+	return &response, nil
+}
+
+// GetOrganizationByUUID returns an organization the user is a member of by its UUID.
+func (s *OrganizationService) GetOrganizationByUUID(ctx context.Context, userID int64, organizationUUID string) (*organizations.OrganizationResponse, error) {
+	organization, membership, err := s.findMembership(userID, organizationUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := organizationResponse(organization, membership)
+	return &response, nil
+}
+
+// ListMembers returns the members of an organization the user belongs to.
+func (s *OrganizationService) ListMembers(ctx context.Context, userID int64, organizationUUID string) ([]organizations.MemberResponse, error) {
+	organization, _, err := s.findMembership(userID, organizationUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.orgRepo.FindMembershipsByOrganizationID(organization.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch members: %w", err)
+	}
+
+	response := make([]organizations.MemberResponse, 0, len(memberships))
+	for _, membership := range memberships {
+		user, err := s.userRepo.FindByID(membership.UserID)
+		if err != nil {
+			continue
+		}
+		response = append(response, organizations.MemberResponse{
+			UUID:     membership.UUID,
+			UserUUID: user.UUID,
+			Email:    user.Email,
+			Username: user.Username,
+			Role:     membership.Role,
+			JoinedAt: membership.CreatedAt,
+		})
+	}
+	return response, nil
+}
+
+// InviteMember emails an invitation to join the organization with the given role. Only owners
+// and admins can invite, and nobody can be invited as owner.
+func (s *OrganizationService) InviteMember(ctx context.Context, userID int64, organizationUUID string, email string, role string) (*organizations.InvitationResponse, error) {
+	organization, membership, err := s.findMembership(userID, organizationUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !membership.CanManageMembers() {
+		return nil, ErrNotOrganizationAdmin
+	}
+
+	if role == "" {
+		role = organizations.RoleMember
+	}
+	if role != organizations.RoleAdmin && role != organizations.RoleMember {
+		return nil, helpers.FieldErrors{"role": "role must be one of admin member"}
+	}
+
+	if invitee, err := s.userRepo.FindByEmail(email); err == nil {
+		if _, err := s.orgRepo.FindMembership(organization.ID, invitee.ID); err == nil {
+			return nil, ErrAlreadyMember
+		}
+	}
+
+	token, err := helpers.GenerateRandomToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	invitation := organizations.Invitation{
+		OrganizationID: organization.ID,
+		Email:          email,
+		Role:           role,
+		InvitedBy:      userID,
+		TokenHash:      helpers.HashToken(token),
+		ExpiresAt:      time.Now().Add(s.config.InvitationTTL),
+	}
+	if err := s.orgRepo.CreateInvitation(&invitation); err != nil {
+		return nil, fmt.Errorf("failed to save invitation: %w", err)
+	}
+
+	inviter := "A member"
+	if user, err := s.userRepo.FindByID(userID); err == nil {
+		inviter = user.Username
+	}
+
+	link := s.config.InvitationAcceptURL + "?token=" + url.QueryEscape(token)
+	err = s.mailer.Send(ctx, mailers.Message{
+		To:      email,
+		Subject: fmt.Sprintf("You have been invited to join %s", organization.Name),
+		Body: fmt.Sprintf(
+			"Hi,\n\n%s invited you to join %s as %s. Open the link below to accept the invitation:\n\n%s\n\nThe link expires in %s. If you do not have an account yet, sign up with this email address first.\n",
+			inviter, organization.Name, role, link, s.config.InvitationTTL,
+		),
+	})
+	if err != nil {
+		loggers.Log.Error("failed to send invitation email", map[string]interface{}{
+			"organization_id": organization.ID,
+			"invitation_id":   invitation.ID,
+			"error":           err.Error(),
+		})
+		return nil, fmt.Errorf("failed to send invitation email: %w", err)
+	}
+
+	response := invitationResponse(&invitation)
+	return &response, nil
+}
+
+// ListInvitations returns the pending invitations of the organization, for owners and admins.
+func (s *OrganizationService) ListInvitations(ctx context.Context, userID int64, organizationUUID string) ([]organizations.InvitationResponse, error) {
+	organization, membership, err := s.findMembership(userID, organizationUUID)
+	if err != nil {
+		return nil, err
+	}
+	if !membership.CanManageMembers() {
+		return nil, ErrNotOrganizationAdmin
+	}
+
+	invitations, err := s.orgRepo.FindPendingInvitations(organization.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch invitations: %w", err)
+	}
+
+	response := make([]organizations.InvitationResponse, 0, len(invitations))
+	for i := range invitations {
+		response = append(response, invitationResponse(&invitations[i]))
+	}
+	return response, nil
+}
+
+// AcceptInvitation adds the signed-in user to the organization of the invitation. The invitation
+// is single-use and only valid for the email address it was sent to, which the user must have
+// verified (ErrEmailNotVerified).
+func (s *OrganizationService) AcceptInvitation(ctx context.Context, userID int64, token string) (*organizations.OrganizationResponse, error) {
+	invitation, err := s.orgRepo.FindInvitationByTokenHash(helpers.HashToken(token))
+	if err != nil || !invitation.IsPending(time.Now()) {
+		return nil, ErrInvalidInvitation
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationForOtherUser
+	}
+	// Tanpa verifikasi, siapa pun yang mendaftar dengan email undangan bisa ikut masuk
+	if !user.IsEmailVerified() {
+		return nil, ErrEmailNotVerified
+	}
+
+	organization, err := s.orgRepo.FindOrganizationByID(invitation.OrganizationID)
+	if err != nil {
+		return nil, ErrInvalidInvitation
+	}
+
+	if _, err := s.orgRepo.FindMembership(organization.ID, userID); err == nil {
+		return nil, ErrAlreadyMember
+	}
+
+	// Tandai dipakai lebih dulu agar link yang sama tidak bisa dipakai dua kali
+	if err := s.orgRepo.MarkInvitationAsAccepted(invitation); err != nil {
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	membership := organizations.Membership{
+		OrganizationID: organization.ID,
+		UserID:         userID,
+		Role:           invitation.Role,
+	}
+	if err := s.orgRepo.CreateMembership(&membership); err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	response := organizationResponse(organization, &membership)
+	return &response, nil
+}
+
+// findMembership returns the organization and the membership of the user in it. Organizations
+// the user does not belong to are reported as not found.
+func (s *OrganizationService) findMembership(userID int64, organizationUUID string) (*organizations.Organization, *organizations.Membership, error) {
+	organization, err := s.orgRepo.FindOrganizationByUUID(organizationUUID)
+	if err != nil {
+		return nil, nil, ErrOrganizationNotFound
+	}
+	membership, err := s.orgRepo.FindMembership(organization.ID, userID)
+	if err != nil {
+		return nil, nil, ErrOrganizationNotFound
+	}
+	return organization, membership, nil
+}
+
+func organizationResponse(organization *organizations.Organization, membership *organizations.Membership) organizations.OrganizationResponse {
+	return organizations.OrganizationResponse{
+		UUID:      organization.UUID,
+		Name:      organization.Name,
+		Role:      membership.Role,
+		CreatedAt: organization.CreatedAt,
+	}
+}
+
+func invitationResponse(invitation *organizations.Invitation) organizations.InvitationResponse {
+	return organizations.InvitationResponse{
+		UUID:      invitation.UUID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		ExpiresAt: invitation.ExpiresAt,
+		CreatedAt: invitation.CreatedAt,
+	}
+}
@@ -0,0 +1,185 @@
+package role_services
+
+import (
+	"fmt"
+	"gin/src/repositories/role_repositories"
+	"gin/src/utils/caches"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+type RoleServiceInterface interface {
+	UserRoles(userID int64) ([]string, error)
+	UserPermissions(userID int64) (map[string]bool, error)
+	HasPermission(userID int64, permissions ...string) (bool, error)
+	HasRole(userID int64, roleName string) (bool, error)
+	CoversPermissions(actorID int64, targetID int64) (bool, error)
+	AssignRole(userID int64, roleName string) error
+	RemoveRole(userID int64, roleName string) error
+	Forget(userID int64)
+}
+
+// permissionCacheTTL bounds how long a role change can take to reach the permission checks
+// of other application instances. Changes made through this service apply immediately.
+const permissionCacheTTL = time.Minute
+
+type userAccess struct {
+	roles       []string
+	permissions map[string]bool
+}
+
+type RoleService struct {
+	roleRepo role_repositories.RoleRepositoryInterface
+	cache    *caches.TTLCache[int64, userAccess]
+}
+
+func NewRoleService(repo role_repositories.RoleRepositoryInterface) *RoleService {
+	return &RoleService{
+		roleRepo: repo,
+		cache:    caches.NewTTLCache[int64, userAccess](permissionCacheTTL),
+	}
+}
+
+var (
+	defaultService *RoleService
+	defaultOnce    sync.Once
+)
+
+// Default returns the shared RoleService. The middleware and the routes use the same
+// instance, so invalidating a user after a role change also clears the middleware cache.
+func Default() *RoleService {
+	defaultOnce.Do(func() {
+		defaultService = NewRoleService(role_repositories.NewRoleRepository())
+	})
+	return defaultService
+}
+
+// UserRoles returns the role names of the user. The slice is a copy, changing it does not
+// touch the cached roles.
+func (s *RoleService) UserRoles(userID int64) ([]string, error) {
+	access, err := s.load(userID)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(access.roles), nil
+}
+
+// UserPermissions returns the permission names granted to the user through all of their roles.
+// The map is a copy, changing it does not touch the cached permissions.
+func (s *RoleService) UserPermissions(userID int64) (map[string]bool, error) {
+	access, err := s.load(userID)
+	if err != nil {
+		return nil, err
+	}
+	return maps.Clone(access.permissions), nil
+}
+
+// HasPermission reports whether the user has every one of the given permissions.
+func (s *RoleService) HasPermission(userID int64, permissions ...string) (bool, error) {
+	access, err := s.load(userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range permissions {
+		if !access.permissions[permission] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CoversPermissions reports whether the actor holds every permission of the target, so an
+// admin cannot act on an account that is more privileged than their own.
+func (s *RoleService) CoversPermissions(actorID int64, targetID int64) (bool, error) {
+	actor, err := s.load(actorID)
+	if err != nil {
+		return false, err
+	}
+	target, err := s.load(targetID)
+	if err != nil {
+		return false, err
+	}
+
+	for permission, granted := range target.permissions {
+		if granted && !actor.permissions[permission] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// HasRole reports whether the user has the given role.
+func (s *RoleService) HasRole(userID int64, roleName string) (bool, error) {
+	access, err := s.load(userID)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(access.roles, roleName), nil
+}
+
+// AssignRole gives the role to the user and clears the cached permissions of the user.
+func (s *RoleService) AssignRole(userID int64, roleName string) error {
+	role, err := s.roleRepo.FindRoleByName(roleName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.AssignRole(userID, role.ID); err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	s.Forget(userID)
+	return nil
+}
+
+// RemoveRole takes the role away from the user and clears the cached permissions of the user.
+func (s *RoleService) RemoveRole(userID int64, roleName string) error {
+	role, err := s.roleRepo.FindRoleByName(roleName)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.RemoveRole(userID, role.ID); err != nil {
+		return fmt.Errorf("failed to remove role: %w", err)
+	}
+
+	s.Forget(userID)
+	return nil
+}
+
+// Forget removes the cached roles and permissions of the user.
+func (s *RoleService) Forget(userID int64) {
+	s.cache.Delete(userID)
+}
+
+// load returns the roles and permissions of the user from the cache, or from the database
+// when they are not cached yet.
+func (s *RoleService) load(userID int64) (userAccess, error) {
+	if access, ok := s.cache.Get(userID); ok {
+		return access, nil
+	}
+
+	userRoles, err := s.roleRepo.FindRolesByUserID(userID)
+	if err != nil {
+		return userAccess{}, err
+	}
+
+	access := userAccess{permissions: make(map[string]bool)}
+	for _, role := range userRoles {
+		access.roles = append(access.roles, role.Name)
+
+		names, err := s.roleRepo.FindPermissionNamesByRoleID(role.ID)
+		if err != nil {
+			return userAccess{}, err
+		}
+		for _, name := range names {
+			access.permissions[name] = true
+		}
+	}
+
+	s.cache.Set(userID, access)
+	return access, nil
+}
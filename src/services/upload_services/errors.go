@@ -0,0 +1,15 @@
+package upload_services
+
+import "errors"
+
+var (
+	// ErrUploadNotFound is also returned for uploads of other users.
+	ErrUploadNotFound  = errors.New("upload not found")
+	ErrUploadExpired   = errors.New("upload has expired, please start a new one")
+	ErrUploadCompleted = errors.New("upload is already complete")
+	ErrOffsetMismatch  = errors.New("Upload-Offset does not match the offset of the upload")
+	ErrUploadTooLarge  = errors.New("upload is larger than allowed")
+	ErrInvalidLength   = errors.New("Upload-Length must be a positive number")
+	ErrUnknownPurpose  = errors.New("Upload-Metadata must name a supported purpose")
+	ErrInvalidMetadata = errors.New("invalid Upload-Metadata header")
+)
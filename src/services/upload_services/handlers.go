@@ -0,0 +1,47 @@
+package upload_services
+
+import (
+	"context"
+	"gin/src/configs/security"
+	"gin/src/entities/uploads"
+	"gin/src/services/attachment_services"
+	services "gin/src/services/user_services"
+	"io"
+)
+
+// AvatarHandler makes the finished upload the avatar of the user, through the same
+// validation and re-encoding as POST /user/upload/avatar.
+func AvatarHandler(userService services.UserService) CompletionHandler {
+	return CompletionHandler{
+		MaxSize: security.LoadAvatarConfig().MaxBytes,
+		Complete: func(ctx context.Context, userID int64, upload *uploads.Upload, file io.Reader) error {
+			_, err := userService.UploadAvatar(ctx, userID, file, "avatars")
+			return err
+		},
+	}
+}
+
+// AttachmentHandler attaches the finished upload to the owner named by "owner_type" and
+// "owner_uuid" in the Upload-Metadata, the other entries (except purpose) become the
+// metadata of the attachment. Access to the owner is checked when the upload is created.
+func AttachmentHandler(attachmentService attachment_services.AttachmentServiceInterface) CompletionHandler {
+	return CompletionHandler{
+		MaxSize: attachmentService.MaxSize(),
+		Validate: func(ctx context.Context, userID int64, metadata map[string]string) error {
+			return attachmentService.CheckAccess(ctx, userID, metadata["owner_type"], metadata["owner_uuid"])
+		},
+		Complete: func(ctx context.Context, userID int64, upload *uploads.Upload, file io.Reader) error {
+			metadata, err := ParseMetadata(upload.Metadata)
+			if err != nil {
+				return err
+			}
+			ownerType, ownerUUID := metadata["owner_type"], metadata["owner_uuid"]
+			delete(metadata, "purpose")
+			delete(metadata, "owner_type")
+			delete(metadata, "owner_uuid")
+
+			_, err = attachmentService.CreateAttachment(ctx, userID, ownerType, ownerUUID, file, metadata)
+			return err
+		},
+	}
+}
@@ -0,0 +1,367 @@
+package upload_services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"gin/src/configs/security"
+	"gin/src/entities/uploads"
+	"gin/src/repositories/upload_repositories"
+	"gin/src/utils/loggers"
+	"gin/src/utils/storages"
+	"github.com/google/uuid"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CompletionHandler receives the file of a finished upload. MaxSize limits the Upload-Length
+// of uploads for the purpose, 0 leaves only UPLOAD_MAX_SIZE. Validate is optional and checks
+// the decoded Upload-Metadata when the upload is created, before any chunk is sent.
+type CompletionHandler struct {
+	MaxSize  int64
+	Validate func(ctx context.Context, userID int64, metadata map[string]string) error
+	Complete func(ctx context.Context, userID int64, upload *uploads.Upload, file io.Reader) error
+}
+
+type UploadServiceInterface interface {
+	RegisterHandler(purpose string, handler CompletionHandler)
+	MaxSize() int64
+	CreateUpload(ctx context.Context, userID int64, length int64, metadata string) (*uploads.Upload, error)
+	GetUpload(ctx context.Context, userID int64, uploadUUID string) (*uploads.Upload, error)
+	WriteChunk(ctx context.Context, userID int64, uploadUUID string, offset int64, chunk io.Reader) (*uploads.Upload, error)
+	TerminateUpload(ctx context.Context, userID int64, uploadUUID string) error
+	PurgeExpiredUploads(ctx context.Context) error
+	StoredFileKeys(ctx context.Context) ([]string, error)
+}
+
+type UploadService struct {
+	repo     upload_repositories.UploadRepositoryInterface
+	storage  storages.Storage
+	config   security.UploadConfig
+	handlers map[string]CompletionHandler
+	locks    sync.Map // upload uuid -> *sync.Mutex
+}
+
+// NewUploadService creates the resumable upload service. The chunks are kept in the storage,
+// the flows that receive finished files are added with RegisterHandler.
+func NewUploadService(repo upload_repositories.UploadRepositoryInterface, storage storages.Storage) *UploadService {
+	return &UploadService{
+		repo:     repo,
+		storage:  storage,
+		config:   security.LoadUploadConfig(),
+		handlers: make(map[string]CompletionHandler),
+	}
+}
+
+// RegisterHandler lets uploads with the given purpose in their Upload-Metadata be created and
+// hands their finished files to the handler. It is meant to be called while setting up the routes.
+func (s *UploadService) RegisterHandler(purpose string, handler CompletionHandler) {
+	s.handlers[purpose] = handler
+}
+
+// MaxSize returns the largest Upload-Length accepted for any purpose, sent as Tus-Max-Size.
+func (s *UploadService) MaxSize() int64 {
+	return s.config.MaxSize
+}
+
+// CreateUpload starts an upload of length bytes. The metadata is the raw Upload-Metadata
+// header, its "purpose" entry picks the flow that receives the finished file.
+func (s *UploadService) CreateUpload(ctx context.Context, userID int64, length int64, metadata string) (*uploads.Upload, error) {
+	values, err := ParseMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	handler, ok := s.handlers[values["purpose"]]
+	if !ok {
+		return nil, ErrUnknownPurpose
+	}
+
+	if length <= 0 {
+		return nil, ErrInvalidLength
+	}
+	if length > s.config.MaxSize || (handler.MaxSize > 0 && length > handler.MaxSize) {
+		return nil, ErrUploadTooLarge
+	}
+	if handler.Validate != nil {
+		if err := handler.Validate(ctx, userID, values); err != nil {
+			return nil, err
+		}
+	}
+
+	upload := uploads.Upload{
+		UserID:    userID,
+		Purpose:   values["purpose"],
+		Length:    length,
+		Metadata:  metadata,
+		ExpiresAt: time.Now().Add(s.config.TTL),
+	}
+	if err := s.repo.CreateUpload(&upload); err != nil {
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+	return &upload, nil
+}
+
+// GetUpload returns an upload of the user that has not expired yet.
+func (s *UploadService) GetUpload(ctx context.Context, userID int64, uploadUUID string) (*uploads.Upload, error) {
+	upload, err := s.repo.FindUploadByUUID(uploadUUID)
+	if err != nil || upload.UserID != userID {
+		return nil, ErrUploadNotFound
+	}
+	if upload.IsExpired(time.Now()) {
+		return nil, ErrUploadExpired
+	}
+	return upload, nil
+}
+
+// WriteChunk appends the chunk at offset, which must be the current offset of the upload.
+// Each chunk is stored as its own part under a key of its own; a chunk that breaks off is
+// dropped as a whole and the client resumes from the offset reported by HEAD. The offset only
+// advances when it is still the one the chunk was written at, so of two requests for the same
+// offset, in this process or another, one gets ErrOffsetMismatch. When the last byte arrives
+// the parts are handed to the handler of the purpose and removed afterwards.
+func (s *UploadService) WriteChunk(ctx context.Context, userID int64, uploadUUID string, offset int64, chunk io.Reader) (*uploads.Upload, error) {
+	// Lock hanya dibuat untuk upload yang ada dan milik user, supaya map tidak diisi UUID sembarang
+	if _, err := s.GetUpload(ctx, userID, uploadUUID); err != nil {
+		return nil, err
+	}
+
+	// Request untuk upload yang sama dalam satu proses diantrekan, antar proses dijaga AdvanceUpload
+	lock, _ := s.locks.LoadOrStore(uploadUUID, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	upload, err := s.GetUpload(ctx, userID, uploadUUID)
+	if err != nil {
+		return nil, err
+	}
+	if upload.CompletedAt != nil {
+		return nil, ErrUploadCompleted
+	}
+	if offset != upload.Offset {
+		return nil, ErrOffsetMismatch
+	}
+
+	key := partKey(upload, upload.Parts)
+	counter := &countingReader{reader: io.LimitReader(chunk, upload.Length-upload.Offset)}
+	if err := s.storage.Put(ctx, key, counter, "application/offset+octet-stream"); err != nil {
+		return nil, fmt.Errorf("failed to store chunk: %w", err)
+	}
+
+	// Data melebihi Upload-Length ditolak, potongan yang sudah tersimpan dibuang
+	if extra, _ := chunk.Read(make([]byte, 1)); extra > 0 {
+		s.deleteKeys(ctx, []string{key})
+		return nil, ErrUploadTooLarge
+	}
+	if counter.count == 0 {
+		s.deleteKeys(ctx, []string{key})
+		return upload, nil
+	}
+
+	upload.Offset += counter.count
+	upload.Parts++
+	upload.PartKeys = strings.Join(append(upload.StoredParts(), key), "\n")
+	advanced, err := s.repo.AdvanceUpload(upload.ID, offset, map[string]interface{}{
+		"upload_offset": upload.Offset,
+		"parts":         upload.Parts,
+		"part_keys":     upload.PartKeys,
+	})
+	if err != nil {
+		s.deleteKeys(ctx, []string{key})
+		return nil, fmt.Errorf("failed to update upload: %w", err)
+	}
+	if !advanced {
+		// Request lain sudah mengisi offset ini, key potongan ini unik jadi part miliknya tidak ikut terhapus
+		s.deleteKeys(ctx, []string{key})
+		return nil, ErrOffsetMismatch
+	}
+
+	if upload.Offset == upload.Length {
+		if err := s.complete(ctx, upload); err != nil {
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+// complete hands the finished file to the handler of the purpose. A file the handler refuses
+// is thrown away together with the upload, the client has to start over.
+func (s *UploadService) complete(ctx context.Context, upload *uploads.Upload) error {
+	handler, ok := s.handlers[upload.Purpose]
+	if !ok {
+		s.removeUpload(ctx, upload)
+		return ErrUnknownPurpose
+	}
+
+	file := &partsReader{ctx: ctx, storage: s.storage, keys: upload.StoredParts()}
+	err := handler.Complete(ctx, upload.UserID, upload, file)
+	file.Close()
+	if err != nil {
+		s.removeUpload(ctx, upload)
+		return err
+	}
+
+	now := time.Now()
+	upload.CompletedAt = &now
+	if err := s.repo.UpdateUpload(upload.ID, map[string]interface{}{"completed_at": now}); err != nil {
+		return fmt.Errorf("failed to update upload: %w", err)
+	}
+	s.deleteKeys(ctx, upload.StoredParts())
+	return nil
+}
+
+// TerminateUpload removes the upload and the chunks received so far.
+func (s *UploadService) TerminateUpload(ctx context.Context, userID int64, uploadUUID string) error {
+	upload, err := s.repo.FindUploadByUUID(uploadUUID)
+	if err != nil || upload.UserID != userID {
+		return ErrUploadNotFound
+	}
+	return s.removeUpload(ctx, upload)
+}
+
+// PurgeExpiredUploads removes the uploads that expired, finished or not, and their chunks.
+// It is meant to run on a schedule.
+func (s *UploadService) PurgeExpiredUploads(ctx context.Context) error {
+	list, err := s.repo.FindAllUploads()
+	if err != nil {
+		return fmt.Errorf("failed to fetch uploads: %w", err)
+	}
+
+	now := time.Now()
+	var errs []error
+	for i := range list {
+		if !list[i].IsExpired(now) {
+			continue
+		}
+		if err := s.removeUpload(ctx, &list[i]); err != nil {
+			errs = append(errs, fmt.Errorf("upload %s: %w", list[i].UUID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StoredFileKeys returns the chunk prefixes of all uploads, a reference source for the
+// storages.Janitor.
+func (s *UploadService) StoredFileKeys(ctx context.Context) ([]string, error) {
+	list, err := s.repo.FindAllUploads()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch uploads: %w", err)
+	}
+	keys := make([]string, 0, len(list))
+	for i := range list {
+		keys = append(keys, list[i].PartPrefix())
+	}
+	return keys, nil
+}
+
+// removeUpload deletes everything under the part prefix, also the chunks of requests that
+// broke off before their part was recorded.
+func (s *UploadService) removeUpload(ctx context.Context, upload *uploads.Upload) error {
+	keys := upload.StoredParts()
+	if objects, err := s.storage.List(ctx, upload.PartPrefix()); err == nil {
+		keys = keys[:0]
+		for _, object := range objects {
+			keys = append(keys, object.Key)
+		}
+	}
+	s.deleteKeys(ctx, keys)
+	if err := s.repo.DeleteUpload(upload.ID); err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	s.locks.Delete(upload.UUID)
+	return nil
+}
+
+// deleteKeys removes stored chunks. Failures are only logged, a leftover chunk is harmless.
+func (s *UploadService) deleteKeys(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			loggers.Log.Error("failed to delete upload chunk", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
+// partKey returns a key of its own for every chunk written, two requests racing for the same
+// part never share a key.
+func partKey(upload *uploads.Upload, index int) string {
+	return fmt.Sprintf("%spart-%06d-%s", upload.PartPrefix(), index, uuid.New().String())
+}
+
+// ParseMetadata decodes an Upload-Metadata header: comma separated pairs of a key and a
+// base64 encoded value, the value may be left out.
+func ParseMetadata(header string) (map[string]string, error) {
+	values := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return values, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, ErrInvalidMetadata
+		}
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, ErrInvalidMetadata
+		}
+		values[key] = string(value)
+	}
+	return values, nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+// partsReader reads the stored parts one after the other as a single file.
+type partsReader struct {
+	ctx     context.Context
+	storage storages.Storage
+	keys    []string
+	current io.ReadCloser
+}
+
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.keys) == 0 {
+				return 0, io.EOF
+			}
+			part, _, err := r.storage.Get(r.ctx, r.keys[0])
+			if err != nil {
+				return 0, fmt.Errorf("failed to read upload chunk: %w", err)
+			}
+			r.current, r.keys = part, r.keys[1:]
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	return r.current.Close()
+}
@@ -0,0 +1,118 @@
+package upload_services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin/src/entities/uploads"
+	"gin/src/repositories/upload_repositories"
+	"gin/src/utils/storages"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// uploadTestRepo keeps the uploads in memory, like the database it only advances an upload
+// whose offset did not change in the meantime.
+type uploadTestRepo struct {
+	upload_repositories.UploadRepositoryInterface
+
+	mu      sync.Mutex
+	uploads map[string]*uploads.Upload
+	stale   *uploads.Upload // returned by FindUploadByUUID instead of the stored upload when set
+}
+
+func (r *uploadTestRepo) FindUploadByUUID(uuid string) (*uploads.Upload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stale != nil {
+		copied := *r.stale
+		return &copied, nil
+	}
+	upload, ok := r.uploads[uuid]
+	if !ok {
+		return nil, fmt.Errorf("upload not found")
+	}
+	copied := *upload
+	return &copied, nil
+}
+
+func (r *uploadTestRepo) AdvanceUpload(id int64, offset int64, updatedFields map[string]interface{}) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, upload := range r.uploads {
+		if upload.ID != id || upload.Offset != offset {
+			continue
+		}
+		upload.Offset = updatedFields["upload_offset"].(int64)
+		upload.Parts = updatedFields["parts"].(int)
+		upload.PartKeys = updatedFields["part_keys"].(string)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *uploadTestRepo) UpdateUpload(id int64, updatedFields map[string]interface{}) error {
+	return nil
+}
+
+func newUploadTest(t *testing.T) (*UploadService, *uploadTestRepo, *string) {
+	t.Helper()
+
+	repo := &uploadTestRepo{uploads: map[string]*uploads.Upload{
+		"upload-1": {ID: 1, UUID: "upload-1", UserID: 7, Purpose: "test", Length: 10, ExpiresAt: time.Now().Add(time.Hour)},
+	}}
+	var received string
+	service := NewUploadService(repo, storages.NewMemoryStorage("/files"))
+	service.RegisterHandler("test", CompletionHandler{
+		Complete: func(ctx context.Context, userID int64, upload *uploads.Upload, file io.Reader) error {
+			data, err := io.ReadAll(file)
+			received = string(data)
+			return err
+		},
+	})
+	return service, repo, &received
+}
+
+func TestWriteChunkRefusesStaleOffset(t *testing.T) {
+	service, repo, received := newUploadTest(t)
+	ctx := context.Background()
+
+	if _, err := service.WriteChunk(ctx, 7, "upload-1", 0, strings.NewReader("01234")); err != nil {
+		t.Fatalf("first chunk failed: %v", err)
+	}
+
+	// Proses lain yang masih membaca offset 0 tidak boleh menimpa part yang sudah tercatat
+	other := NewUploadService(repo, service.storage)
+	repo.stale = &uploads.Upload{ID: 1, UUID: "upload-1", UserID: 7, Purpose: "test", Length: 10, ExpiresAt: time.Now().Add(time.Hour)}
+	if _, err := other.WriteChunk(ctx, 7, "upload-1", 0, strings.NewReader("abcde")); !errors.Is(err, ErrOffsetMismatch) {
+		t.Fatalf("expected ErrOffsetMismatch for a chunk at an offset already written, got %v", err)
+	}
+	repo.stale = nil
+
+	upload, err := service.WriteChunk(ctx, 7, "upload-1", 5, strings.NewReader("56789"))
+	if err != nil {
+		t.Fatalf("last chunk failed: %v", err)
+	}
+	if upload.CompletedAt == nil || *received != "0123456789" {
+		t.Fatalf("expected the completed file %q, got %q (completed %v)", "0123456789", *received, upload.CompletedAt)
+	}
+}
+
+func TestWriteChunkLocksOnlyOwnUploads(t *testing.T) {
+	service, _, _ := newUploadTest(t)
+
+	for _, uploadUUID := range []string{"upload-1", "missing"} {
+		if _, err := service.WriteChunk(context.Background(), 8, uploadUUID, 0, strings.NewReader("x")); !errors.Is(err, ErrUploadNotFound) {
+			t.Errorf("%s: expected ErrUploadNotFound, got %v", uploadUUID, err)
+		}
+	}
+	service.locks.Range(func(key, value any) bool {
+		t.Errorf("no lock may be kept for an upload the user cannot write, found %v", key)
+		return true
+	})
+}
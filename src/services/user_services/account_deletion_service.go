@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	"gin/src/utils/hashers"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"time"
+)
+
+// DeleteAccount schedules the account of the user for deletion after checking the password.
+// Every session and personal access token is revoked right away, the personal data is anonymised by
+// PurgeDeletedAccounts once the grace period is over. Signing in again before that
+// cancels the deletion.
+func (s *userService) DeleteAccount(ctx context.Context, userID int64, password string) (*users.AccountDeletionResponse, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok, err := hashers.Default().Verify(password, user.Password); err != nil || !ok {
+		return nil, helpers.FieldErrors{"password": "password is incorrect"}
+	}
+
+	scheduledAt := time.Now().Add(s.accountConfig.DeletionGracePeriod)
+	if err := s.repo.ScheduleDeletion(user.ID, scheduledAt); err != nil {
+		return nil, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	if err := s.authRepo.RevokeAllUserTokens(user.ID); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	// API key juga dicabut, login ulang membatalkan penghapusan tapi tidak menghidupkan key lama
+	if err := s.authRepo.RevokeAllPersonalAccessTokens(user.ID); err != nil {
+		return nil, fmt.Errorf("failed to revoke personal access tokens: %w", err)
+	}
+
+	err = s.mailer.Send(ctx, mailers.Message{
+		To:      user.Email,
+		Subject: "Your account will be deleted",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour account is scheduled for deletion on %s. Your personal data will be removed after that date.\n\nChanged your mind? Just sign in again before then and the deletion is cancelled.\n",
+			user.Username, scheduledAt.Format(time.RFC1123),
+		),
+	})
+	if err != nil {
+		loggers.Log.Error("failed to send account deletion notice", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+
+	return &users.AccountDeletionResponse{DeletionScheduledAt: scheduledAt}, nil
+}
+
+// PurgeDeletedAccounts anonymises every account whose deletion grace period is over: the
+// personal data in users is replaced, tokens, sessions and other credentials are purged, the
+// avatar, attachments and data export files are deleted and files the user attached elsewhere
+// are detached from the user. An account is only touched while its deletion is still pending,
+// one the user cancelled after the list was read is skipped. It is meant to run on a schedule.
+func (s *userService) PurgeDeletedAccounts(ctx context.Context) error {
+	pending, err := s.repo.FindPendingDeletions()
+	if err != nil {
+		return fmt.Errorf("failed to fetch pending deletions: %w", err)
+	}
+
+	now := time.Now()
+	var errs []error
+	for i := range pending {
+		user := &pending[i]
+		if user.DeletionScheduledAt == nil || user.DeletionScheduledAt.After(now) {
+			continue
+		}
+		if _, err := s.anonymizeUser(user, now); err != nil {
+			errs = append(errs, fmt.Errorf("user %d: %w", user.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// anonymizeUser removes the personal data of a user whose deletion is due at now. It reports
+// false when the deletion was cancelled in the meantime, nothing is removed then.
+func (s *userService) anonymizeUser(user *users.User, now time.Time) (bool, error) {
+	// Data pribadi diganti dulu, file dan kredensial hanya dihapus kalau penghapusan belum dibatalkan
+	anonymized, err := s.repo.Anonymize(user, now)
+	if err != nil || !anonymized {
+		return false, err
+	}
+
+	for _, key := range user.Avatar.Keys() {
+		if err := s.storage.Delete(context.Background(), key); err != nil {
+			return true, err
+		}
+	}
+
+	// Lampiran akun dihapus, lampiran di owner lain dilepas dari user
+	if err := s.attachmentService.RemoveUserAttachments(context.Background(), user.ID); err != nil {
+		return true, err
+	}
+
+	exports, err := s.repo.FindDataExportsByUserID(user.ID)
+	if err != nil {
+		return true, err
+	}
+	for i := range exports {
+		if err := s.deleteDataExport(&exports[i]); err != nil {
+			return true, err
+		}
+	}
+
+	return true, s.authRepo.PurgeUserCredentials(user.ID)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	"gin/src/utils/hashers"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"gin/src/utils/passwords"
+	"gin/src/utils/signers"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UpdateProfile changes the username of the user. The username must not be used by another user.
+func (s *userService) UpdateProfile(ctx context.Context, userID int64, username string) (*users.User, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if username != user.Username {
+		if existing, err := s.repo.FindByUsername(username); err == nil && existing.ID != user.ID {
+			return nil, ErrUsernameTaken
+		}
+		if err := s.repo.UpdateUsername(user.ID, username); err != nil {
+			return nil, fmt.Errorf("failed to update profile: %w", err)
+		}
+		user.Username = username
+	}
+
+	return user, nil
+}
+
+// ChangePassword sets a new password after checking the current one. The new password has to
+// pass the password policy. Every other session of the user is revoked, the session the
+// request was made with (sessionID) stays signed in.
+func (s *userService) ChangePassword(ctx context.Context, userID int64, sessionID int64, currentPassword string, newPassword string) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if ok, err := hashers.Default().Verify(currentPassword, user.Password); err != nil || !ok {
+		return helpers.FieldErrors{"current_password": "current_password is incorrect"}
+	}
+
+	if err := passwords.Default().ValidateNew(s.authRepo, user, newPassword); err != nil {
+		return err
+	}
+
+	hashedPassword, err := hashers.Default().Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("could not hash password: %w", err)
+	}
+	if err := s.repo.UpdatePassword(user.ID, hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	passwords.Default().Remember(s.authRepo, user.ID, hashedPassword)
+
+	return s.revokeOtherSessions(user.ID, sessionID)
+}
+
+// revokeOtherSessions revokes every active session of the user except keepSessionID.
+func (s *userService) revokeOtherSessions(userID int64, keepSessionID int64) error {
+	sessions, err := s.authRepo.FindSessionsByUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if session.ID == keepSessionID {
+			continue
+		}
+		if err := s.authRepo.RevokeSession(session.ID); err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+	return nil
+}
+
+// RequestEmailChange checks the current password and emails a signed confirmation link to the
+// new address. The email is only changed once the link is confirmed with ConfirmEmailChange.
+// The link signs the user UUID, the current and the new email and an expiry, so it stops
+// working once it expires or the email changes in the meantime.
+func (s *userService) RequestEmailChange(ctx context.Context, userID int64, newEmail string, password string) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if ok, err := hashers.Default().Verify(password, user.Password); err != nil || !ok {
+		return helpers.FieldErrors{"password": "password is incorrect"}
+	}
+
+	if strings.EqualFold(newEmail, user.Email) {
+		return helpers.FieldErrors{"email": "email must be different from the current email"}
+	}
+	if _, err := s.repo.FindByEmail(newEmail); err == nil {
+		return ErrEmailTaken
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(s.emailVerificationConfig.LinkTTL).Unix(), 10)
+	signature := signers.Sign("change-email", user.UUID, strings.ToLower(user.Email), strings.ToLower(newEmail), expires)
+
+	query := url.Values{}
+	query.Set("user", user.UUID)
+	query.Set("email", newEmail)
+	query.Set("expires", expires)
+	query.Set("signature", signature)
+	link := s.emailVerificationConfig.ChangeURL + "?" + query.Encode()
+
+	err = s.mailer.Send(ctx, mailers.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm that you want to use this address for your account by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not request this, you can ignore this email.\n",
+			user.Username, link, s.emailVerificationConfig.LinkTTL,
+		),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+	return nil
+}
+
+// ConfirmEmailChange checks the signed link from RequestEmailChange and swaps the email of
+// the user. The previous address is notified about the change.
+func (s *userService) ConfirmEmailChange(ctx context.Context, userUUID string, newEmail string, expires string, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return fmt.Errorf("invalid or expired email change link")
+	}
+
+	user, err := s.repo.FindByUUID(userUUID)
+	if err != nil {
+		return fmt.Errorf("invalid or expired email change link")
+	}
+
+	if !signers.Verify(signature, "change-email", user.UUID, strings.ToLower(user.Email), strings.ToLower(newEmail), expires) {
+		return fmt.Errorf("invalid or expired email change link")
+	}
+
+	// Cek ulang, alamat baru bisa saja sudah dipakai user lain setelah link dikirim
+	if existing, err := s.repo.FindByEmail(newEmail); err == nil && existing.ID != user.ID {
+		return ErrEmailTaken
+	}
+
+	if err := s.repo.UpdateEmail(user.ID, newEmail); err != nil {
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+
+	err = s.mailer.Send(ctx, mailers.Message{
+		To:      user.Email,
+		Subject: "Your email address was changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe email address of your account was changed to %s. If you did not make this change, please contact support immediately.\n",
+			user.Username, newEmail,
+		),
+	})
+	if err != nil {
+		loggers.Log.Error("failed to send email change notice", map[string]interface{}{
+			"user_id": user.ID,
+			"error":   err.Error(),
+		})
+	}
+	return nil
+}
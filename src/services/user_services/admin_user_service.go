@@ -0,0 +1,447 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"gin/src/entities/audits"
+	"gin/src/entities/auth"
+	"gin/src/entities/users"
+	"gin/src/helpers"
+	"gin/src/repositories/auth_repositories"
+	repositories "gin/src/repositories/user_repositories"
+	"gin/src/services/attachment_services"
+	"gin/src/services/auth_services"
+	"gin/src/services/role_services"
+	"gin/src/utils/auditors"
+	"gin/src/utils/filters"
+	"gin/src/utils/hashers"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"gin/src/utils/passwords"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminUserInput holds the fields of a user created by an admin.
+type AdminUserInput struct {
+	Email         string
+	Username      string
+	Password      string
+	Roles         []string
+	EmailVerified bool
+}
+
+// AdminUserUpdate holds the fields an admin changes, nil fields are left as they are.
+type AdminUserUpdate struct {
+	Email         *string
+	Username      *string
+	Password      *string
+	EmailVerified *bool
+	Roles         *[]string
+}
+
+// AdminUserService is the back-office user management. Every change is written to the audit
+// log with the admin as actor. actorID is the admin making the request.
+type AdminUserService interface {
+	ListUsers(ctx *gin.Context, limit int, offset int) ([]users.AdminUserResponse, int64, error)
+	GetUser(ctx context.Context, uuid string) (*users.AdminUserResponse, error)
+	CreateUser(ctx context.Context, actorID int64, input AdminUserInput) (*users.AdminUserResponse, error)
+	UpdateUser(ctx context.Context, actorID int64, uuid string, input AdminUserUpdate) (*users.AdminUserResponse, error)
+	DeleteUser(ctx context.Context, actorID int64, uuid string) error
+	SuspendUser(ctx context.Context, actorID int64, uuid string, reason string, until *time.Time) (*users.AdminUserResponse, error)
+	BanUser(ctx context.Context, actorID int64, uuid string, reason string) (*users.AdminUserResponse, error)
+	ReactivateUser(ctx context.Context, actorID int64, uuid string) (*users.AdminUserResponse, error)
+	ForcePasswordReset(ctx context.Context, actorID int64, uuid string) error
+	RevokeSessions(ctx context.Context, actorID int64, uuid string) error
+	LoginHistory(ctx context.Context, uuid string) ([]auth.LoginEvent, error)
+}
+
+type adminUserService struct {
+	*userService
+	roles       role_services.RoleServiceInterface
+	authService auth_services.AuthServiceInterface
+}
+
+// NewAdminUserService creates the admin user management service. The auth service sends the
+// password reset links, the role service assigns roles and the attachment service removes the
+// files of deleted users.
+func NewAdminUserService(repo repositories.UserRepository, authRepo auth_repositories.AuthRepositoryInterface, mailer mailers.Mailer, roleService role_services.RoleServiceInterface, authService auth_services.AuthServiceInterface, attachmentService attachment_services.AttachmentServiceInterface) AdminUserService {
+	return &adminUserService{
+		userService: newUserService(repo, authRepo, mailer, attachmentService),
+		roles:       roleService,
+		authService: authService,
+	}
+}
+
+// ListUsers returns a page of users, filtered and ordered by the query parameters of the request.
+func (s *adminUserService) ListUsers(ctx *gin.Context, limit int, offset int) ([]users.AdminUserResponse, int64, error) {
+	if err := filters.AllowFields(ctx, users.AdminUserFilterFields...); err != nil {
+		return nil, 0, err
+	}
+
+	userList, err := s.repo.GetAll(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	total, err := helpers.CountModelWithFilters[users.User](ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	response := make([]users.AdminUserResponse, 0, len(userList))
+	for i := range userList {
+		response = append(response, s.toAdminResponse(&userList[i]))
+	}
+	return response, total, nil
+}
+
+func (s *adminUserService) GetUser(ctx context.Context, uuid string) (*users.AdminUserResponse, error) {
+	user, err := s.findUser(uuid)
+	if err != nil {
+		return nil, err
+	}
+	response := s.toAdminResponse(user)
+	return &response, nil
+}
+
+// CreateUser creates a user with the given roles. The password has to pass the password policy.
+func (s *adminUserService) CreateUser(ctx context.Context, actorID int64, input AdminUserInput) (*users.AdminUserResponse, error) {
+	if _, err := s.repo.FindByEmail(input.Email); err == nil {
+		return nil, ErrEmailTaken
+	}
+	if _, err := s.repo.FindByUsername(input.Username); err == nil {
+		return nil, ErrUsernameTaken
+	}
+	if err := passwords.Default().Validate(input.Password, passwords.Input{Email: input.Email, Username: input.Username}); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := hashers.Default().Hash(input.Password)
+	if err != nil {
+		return nil, fmt.Errorf("could not hash password: %w", err)
+	}
+
+	user := users.User{
+		Email:    input.Email,
+		Username: input.Username,
+		Password: hashedPassword,
+		Status:   users.StatusActive,
+	}
+	if input.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	if err := s.repo.Create(&user); err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	passwords.Default().Remember(s.authRepo, user.ID, hashedPassword)
+
+	for _, role := range input.Roles {
+		if err := s.roles.AssignRole(user.ID, role); err != nil {
+			return nil, fmt.Errorf("failed to assign role %s: %w", role, err)
+		}
+	}
+
+	s.audit(ctx, audits.EventAdminUserCreated, actorID, user.ID, map[string]interface{}{"roles": input.Roles})
+	return s.GetUser(ctx, user.UUID)
+}
+
+// UpdateUser changes the given fields of the user. A new password has to pass the password
+// policy and signs the user out everywhere, the roles replace the current roles.
+func (s *adminUserService) UpdateUser(ctx context.Context, actorID int64, uuid string, input AdminUserUpdate) (*users.AdminUserResponse, error) {
+	user, err := s.findUser(uuid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeTarget(actorID, user); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{}
+	var changed []string
+
+	if input.Email != nil && *input.Email != user.Email {
+		if existing, err := s.repo.FindByEmail(*input.Email); err == nil && existing.ID != user.ID {
+			return nil, ErrEmailTaken
+		}
+		fields["email"] = *input.Email
+		changed = append(changed, "email")
+	}
+	if input.Username != nil && *input.Username != user.Username {
+		if existing, err := s.repo.FindByUsername(*input.Username); err == nil && existing.ID != user.ID {
+			return nil, ErrUsernameTaken
+		}
+		fields["username"] = *input.Username
+		changed = append(changed, "username")
+	}
+	if input.EmailVerified != nil && *input.EmailVerified != user.IsEmailVerified() {
+		if *input.EmailVerified {
+			fields["email_verified_at"] = time.Now()
+		} else {
+			fields["email_verified_at"] = nil
+		}
+		changed = append(changed, "email_verified")
+	}
+
+	var hashedPassword string
+	if input.Password != nil {
+		if err := passwords.Default().ValidateNew(s.authRepo, user, *input.Password); err != nil {
+			return nil, err
+		}
+		if hashedPassword, err = hashers.Default().Hash(*input.Password); err != nil {
+			return nil, fmt.Errorf("could not hash password: %w", err)
+		}
+		fields["password"] = hashedPassword
+		changed = append(changed, "password")
+	}
+
+	if len(fields) > 0 {
+		if err := s.repo.Update(user.ID, fields); err != nil {
+			return nil, fmt.Errorf("failed to update user: %w", err)
+		}
+	}
+
+	if hashedPassword != "" {
+		passwords.Default().Remember(s.authRepo, user.ID, hashedPassword)
+		if err := s.authRepo.RevokeAllUserTokens(user.ID); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	if input.Roles != nil {
+		if err := s.syncRoles(user.ID, *input.Roles); err != nil {
+			return nil, err
+		}
+		changed = append(changed, "roles")
+	}
+
+	if len(changed) > 0 {
+		s.audit(ctx, audits.EventAdminUserUpdated, actorID, user.ID, map[string]interface{}{"fields": changed})
+	}
+	return s.GetUser(ctx, user.UUID)
+}
+
+// syncRoles gives the user exactly the given roles.
+func (s *adminUserService) syncRoles(userID int64, roleNames []string) error {
+	current, err := s.roles.UserRoles(userID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch roles: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(roleNames))
+	for _, role := range roleNames {
+		wanted[role] = true
+		if err := s.roles.AssignRole(userID, role); err != nil {
+			return fmt.Errorf("failed to assign role %s: %w", role, err)
+		}
+	}
+	for _, role := range current {
+		if !wanted[role] {
+			if err := s.roles.RemoveRole(userID, role); err != nil {
+				return fmt.Errorf("failed to remove role %s: %w", role, err)
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteUser anonymises the user right away, without the grace period of a self-service deletion.
+func (s *adminUserService) DeleteUser(ctx context.Context, actorID int64, uuid string) error {
+	user, err := s.findUser(uuid)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeTarget(actorID, user); err != nil {
+		return err
+	}
+
+	// Penghapusan dijadwalkan saat ini juga lalu langsung dijalankan lewat jalur yang sama dengan purge
+	now := time.Now()
+	if err := s.repo.ScheduleDeletion(user.ID, now); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	anonymized, err := s.anonymizeUser(user, now)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	if !anonymized {
+		return fmt.Errorf("failed to delete user: the account is no longer scheduled for deletion")
+	}
+	s.roles.Forget(user.ID)
+
+	s.audit(ctx, audits.EventAdminUserDeleted, actorID, user.ID, nil)
+	return nil
+}
+
+// SuspendUser suspends the user until the given time, or until reactivated when until is nil.
+// The access and refresh tokens of the user are revoked right away.
+func (s *adminUserService) SuspendUser(ctx context.Context, actorID int64, uuid string, reason string, until *time.Time) (*users.AdminUserResponse, error) {
+	if until != nil && !until.After(time.Now()) {
+		return nil, helpers.FieldErrors{"until": "until must be in the future"}
+	}
+	return s.changeStatus(ctx, actorID, uuid, users.StatusSuspended, reason, until, audits.EventAdminUserSuspended)
+}
+
+// BanUser bans the user permanently, until reactivated. The tokens of the user are revoked right away.
+func (s *adminUserService) BanUser(ctx context.Context, actorID int64, uuid string, reason string) (*users.AdminUserResponse, error) {
+	return s.changeStatus(ctx, actorID, uuid, users.StatusBanned, reason, nil, audits.EventAdminUserBanned)
+}
+
+// ReactivateUser lifts a suspension or ban.
+func (s *adminUserService) ReactivateUser(ctx context.Context, actorID int64, uuid string) (*users.AdminUserResponse, error) {
+	return s.changeStatus(ctx, actorID, uuid, users.StatusActive, "", nil, audits.EventAdminUserReactivated)
+}
+
+func (s *adminUserService) changeStatus(ctx context.Context, actorID int64, uuid string, status string, reason string, until *time.Time, event string) (*users.AdminUserResponse, error) {
+	user, err := s.findUser(uuid)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeTarget(actorID, user); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{
+		"status":          status,
+		"status_reason":   reason,
+		"suspended_until": nil,
+	}
+	if until != nil {
+		fields["suspended_until"] = *until
+	}
+	if err := s.repo.Update(user.ID, fields); err != nil {
+		return nil, fmt.Errorf("failed to update user status: %w", err)
+	}
+
+	// Suspend / ban langsung mengeluarkan user dari semua sesi
+	if status != users.StatusActive {
+		if err := s.authRepo.RevokeAllUserTokens(user.ID); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+	}
+
+	s.audit(ctx, event, actorID, user.ID, map[string]interface{}{"reason": reason, "until": until})
+	return s.GetUser(ctx, user.UUID)
+}
+
+// ForcePasswordReset clears the password of the user, signs the user out everywhere and
+// emails a password reset link, so the user has to choose a new password to sign in again.
+func (s *adminUserService) ForcePasswordReset(ctx context.Context, actorID int64, uuid string) error {
+	user, err := s.findUser(uuid)
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeTarget(actorID, user); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(user.ID, ""); err != nil {
+		return fmt.Errorf("failed to clear password: %w", err)
+	}
+	if err := s.authRepo.RevokeAllUserTokens(user.ID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := s.authService.ForgotPassword(ctx, user.Email); err != nil {
+		return fmt.Errorf("failed to send reset link: %w", err)
+	}
+
+	s.audit(ctx, audits.EventAdminPasswordReset, actorID, user.ID, nil)
+	return nil
+}
+
+// RevokeSessions signs the user out of every session.
+func (s *adminUserService) RevokeSessions(ctx context.Context, actorID int64, uuid string) error {
+	user, err := s.findUser(uuid)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authRepo.RevokeAllUserTokens(user.ID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	s.audit(ctx, audits.EventAdminSessionsRevoked, actorID, user.ID, nil)
+	return nil
+}
+
+// LoginHistory returns the successful and failed sign-ins of the user, the latest first.
+func (s *adminUserService) LoginHistory(ctx context.Context, uuid string) ([]auth.LoginEvent, error) {
+	user, err := s.findUser(uuid)
+	if err != nil {
+		return nil, err
+	}
+	return s.authRepo.FindLoginEventsByUserID(user.ID)
+}
+
+// authorizeTarget refuses changes to the own account and to users holding permissions the
+// actor lacks, so a limited admin cannot take over a more privileged account.
+func (s *adminUserService) authorizeTarget(actorID int64, user *users.User) error {
+	if user.ID == actorID {
+		return ErrActingOnSelf
+	}
+	covered, err := s.roles.CoversPermissions(actorID, user.ID)
+	if err != nil {
+		return fmt.Errorf("could not check permissions: %w", err)
+	}
+	if !covered {
+		return ErrPrivilegedUser
+	}
+	return nil
+}
+
+// findUser looks a user up by UUID, anonymised users count as not found.
+func (s *adminUserService) findUser(uuid string) (*users.User, error) {
+	user, err := s.repo.FindByUUID(uuid)
+	if err != nil || user.IsAnonymized() {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *adminUserService) toAdminResponse(user *users.User) users.AdminUserResponse {
+	roleNames, err := s.roles.UserRoles(user.ID)
+	if err != nil || roleNames == nil {
+		roleNames = []string{}
+	}
+
+	response := users.AdminUserResponse{
+		UUID:           user.UUID,
+		ID:             user.ID,
+		Email:          user.Email,
+		Username:       user.Username,
+		Avatar:         user.Avatar.URLs(s.storage.URL),
+		EmailVerified:  user.IsEmailVerified(),
+		Status:         user.CurrentStatus(time.Now()),
+		StatusReason:   user.StatusReason,
+		SuspendedUntil: user.SuspendedUntil,
+		Roles:          roleNames,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
+	}
+	if user.DeletionPending {
+		response.DeletionScheduledAt = user.DeletionScheduledAt
+	}
+	return response
+}
+
+// audit writes an admin action to the audit log. Failures are only logged, the action itself
+// already happened.
+func (s *adminUserService) audit(ctx context.Context, event string, actorID int64, userID int64, details map[string]interface{}) {
+	err := auditors.Default().Record(ctx, audits.AuditLog{
+		Event:   event,
+		ActorID: actorID,
+		UserID:  userID,
+		Details: auditors.Details(details),
+	})
+	if err != nil {
+		loggers.Log.Error("failed to record admin action", map[string]interface{}{
+			"event":    event,
+			"admin_id": actorID,
+			"user_id":  userID,
+			"error":    err.Error(),
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gin/src/entities/users"
+	"gin/src/utils/loggers"
+	"gin/src/utils/storages"
+	"io"
+	"path"
+	"time"
+)
+
+// exportPrefix is the storage prefix of the data export archives. It is private, so an
+// archive is only served by /files through the signed link of its export.
+const exportPrefix = storages.PrivatePrefix + "exports/"
+
+// RequestDataExport returns the current data export of the user. A new export is started in
+// the background when there is none yet, or the last one failed or expired. Once it is ready
+// the response carries a signed link to the archive that works until the export expires.
+func (s *userService) RequestDataExport(ctx context.Context, userID int64) (*users.DataExportResponse, error) {
+	now := time.Now()
+
+	export, err := s.repo.FindLatestDataExport(userID)
+	if err != nil || export.Status == users.ExportFailed || export.IsExpired(now) || s.isStaleExport(export, now) {
+		export = &users.DataExport{UserID: userID, Status: users.ExportPending}
+		if err := s.repo.CreateDataExport(export); err != nil {
+			return nil, fmt.Errorf("failed to start data export: %w", err)
+		}
+		go s.buildDataExport(*export)
+	}
+
+	response := &users.DataExportResponse{
+		UUID:      export.UUID,
+		Status:    export.Status,
+		ExpiresAt: export.ExpiresAt,
+		CreatedAt: export.CreatedAt,
+	}
+	if export.Status == users.ExportReady && export.ExpiresAt != nil {
+		response.DownloadURL = storages.SignedURL(export.FilePath, time.Until(*export.ExpiresAt))
+	}
+	return response, nil
+}
+
+// isStaleExport detects exports that never finished, for example because the application
+// restarted while building them.
+func (s *userService) isStaleExport(export *users.DataExport, now time.Time) bool {
+	unfinished := export.Status == users.ExportPending || export.Status == users.ExportProcessing
+	return unfinished && now.Sub(export.CreatedAt) > time.Hour
+}
+
+// buildDataExport stores the archive of the export and records the outcome. It runs in its
+// own goroutine, so failures are saved on the export and written to the log.
+func (s *userService) buildDataExport(export users.DataExport) {
+	_ = s.repo.UpdateDataExport(export.ID, map[string]interface{}{"status": users.ExportProcessing})
+
+	key := exportPrefix + export.UUID + ".zip"
+	if err := s.storeDataExport(export.UserID, key); err != nil {
+		_ = s.storage.Delete(context.Background(), key)
+		loggers.Log.Error("failed to build data export", map[string]interface{}{
+			"user_id": export.UserID,
+			"export":  export.UUID,
+			"error":   err.Error(),
+		})
+		_ = s.repo.UpdateDataExport(export.ID, map[string]interface{}{
+			"status": users.ExportFailed,
+			"error":  "failed to build the export, please request a new one",
+		})
+		return
+	}
+
+	now := time.Now()
+	_ = s.repo.UpdateDataExport(export.ID, map[string]interface{}{
+		"status":       users.ExportReady,
+		"file_path":    key,
+		"completed_at": now,
+		"expires_at":   now.Add(s.accountConfig.ExportTTL),
+	})
+}
+
+// storeDataExport streams the archive of writeDataExport into the storage under key.
+func (s *userService) storeDataExport(userID int64, key string) error {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(s.writeDataExport(userID, writer))
+	}()
+
+	err := s.storage.Put(context.Background(), key, reader, "application/zip")
+	// Penulis berhenti kalau Put gagal di tengah jalan
+	reader.CloseWithError(errors.New("data export upload stopped"))
+	return err
+}
+
+// writeDataExport writes a ZIP with profile.json, sessions.json, audit_logs.json and
+// attachments.json, and the files of the user under files/: the avatar and every attachment
+// in files/attachments/.
+func (s *userService) writeDataExport(userID int64, file io.Writer) error {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	sessions, err := s.repo.FindAllSessions(userID)
+	if err != nil {
+		return err
+	}
+	auditLogs, err := s.repo.FindAuditLogs(userID)
+	if err != nil {
+		return err
+	}
+	userAttachments, err := s.attachmentService.UserAttachments(context.Background(), userID)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(file)
+
+	profile := map[string]interface{}{
+		"uuid":              user.UUID,
+		"email":             user.Email,
+		"username":          user.Username,
+		"avatar":            user.Avatar.URLs(s.storage.URL),
+		"email_verified_at": user.EmailVerifiedAt,
+		"created_at":        user.CreatedAt,
+		"updated_at":        user.UpdatedAt,
+	}
+	attachmentEntries := make([]map[string]interface{}, 0, len(userAttachments))
+	for _, attachment := range userAttachments {
+		attachmentEntries = append(attachmentEntries, map[string]interface{}{
+			"uuid":       attachment.UUID,
+			"owner_type": attachment.OwnerType,
+			"mime_type":  attachment.MimeType,
+			"size":       attachment.Size,
+			"checksum":   attachment.Checksum,
+			"metadata":   attachment.Metadata,
+			"file":       attachmentEntryName(attachment.UUID, attachment.StorageKey),
+			"created_at": attachment.CreatedAt,
+		})
+	}
+	entries := map[string]interface{}{
+		"profile.json":     profile,
+		"sessions.json":    sessions,
+		"audit_logs.json":  auditLogs,
+		"attachments.json": attachmentEntries,
+	}
+	for _, name := range []string{"profile.json", "sessions.json", "audit_logs.json", "attachments.json"} {
+		if err := writeJSONEntry(archive, name, entries[name]); err != nil {
+			return err
+		}
+	}
+
+	if original := user.Avatar.Original(); original != "" {
+		if err := s.writeFileEntry(archive, "files/avatar"+path.Ext(original), original); err != nil {
+			return err
+		}
+	}
+	for _, attachment := range userAttachments {
+		if err := s.writeFileEntry(archive, attachmentEntryName(attachment.UUID, attachment.StorageKey), attachment.StorageKey); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// attachmentEntryName names the file of an attachment in the archive after its UUID, the
+// original filename is kept in attachments.json.
+func attachmentEntryName(uuid string, storageKey string) string {
+	return "files/attachments/" + uuid + path.Ext(storageKey)
+}
+
+func writeJSONEntry(archive *zip.Writer, name string, data interface{}) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+// writeFileEntry copies an uploaded file into the archive, a missing file is skipped.
+func (s *userService) writeFileEntry(archive *zip.Writer, name string, key string) error {
+	source, _, err := s.storage.Get(context.Background(), key)
+	if errors.Is(err, storages.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, source)
+	return err
+}
+
+// PurgeExpiredExports deletes the archives and records of exports that can no longer be
+// downloaded. It is meant to run on a schedule.
+func (s *userService) PurgeExpiredExports(ctx context.Context) error {
+	exports, err := s.repo.FindDataExportsByStatus(users.ExportReady)
+	if err != nil {
+		return fmt.Errorf("failed to fetch data exports: %w", err)
+	}
+
+	now := time.Now()
+	var errs []error
+	for i := range exports {
+		if exports[i].IsExpired(now) {
+			if err := s.deleteDataExport(&exports[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *userService) deleteDataExport(export *users.DataExport) error {
+	if export.FilePath != "" {
+		if err := s.storage.Delete(context.Background(), export.FilePath); err != nil {
+			return fmt.Errorf("failed to delete export file: %w", err)
+		}
+	}
+	return s.repo.DeleteDataExport(export.ID)
+}
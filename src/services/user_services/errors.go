@@ -0,0 +1,12 @@
+package services
+
+import "errors"
+
+var (
+	ErrUsernameTaken = errors.New("username already in use")
+	ErrEmailTaken    = errors.New("email already in use")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrActingOnSelf  = errors.New("admins cannot change, suspend, ban or delete their own account")
+	// ErrPrivilegedUser is returned when an admin acts on a user holding permissions the admin lacks.
+	ErrPrivilegedUser = errors.New("this user holds permissions you do not have")
+)
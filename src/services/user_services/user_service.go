@@ -1,28 +1,69 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"gin/src/configs/security"
 	"gin/src/entities/users"
+	"gin/src/repositories/auth_repositories"
 	repositories "gin/src/repositories/user_repositories"
-	"gin/src/utils/uploaders"
-	"mime/multipart"
+	"gin/src/services/attachment_services"
+	"gin/src/utils/images"
+	"gin/src/utils/loggers"
+	"gin/src/utils/mailers"
+	"gin/src/utils/storages"
+	"io"
+	"path"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type UserService interface {
 	GetPaginatedUsers(ctx *gin.Context, limit int, offset int) ([]users.User, int64, error)
-	UpdateAvatar(ctx context.Context, userID int64, avatarURL string) error
-	UploadAvatar(ctx *gin.Context, userID int64, file multipart.File, folder string) (string, error)
+	UpdateAvatar(ctx context.Context, userID int64, avatar users.AvatarVariants) error
+	UploadAvatar(ctx context.Context, userID int64, file io.Reader, folder string) (users.AvatarVariants, error)
+	UpdateProfile(ctx context.Context, userID int64, username string) (*users.User, error)
+	ChangePassword(ctx context.Context, userID int64, sessionID int64, currentPassword string, newPassword string) error
+	RequestEmailChange(ctx context.Context, userID int64, newEmail string, password string) error
+	ConfirmEmailChange(ctx context.Context, userUUID string, newEmail string, expires string, signature string) error
+	DeleteAccount(ctx context.Context, userID int64, password string) (*users.AccountDeletionResponse, error)
+	PurgeDeletedAccounts(ctx context.Context) error
+	RequestDataExport(ctx context.Context, userID int64) (*users.DataExportResponse, error)
+	PurgeExpiredExports(ctx context.Context) error
+	StoredFileKeys(ctx context.Context) ([]string, error)
 }
 
 type userService struct {
-	repo repositories.UserRepository
+	repo                    repositories.UserRepository
+	authRepo                auth_repositories.AuthRepositoryInterface
+	mailer                  mailers.Mailer
+	emailVerificationConfig security.EmailVerificationConfig
+	accountConfig           security.AccountConfig
+	avatarConfig            security.AvatarConfig
+	storage                 storages.Storage
+	attachmentService       attachment_services.AttachmentServiceInterface
 }
 
-func NewUserService(repo repositories.UserRepository) UserService {
-	return &userService{repo}
+// NewUserService creates the user service. The auth repository is used for the password
+// history and to revoke sessions, the mailer sends the email change links and the attachment
+// service provides the files of the user for the data export and the account deletion.
+func NewUserService(repo repositories.UserRepository, authRepo auth_repositories.AuthRepositoryInterface, mailer mailers.Mailer, attachmentService attachment_services.AttachmentServiceInterface) UserService {
+	return newUserService(repo, authRepo, mailer, attachmentService)
+}
+
+func newUserService(repo repositories.UserRepository, authRepo auth_repositories.AuthRepositoryInterface, mailer mailers.Mailer, attachmentService attachment_services.AttachmentServiceInterface) *userService {
+	return &userService{
+		repo:                    repo,
+		authRepo:                authRepo,
+		mailer:                  mailer,
+		attachmentService:       attachmentService,
+		emailVerificationConfig: security.LoadEmailVerificationConfig(),
+		accountConfig:           security.LoadAccountConfig(),
+		avatarConfig:            security.LoadAvatarConfig(),
+		storage:                 storages.Default(),
+	}
 }
 
 func (s *userService) GetPaginatedUsers(ctx *gin.Context, limit int, offset int) ([]users.User, int64, error) {
@@ -39,31 +80,108 @@ func (s *userService) GetPaginatedUsers(ctx *gin.Context, limit int, offset int)
 	return usersList, total, nil
 }
 
-func (s *userService) UpdateAvatar(ctx context.Context, userID int64, avatarURL string) error {
-	// Validasi avatar URL jika perlu
-	if avatarURL == "" {
+func (s *userService) UpdateAvatar(ctx context.Context, userID int64, avatar users.AvatarVariants) error {
+	// Validasi avatar, minimal harus ada varian original
+	if avatar.Original() == "" {
 		return fmt.Errorf("avatar URL cannot be empty")
 	}
 
 	// Update avatar via repository
-	if err := s.repo.UpdateAvatar(ctx, userID, avatarURL); err != nil {
+	if err := s.repo.UpdateAvatar(ctx, userID, avatar); err != nil {
 		return fmt.Errorf("failed to update avatar: %w", err)
 	}
 
 	return nil
 }
 
-func (service *userService) UploadAvatar(ctx *gin.Context, userID int64, file multipart.File, folder string) (string, error) {
-	avatarURL, err := uploaders.UploadFile(ctx.Request, folder)
+// avatarSizes are the variants rendered for every avatar upload.
+var avatarSizes = []images.Size{
+	{Name: users.AvatarOriginal, Width: 1024, Height: 1024},
+	{Name: users.AvatarLarge, Width: 256, Height: 256, Crop: true},
+	{Name: users.AvatarMedium, Width: 128, Height: 128, Crop: true},
+	{Name: users.AvatarSmall, Width: 64, Height: 64, Crop: true},
+}
 
+// UploadAvatar validates the uploaded image, puts the re-encoded variants into the storage
+// (STORAGE_DRIVER) and replaces the previous avatar. It returns the URLs of the variants.
+// Content that is not a JPEG, PNG, WebP or GIF image or exceeds the AVATAR_MAX_* limits is
+// refused with one of the images errors.
+func (service *userService) UploadAvatar(ctx context.Context, userID int64, file io.Reader, folder string) (users.AvatarVariants, error) {
+	user, err := service.repo.FindByID(userID)
 	if err != nil {
-		return "", fmt.Errorf("failed to upload avatar: %w", err)
+		return nil, fmt.Errorf("failed to find user by ID: %w", err)
 	}
 
-	if err := service.UpdateAvatar(ctx, userID, avatarURL); err != nil {
-		return "", fmt.Errorf("failed to update avatar URL in database: %w", err)
+	// Baca satu byte lebih dari batas supaya file yang terlalu besar bisa dikenali
+	data, err := io.ReadAll(io.LimitReader(file, service.avatarConfig.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
 	}
 
-	// Kembalikan URL avatar yang telah berhasil disimpan
-	return avatarURL, nil
+	variants, err := images.Process(data, images.Limits{
+		MaxBytes:  service.avatarConfig.MaxBytes,
+		MaxWidth:  service.avatarConfig.MaxWidth,
+		MaxHeight: service.avatarConfig.MaxHeight,
+	}, avatarSizes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid avatar: %w", err)
+	}
+
+	// Nama file dibuat server, ekstensi mengikuti format hasil encode ulang
+	baseName := uuid.New().String()
+	avatar := users.AvatarVariants{}
+	for _, variant := range variants {
+		key := path.Join(folder, baseName+"-"+variant.Name+variant.Extension)
+		if err := service.storage.Put(ctx, key, bytes.NewReader(variant.Data), variant.ContentType); err != nil {
+			service.removeFiles(ctx, avatar.Keys())
+			return nil, fmt.Errorf("failed to upload avatar: %w", err)
+		}
+		avatar[variant.Name] = key
+	}
+
+	if err := service.UpdateAvatar(ctx, userID, avatar); err != nil {
+		service.removeFiles(ctx, avatar.Keys())
+		return nil, fmt.Errorf("failed to update avatar URL in database: %w", err)
+	}
+
+	// File avatar lama sudah tidak dipakai lagi
+	service.removeFiles(ctx, user.Avatar.Keys())
+
+	// Kembalikan URL varian avatar yang telah berhasil disimpan
+	return avatar.URLs(service.storage.URL), nil
+}
+
+// StoredFileKeys returns the keys of all avatar variants and of the data export archives that
+// are ready, a reference source for the storages.Janitor.
+func (s *userService) StoredFileKeys(ctx context.Context) ([]string, error) {
+	list, err := s.repo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch users: %w", err)
+	}
+	var keys []string
+	for _, user := range list {
+		keys = append(keys, user.Avatar.Keys()...)
+	}
+
+	exports, err := s.repo.FindDataExportsByStatus(users.ExportReady)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch data exports: %w", err)
+	}
+	for _, export := range exports {
+		keys = append(keys, export.FilePath)
+	}
+	return keys, nil
+}
+
+// removeFiles deletes stored files that are no longer referenced. Failures are only logged,
+// the database already points to the new files.
+func (s *userService) removeFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			loggers.Log.Error("failed to delete file", map[string]interface{}{
+				"key":   key,
+				"error": err.Error(),
+			})
+		}
+	}
 }
@@ -0,0 +1,56 @@
+package auditors
+
+import (
+	"context"
+	"encoding/json"
+	"gin/src/entities/audits"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Auditor writes audit entries to a dedicated sink, separate from the application log.
+// Implementations must be safe for concurrent use.
+type Auditor interface {
+	Record(ctx context.Context, entry audits.AuditLog) error
+}
+
+// NewAuditor returns the Auditor selected by the AUDIT_DRIVER environment variable:
+//   - file: appends the entries as JSON lines to src/storage/logs/audit.
+//   - memory: keeps the entries in memory, useful for tests.
+//   - database (default): inserts the entries into the audit_logs table.
+func NewAuditor() Auditor {
+	switch strings.ToLower(os.Getenv("AUDIT_DRIVER")) {
+	case "file":
+		return NewFileAuditor("src/storage/logs/audit")
+	case "memory":
+		return NewMemoryAuditor()
+	default:
+		return NewDatabaseAuditor()
+	}
+}
+
+var (
+	defaultAuditor Auditor
+	defaultOnce    sync.Once
+)
+
+// Default returns the shared Auditor, created from the environment on first use.
+func Default() Auditor {
+	defaultOnce.Do(func() {
+		defaultAuditor = NewAuditor()
+	})
+	return defaultAuditor
+}
+
+// Details encodes extra data for the Details field of an entry.
+func Details(values map[string]interface{}) string {
+	if len(values) == 0 {
+		return ""
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,18 @@
+package auditors
+
+import (
+	"context"
+	"gin/src/entities/audits"
+	"gin/src/helpers"
+)
+
+// DatabaseAuditor stores every entry in the audit_logs table.
+type DatabaseAuditor struct{}
+
+func NewDatabaseAuditor() *DatabaseAuditor {
+	return &DatabaseAuditor{}
+}
+
+func (a *DatabaseAuditor) Record(ctx context.Context, entry audits.AuditLog) error {
+	return helpers.InsertModel(&entry)
+}
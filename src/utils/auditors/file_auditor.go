@@ -0,0 +1,50 @@
+package auditors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gin/src/entities/audits"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileAuditor appends every entry as a JSON line to a daily file in its folder.
+type FileAuditor struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFileAuditor(dir string) *FileAuditor {
+	return &FileAuditor{dir: dir}
+}
+
+func (a *FileAuditor) Record(ctx context.Context, entry audits.AuditLog) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(a.dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create audit folder: %w", err)
+	}
+
+	filePath := filepath.Join(a.dir, entry.CreatedAt.Format("2006-01-02")+".log")
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
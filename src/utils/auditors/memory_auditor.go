@@ -0,0 +1,39 @@
+package auditors
+
+import (
+	"context"
+	"gin/src/entities/audits"
+	"sync"
+)
+
+// MemoryAuditor keeps every entry in memory. It is meant for tests,
+// the captured entries can be inspected with Entries and cleared with Reset.
+type MemoryAuditor struct {
+	mu      sync.Mutex
+	entries []audits.AuditLog
+}
+
+func NewMemoryAuditor() *MemoryAuditor {
+	return &MemoryAuditor{}
+}
+
+func (a *MemoryAuditor) Record(ctx context.Context, entry audits.AuditLog) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, entry)
+	return nil
+}
+
+// Entries returns a copy of the captured entries in the order they were recorded.
+func (a *MemoryAuditor) Entries() []audits.AuditLog {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]audits.AuditLog(nil), a.entries...)
+}
+
+// Reset removes all captured entries.
+func (a *MemoryAuditor) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = nil
+}
@@ -0,0 +1,71 @@
+package crypts
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"os"
+)
+
+// encryptionKey derives a 256-bit AES key from APP_KEY, falling back to JWT_SECRET.
+func encryptionKey() []byte {
+	secret := os.Getenv("APP_KEY")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return sum[:]
+}
+
+// Encrypt encrypts the plaintext with AES-256-GCM and returns the nonce and ciphertext
+// encoded as base64. It is meant for secrets that must be readable again later, like TOTP
+// secrets; values that only need to be compared should be hashed instead.
+func Encrypt(plaintext string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt. It returns an error if the value was not encrypted with the
+// current key or has been tampered with.
+func Decrypt(encoded string) (string, error) {
+	gcm, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func newGCM() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(encryptionKey())
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
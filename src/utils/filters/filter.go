@@ -20,6 +20,40 @@ func parseFilterParam(param string) (field string, operator string) {
 	return field, operator
 }
 
+// AllowFields refuses filter and order_by query parameters on fields outside the given list.
+// Call it before BuildFilters on endpoints whose model holds columns that must not be
+// queried, for example users.password. The field names are also the only part of a filter
+// that ends up in the query unescaped.
+func AllowFields(ctx *gin.Context, fields ...string) error {
+	allowed := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		allowed[field] = true
+	}
+
+	for param := range ctx.Request.URL.Query() {
+		if !strings.Contains(param, "[") {
+			continue
+		}
+		if field, _ := parseFilterParam(param); !allowed[field] {
+			return fmt.Errorf("filtering on %q is not allowed", param)
+		}
+	}
+
+	if orderBy := ctx.Query("order_by"); orderBy != "" {
+		parts := strings.Split(orderBy, ",")
+		if !allowed[strings.TrimSpace(parts[0])] {
+			return fmt.Errorf("ordering by %q is not allowed", parts[0])
+		}
+		if len(parts) == 2 {
+			direction := strings.ToLower(strings.TrimSpace(parts[1]))
+			if direction != "asc" && direction != "desc" {
+				return fmt.Errorf("invalid order direction: %s", parts[1])
+			}
+		}
+	}
+	return nil
+}
+
 func BuildFilters(ctx *gin.Context, useGORM bool) (string, []interface{}, error) {
 	var filters []string
 	var args []interface{}
@@ -0,0 +1,84 @@
+package hashers
+
+import (
+	"fmt"
+	"gin/src/configs/security"
+	"strings"
+	"sync"
+)
+
+// PasswordHasher hashes passwords into self-describing PHC format strings
+// ($argon2id$v=19$m=...,t=...,p=...$salt$hash, or the $2a$ format of bcrypt).
+type PasswordHasher interface {
+	// Hash returns the encoded hash of the password.
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash.
+	Verify(password string, encoded string) (bool, error)
+	// NeedsRehash reports whether the encoded hash was made with another algorithm or other costs
+	// than the hasher would use now.
+	NeedsRehash(encoded string) bool
+}
+
+// Hasher hashes new passwords with the configured algorithm and verifies hashes of every supported
+// algorithm, so existing hashes keep working after the algorithm or the costs change.
+type Hasher struct {
+	current  PasswordHasher
+	argon2id *Argon2idHasher
+	bcrypt   *BcryptHasher
+}
+
+func NewHasher(config security.PasswordHashConfig) *Hasher {
+	hasher := &Hasher{
+		argon2id: NewArgon2idHasher(uint32(config.Argon2Memory), uint32(config.Argon2Iterations), uint8(config.Argon2Parallelism)),
+		bcrypt:   NewBcryptHasher(config.BcryptCost),
+	}
+	hasher.current = hasher.argon2id
+	if config.Algorithm == security.HashBcrypt {
+		hasher.current = hasher.bcrypt
+	}
+	return hasher
+}
+
+var (
+	defaultHasher *Hasher
+	defaultOnce   sync.Once
+)
+
+// Default returns the shared Hasher, configured from the environment on first use.
+func Default() *Hasher {
+	defaultOnce.Do(func() {
+		defaultHasher = NewHasher(security.LoadPasswordHashConfig())
+	})
+	return defaultHasher
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+func (h *Hasher) Verify(password string, encoded string) (bool, error) {
+	algorithm, err := h.algorithmFor(encoded)
+	if err != nil {
+		return false, err
+	}
+	return algorithm.Verify(password, encoded)
+}
+
+func (h *Hasher) NeedsRehash(encoded string) bool {
+	algorithm, err := h.algorithmFor(encoded)
+	if err != nil || algorithm != h.current {
+		return true
+	}
+	return h.current.NeedsRehash(encoded)
+}
+
+func (h *Hasher) algorithmFor(encoded string) (PasswordHasher, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return h.argon2id, nil
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return h.bcrypt, nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash format")
+	}
+}
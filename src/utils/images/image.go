@@ -0,0 +1,152 @@
+package images
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	// ErrUnsupportedType is returned when the content is not a JPEG, PNG, WebP or GIF image,
+	// whatever the file name or the Content-Type header claims.
+	ErrUnsupportedType = errors.New("only JPEG, PNG, WebP and GIF images are allowed")
+	// ErrTooLarge is returned when the file is bigger than Limits.MaxBytes.
+	ErrTooLarge = errors.New("image file is too large")
+	// ErrDimensionsTooLarge is returned when the width or height exceeds the limits.
+	ErrDimensionsTooLarge = errors.New("image dimensions are too large")
+	// ErrInvalidImage is returned when the content looks like an image but cannot be decoded.
+	ErrInvalidImage = errors.New("image could not be decoded")
+)
+
+// formats maps the sniffed content type to the name image.DecodeConfig reports for it.
+var formats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// Limits bounds what Process accepts. The dimensions are checked from the image header
+// before the pixels are decoded, so a small file cannot expand into a huge bitmap.
+type Limits struct {
+	MaxBytes  int64
+	MaxWidth  int
+	MaxHeight int
+}
+
+// Size describes a variant to render. Without Crop the image is scaled down to fit within
+// Width x Height, with Crop it is scaled to fill the box and the overflow is cut off evenly.
+// Images are never scaled up.
+type Size struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+// Variant is a rendered size of the image, re-encoded without any of the original metadata.
+type Variant struct {
+	Name        string
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// Process validates the uploaded bytes and renders every size. JPEG sources are written as
+// JPEG, the other formats as PNG so transparency survives; only the first frame of an
+// animated GIF is kept. The EXIF orientation of JPEG files is applied to the pixels, the
+// EXIF data itself (camera, GPS position, ...) is dropped by the re-encoding.
+func Process(data []byte, limits Limits, sizes []Size) ([]Variant, error) {
+	if limits.MaxBytes > 0 && int64(len(data)) > limits.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	format, ok := formats[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+
+	config, decodedFormat, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || decodedFormat != format || config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrInvalidImage
+	}
+	if (limits.MaxWidth > 0 && config.Width > limits.MaxWidth) || (limits.MaxHeight > 0 && config.Height > limits.MaxHeight) {
+		return nil, ErrDimensionsTooLarge
+	}
+
+	source, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if format == "jpeg" {
+		source = orient(source, jpegOrientation(data))
+	}
+
+	variants := make([]Variant, 0, len(sizes))
+	for _, size := range sizes {
+		rendered := render(source, size)
+
+		var buf bytes.Buffer
+		variant := Variant{
+			Name:   size.Name,
+			Width:  rendered.Bounds().Dx(),
+			Height: rendered.Bounds().Dy(),
+		}
+		if format == "jpeg" {
+			err = jpeg.Encode(&buf, rendered, &jpeg.Options{Quality: 85})
+			variant.ContentType, variant.Extension = "image/jpeg", ".jpg"
+		} else {
+			err = png.Encode(&buf, rendered)
+			variant.ContentType, variant.Extension = "image/png", ".png"
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s variant: %w", size.Name, err)
+		}
+		variant.Data = buf.Bytes()
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+// render scales the image to the size, see Size.
+func render(source image.Image, size Size) image.Image {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	crop := bounds
+	targetWidth, targetHeight := width, height
+	if size.Crop {
+		// Potong sisi yang lebih panjang supaya rasio sama dengan ukuran tujuan
+		if width*size.Height > height*size.Width {
+			cropWidth := height * size.Width / size.Height
+			crop.Min.X += (width - cropWidth) / 2
+			crop.Max.X = crop.Min.X + cropWidth
+		} else {
+			cropHeight := width * size.Height / size.Width
+			crop.Min.Y += (height - cropHeight) / 2
+			crop.Max.Y = crop.Min.Y + cropHeight
+		}
+		targetWidth, targetHeight = min(size.Width, crop.Dx()), min(size.Height, crop.Dy())
+	} else if width > size.Width || height > size.Height {
+		if width*size.Height > height*size.Width {
+			targetWidth, targetHeight = size.Width, max(1, height*size.Width/width)
+		} else {
+			targetWidth, targetHeight = max(1, width*size.Height/height), size.Height
+		}
+	}
+
+	target := image.NewNRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	draw.CatmullRom.Scale(target, target.Bounds(), source, crop, draw.Src, nil)
+	return target
+}
@@ -59,6 +59,12 @@ func (l *Logger) Info(message string, context map[string]interface{}) {
 	l.writeLog("INFO", message, context)
 }
 
+// Warn logs the given message at the WARN level with the provided context.
+// The context is stored as structured data in the log entry.
+func (l *Logger) Warn(message string, context map[string]interface{}) {
+	l.writeLog("WARN", message, context)
+}
+
 // Error logs the given message at the ERROR level with the provided context.
 // The context is stored as structured data in the log entry.
 
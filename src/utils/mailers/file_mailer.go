@@ -0,0 +1,34 @@
+package mailers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileMailer writes every message as an .eml file into a folder instead of sending it.
+// It is meant for local development, the files can be opened with any mail client.
+type FileMailer struct {
+	dir string
+}
+
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{dir: dir}
+}
+
+func (m *FileMailer) Send(ctx context.Context, message Message) error {
+	if err := os.MkdirAll(m.dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create mail folder: %w", err)
+	}
+
+	fileName := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102150405"), uuid.New().String())
+	from := os.Getenv("MAIL_FROM")
+	if err := os.WriteFile(filepath.Join(m.dir, fileName), buildMIME(from, message), 0644); err != nil {
+		return fmt.Errorf("failed to write mail file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package mailers
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends an email message. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// NewMailer returns the Mailer selected by the MAIL_DRIVER environment variable:
+//   - smtp: sends the message through the SMTP server configured by MAIL_HOST, MAIL_PORT,
+//     MAIL_USERNAME, MAIL_PASSWORD and MAIL_FROM.
+//   - memory: keeps the message in memory, useful for tests.
+//   - file (default): writes the message as an .eml file into src/storage/mails.
+func NewMailer() Mailer {
+	switch strings.ToLower(os.Getenv("MAIL_DRIVER")) {
+	case "smtp":
+		return NewSMTPMailer(LoadSMTPConfig())
+	case "memory":
+		return NewMemoryMailer()
+	default:
+		return NewFileMailer("src/storage/mails")
+	}
+}
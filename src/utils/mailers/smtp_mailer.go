@@ -0,0 +1,76 @@
+package mailers
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// LoadSMTPConfig reads the SMTP settings from the environment variables.
+// MAIL_PORT defaults to 587 if empty.
+func LoadSMTPConfig() SMTPConfig {
+	port := os.Getenv("MAIL_PORT")
+	if port == "" {
+		port = "587"
+	}
+
+	return SMTPConfig{
+		Host:     os.Getenv("MAIL_HOST"),
+		Port:     port,
+		Username: os.Getenv("MAIL_USERNAME"),
+		Password: os.Getenv("MAIL_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+}
+
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{config: config}
+}
+
+// Send delivers the message through the configured SMTP server using PLAIN auth
+// when a username is configured. STARTTLS is used automatically if the server supports it.
+func (m *SMTPMailer) Send(ctx context.Context, message Message) error {
+	if m.config.Host == "" {
+		return fmt.Errorf("MAIL_HOST is not set")
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := m.config.Host + ":" + m.config.Port
+	if err := smtp.SendMail(addr, auth, m.config.From, []string{message.To}, buildMIME(m.config.From, message)); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", message.To, err)
+	}
+	return nil
+}
+
+// buildMIME formats the message as a plain text MIME email.
+func buildMIME(from string, message Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + message.To + "\r\n")
+	b.WriteString("Subject: " + message.Subject + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(message.Body)
+	return []byte(b.String())
+}
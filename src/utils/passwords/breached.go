@@ -0,0 +1,76 @@
+package passwords
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BreachedList reports whether a password appears in a list of breached passwords.
+type BreachedList interface {
+	Contains(password string) (bool, error)
+}
+
+// FileBreachedList looks passwords up in a local copy of a breached password list, stored as
+// upper case SHA-1 hashes the same way as the Pwned Passwords range API (k-anonymity):
+//
+//   - a directory with one file per 5 character hash prefix (for example "21BD1" or
+//     "21BD1.txt"), each line holding the remaining 35 characters and an optional ":count"
+//   - or a single file with one full hash per line, optionally followed by ":count"
+//
+// With the directory layout only the range file of the prefix is read, the plain password and
+// the full hash never leave the lookup.
+type FileBreachedList struct {
+	Path string
+}
+
+func NewFileBreachedList(path string) *FileBreachedList {
+	return &FileBreachedList{Path: path}
+}
+
+func (l *FileBreachedList) Contains(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	info, err := os.Stat(l.Path)
+	if err != nil {
+		return false, err
+	}
+
+	if !info.IsDir() {
+		return scanHashes(l.Path, hash)
+	}
+
+	for _, name := range []string{prefix, prefix + ".txt"} {
+		found, err := scanHashes(filepath.Join(l.Path, name), suffix)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return found, err
+	}
+	return false, nil
+}
+
+// scanHashes reports whether the file has a line whose hash part equals target.
+func scanHashes(path string, target string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if hash, _, _ := strings.Cut(line, ":"); strings.EqualFold(hash, target) {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,42 @@
+package passwords
+
+import (
+	"gin/src/entities/auth"
+	"gin/src/entities/users"
+	"gin/src/utils/loggers"
+)
+
+// HistoryStore keeps the hashes of the previous passwords of a user, implemented by the auth repository.
+type HistoryStore interface {
+	AddPasswordHistory(userID int64, hashedPassword string, keep int) error
+	FindPasswordHistory(userID int64) ([]auth.PasswordHistory, error)
+}
+
+// ValidateNew checks a password that is about to be set for the user, including reuse of the
+// current password and the recent ones from the password history.
+// The returned error is a helpers.FieldErrors, so it renders as a field-level validation error.
+func (p *Policy) ValidateNew(store HistoryStore, user *users.User, password string) error {
+	previousHashes := []string{user.Password}
+	if history, err := store.FindPasswordHistory(user.ID); err == nil {
+		for _, entry := range history {
+			previousHashes = append(previousHashes, entry.PasswordHash)
+		}
+	}
+
+	return p.Validate(password, Input{
+		Email:          user.Email,
+		Username:       user.Username,
+		PreviousHashes: previousHashes,
+	})
+}
+
+// Remember adds a newly set password hash to the password history, keeping HistorySize entries.
+// Failures are only logged, the password itself has already been changed.
+func (p *Policy) Remember(store HistoryStore, userID int64, hashedPassword string) {
+	if err := store.AddPasswordHistory(userID, hashedPassword, p.HistorySize()); err != nil {
+		loggers.Log.Error("failed to save password history", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+}
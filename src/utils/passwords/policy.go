@@ -0,0 +1,139 @@
+package passwords
+
+import (
+	"fmt"
+	"gin/src/configs/security"
+	"gin/src/helpers"
+	"gin/src/utils/hashers"
+	"gin/src/utils/loggers"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Policy checks new passwords against the configured password policy.
+type Policy struct {
+	config   security.PasswordPolicyConfig
+	breached BreachedList
+}
+
+// Input is what a new password is checked against besides the rules themselves: the email and
+// username of the account and the hashes of its current and previous passwords.
+type Input struct {
+	Email          string
+	Username       string
+	PreviousHashes []string
+}
+
+// NewPolicy creates a policy from the config. The breached password check uses the local list
+// from the config, pass a BreachedList to use another one.
+func NewPolicy(config security.PasswordPolicyConfig, breached ...BreachedList) *Policy {
+	policy := &Policy{config: config}
+	if len(breached) > 0 {
+		policy.breached = breached[0]
+	} else if config.BreachedListPath != "" {
+		policy.breached = NewFileBreachedList(config.BreachedListPath)
+	}
+	return policy
+}
+
+var (
+	defaultPolicy *Policy
+	defaultOnce   sync.Once
+)
+
+// Default returns the shared Policy, configured from the environment on first use.
+func Default() *Policy {
+	defaultOnce.Do(func() {
+		defaultPolicy = NewPolicy(security.LoadPasswordPolicyConfig())
+	})
+	return defaultPolicy
+}
+
+// HistorySize is how many previous passwords are kept to prevent reuse.
+func (p *Policy) HistorySize() int {
+	return p.config.HistorySize
+}
+
+// Validate checks the password and returns helpers.FieldErrors for the "password" field with
+// every rule it breaks, or nil when it is accepted.
+func (p *Policy) Validate(password string, input Input) error {
+	var problems []string
+
+	if len([]rune(password)) < p.config.MinLength {
+		problems = append(problems, fmt.Sprintf("must be at least %d characters", p.config.MinLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.config.RequireUppercase && !hasUpper {
+		problems = append(problems, "must contain an uppercase letter")
+	}
+	if p.config.RequireLowercase && !hasLower {
+		problems = append(problems, "must contain a lowercase letter")
+	}
+	if p.config.RequireDigit && !hasDigit {
+		problems = append(problems, "must contain a digit")
+	}
+	if p.config.RequireSymbol && !hasSymbol {
+		problems = append(problems, "must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+	localPart, _, _ := strings.Cut(strings.ToLower(input.Email), "@")
+	if containsIdentity(lowered, localPart) {
+		problems = append(problems, "must not contain your email")
+	}
+	if containsIdentity(lowered, strings.ToLower(input.Username)) {
+		problems = append(problems, "must not contain your username")
+	}
+
+	if p.reused(password, input.PreviousHashes) {
+		problems = append(problems, "must not be one of your recent passwords")
+	}
+
+	if p.breached != nil {
+		found, err := p.breached.Contains(password)
+		if err != nil {
+			// List yang tidak bisa dibaca tidak boleh memblokir semua perubahan password
+			loggers.Log.Error("failed to check breached password list", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else if found {
+			problems = append(problems, "has appeared in a data breach, choose another one")
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return helpers.FieldErrors{"password": "password " + strings.Join(problems, ", ")}
+}
+
+// containsIdentity ignores very short identities, they would reject too many passwords.
+func containsIdentity(password string, identity string) bool {
+	return len(identity) >= 3 && strings.Contains(password, identity)
+}
+
+func (p *Policy) reused(password string, previousHashes []string) bool {
+	for _, hash := range previousHashes {
+		if hash == "" {
+			continue
+		}
+		if ok, err := hashers.Default().Verify(password, hash); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
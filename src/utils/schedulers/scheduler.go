@@ -0,0 +1,35 @@
+package schedulers
+
+import (
+	"context"
+	"gin/src/utils/loggers"
+	"time"
+)
+
+// Every runs the job in the background once per interval until the returned stop function is
+// called. Errors are written to the log, a failing run does not stop the next ones. Like the
+// throttles the schedule lives in memory, so every instance of the application runs its own.
+func Every(interval time.Duration, name string, job func(ctx context.Context) error) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil {
+					loggers.Log.Error("scheduled job failed", map[string]interface{}{
+						"job":   name,
+						"error": err.Error(),
+					})
+				}
+			}
+		}
+	}()
+
+	return cancel
+}
@@ -0,0 +1,32 @@
+package signers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+)
+
+// signingKey returns the key used to sign links. APP_KEY is used when set,
+// otherwise it falls back to JWT_SECRET.
+func signingKey() []byte {
+	if key := os.Getenv("APP_KEY"); key != "" {
+		return []byte(key)
+	}
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+// Sign returns the hex encoded HMAC-SHA256 signature of the given values.
+// The values are joined with a separator, so ("ab", "c") and ("a", "bc") sign differently.
+func Sign(values ...string) string {
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write([]byte(strings.Join(values, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature matches the given values, using a constant time comparison.
+func Verify(signature string, values ...string) bool {
+	expected := Sign(values...)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
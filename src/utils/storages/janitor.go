@@ -0,0 +1,85 @@
+package storages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gin/src/utils/loggers"
+	"strings"
+	"time"
+)
+
+// ReferenceSource returns the keys of the stored files a table still refers to. A key ending
+// with "/" keeps every file below that prefix, e.g. the chunks of an unfinished upload.
+type ReferenceSource func(ctx context.Context) ([]string, error)
+
+// Janitor removes stored files that no ReferenceSource refers to anymore, such as the
+// variants of a replaced avatar or files left behind by a failed request.
+type Janitor struct {
+	storage Storage
+	grace   time.Duration
+	sources []ReferenceSource
+}
+
+// NewJanitor creates a janitor for the storage. Files modified within the grace period are
+// never removed, the row that refers to them may still be on its way to the database.
+func NewJanitor(storage Storage, grace time.Duration, sources ...ReferenceSource) *Janitor {
+	return &Janitor{storage: storage, grace: grace, sources: sources}
+}
+
+// Run removes the orphaned files. When a source fails nothing is removed, an incomplete list
+// of references would make used files look orphaned. It is meant to run on a schedule.
+func (j *Janitor) Run(ctx context.Context) error {
+	referenced := make(map[string]bool)
+	var prefixes []string
+	for _, source := range j.sources {
+		keys, err := source(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to collect referenced files: %w", err)
+		}
+		for _, key := range keys {
+			if strings.HasSuffix(key, "/") {
+				prefixes = append(prefixes, key)
+				continue
+			}
+			if cleaned, err := CleanKey(key); err == nil {
+				referenced[cleaned] = true
+			}
+		}
+	}
+
+	objects, err := j.storage.List(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-j.grace)
+	removed := 0
+	var errs []error
+	for _, object := range objects {
+		if referenced[object.Key] || hasAnyPrefix(object.Key, prefixes) || object.LastModified.After(cutoff) {
+			continue
+		}
+		if err := j.storage.Delete(ctx, object.Key); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", object.Key, err))
+			continue
+		}
+		removed++
+	}
+
+	if removed > 0 {
+		loggers.Log.Info("removed orphaned files", map[string]interface{}{
+			"count": removed,
+		})
+	}
+	return errors.Join(errs...)
+}
+
+func hasAnyPrefix(key string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package storages
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryObject struct {
+	data   []byte
+	object Object
+}
+
+// MemoryStorage keeps the files in memory. It is meant for tests, the stored keys can be
+// inspected with Keys and cleared with Reset.
+type MemoryStorage struct {
+	mu      sync.Mutex
+	objects map[string]memoryObject
+	baseURL string
+}
+
+func NewMemoryStorage(baseURL string) *MemoryStorage {
+	return &MemoryStorage{objects: make(map[string]memoryObject), baseURL: baseURL}
+}
+
+func (s *MemoryStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	sum := md5.Sum(data)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[cleaned] = memoryObject{
+		data: data,
+		object: Object{
+			Key:          cleaned,
+			Size:         int64(len(data)),
+			ContentType:  contentType,
+			ETag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+			LastModified: time.Now().UTC(),
+		},
+	}
+	return nil
+}
+
+type memoryReader struct {
+	*bytes.Reader
+}
+
+func (memoryReader) Close() error {
+	return nil
+}
+
+func (s *MemoryStorage) Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error) {
+	stored, err := s.lookup(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	object := stored.object
+	return memoryReader{bytes.NewReader(stored.data)}, &object, nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, cleaned)
+	return nil
+}
+
+func (s *MemoryStorage) Stat(ctx context.Context, key string) (*Object, error) {
+	stored, err := s.lookup(key)
+	if err != nil {
+		return nil, err
+	}
+	object := stored.object
+	return &object, nil
+}
+
+func (s *MemoryStorage) List(ctx context.Context, prefix string) ([]Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var objects []Object
+	for key, stored := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, stored.object)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+	return objects, nil
+}
+
+func (s *MemoryStorage) URL(key string) string {
+	return fileURL(s.baseURL, key)
+}
+
+func (s *MemoryStorage) lookup(key string) (memoryObject, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return memoryObject{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.objects[cleaned]
+	if !ok {
+		return memoryObject{}, ErrNotFound
+	}
+	return stored, nil
+}
+
+// Keys returns the stored keys in sorted order.
+func (s *MemoryStorage) Keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Reset removes all stored files.
+func (s *MemoryStorage) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects = make(map[string]memoryObject)
+}
@@ -0,0 +1,260 @@
+package storages
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeS3 is an in-process stand-in for an S3 compatible service, so the S3 driver is tested
+// without network access or MinIO:
+//
+//	server := httptest.NewServer(newFakeS3("access", "secret", "us-east-1"))
+//	storage := NewS3Storage(S3Config{Endpoint: server.URL, PathStyle: true, ...})
+//
+// It supports PUT, GET (with open ended ranges), HEAD and DELETE of objects and
+// ListObjectsV2 with path style addressing, creates buckets on first use and checks the
+// Signature Version 4 of every request like S3 does.
+type fakeS3 struct {
+	accessKey string
+	secretKey string
+	region    string
+	// pageSize caps the keys of a listing page below the S3 limit of 1000 to test paging.
+	pageSize int
+
+	mu      sync.Mutex
+	objects map[string]fakeS3Object // "bucket/key" -> object
+}
+
+type fakeS3Object struct {
+	data         []byte
+	contentType  string
+	etag         string
+	lastModified time.Time
+}
+
+func newFakeS3(accessKey string, secretKey string, region string) *fakeS3 {
+	return &fakeS3{
+		accessKey: accessKey,
+		secretKey: secretKey,
+		region:    region,
+		objects:   make(map[string]fakeS3Object),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeS3Error(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+	if code, message := f.verify(r, body); code != "" {
+		writeS3Error(w, http.StatusForbidden, code, message)
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != "" && key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+		f.list(w, bucket, r.URL.Query())
+		return
+	}
+	if bucket == "" || key == "" {
+		writeS3Error(w, http.StatusBadRequest, "InvalidRequest", "only object requests are supported")
+		return
+	}
+	name := bucket + "/" + key
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		sum := md5.Sum(body)
+		f.objects[name] = fakeS3Object{
+			data:         body,
+			contentType:  r.Header.Get("Content-Type"),
+			etag:         `"` + hex.EncodeToString(sum[:]) + `"`,
+			lastModified: time.Now().UTC().Truncate(time.Second),
+		}
+		w.Header().Set("ETag", f.objects[name].etag)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		object, ok := f.objects[name]
+		if !ok {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		data, status := object.data, http.StatusOK
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("ETag", object.etag)
+		w.Header().Set("Last-Modified", object.lastModified.Format(http.TimeFormat))
+		if start, ok := parseFakeRange(r.Header.Get("Range"), len(data)); ok {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+			data, status = data[start:], http.StatusPartialContent
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.WriteHeader(status)
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeS3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed.")
+	}
+}
+
+// verify recomputes the signature from the request and returns the S3 error code when it
+// does not match, an empty code when the request is authentic.
+func (f *fakeS3) verify(r *http.Request, body []byte) (string, string) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, signatureAlgorithm+" ") {
+		return "AccessDenied", "missing signature"
+	}
+
+	fields := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(authorization, signatureAlgorithm+" "), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		fields[name] = value
+	}
+	accessKey, _, _ := strings.Cut(fields["Credential"], "/")
+	if accessKey != f.accessKey {
+		return "InvalidAccessKeyId", "The AWS Access Key Id you provided does not exist in our records."
+	}
+
+	amzDate := r.Header.Get("X-Amz-Date")
+	signedAt, err := time.Parse(amzDateFormat, amzDate)
+	if err != nil || time.Since(signedAt) > 15*time.Minute || time.Until(signedAt) > 15*time.Minute {
+		return "RequestTimeTooSkewed", "The difference between the request time and the current time is too large."
+	}
+
+	payloadHash := r.Header.Get("X-Amz-Content-Sha256")
+	if payloadHash != unsignedPayload && payloadHash != hashHex(body) {
+		return "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed."
+	}
+
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	headers := map[string]string{}
+	for _, name := range signedHeaders {
+		if name == "host" {
+			headers[name] = r.Host
+			continue
+		}
+		headers[name] = strings.Join(r.Header.Values(name), ",")
+	}
+
+	expected := computeSignature(r.Method, r.URL.Path, r.URL.Query(), headers, signedHeaders, payloadHash, amzDate, f.region, f.secretKey)
+	if !hmac.Equal([]byte(expected), []byte(fields["Signature"])) {
+		return "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."
+	}
+	return "", ""
+}
+
+// parseFakeRange supports the open ended "bytes=<start>-" ranges the S3 driver sends.
+func parseFakeRange(header string, size int) (int, bool) {
+	if !strings.HasPrefix(header, "bytes=") || !strings.HasSuffix(header, "-") {
+		return 0, false
+	}
+	start, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(header, "bytes="), "-"))
+	if err != nil || start < 0 || start >= size {
+		return 0, false
+	}
+	return start, true
+}
+
+// list answers ListObjectsV2. The continuation token is the last key of the previous page.
+func (f *fakeS3) list(w http.ResponseWriter, bucket string, query url.Values) {
+	maxKeys := 1000
+	if value, err := strconv.Atoi(query.Get("max-keys")); err == nil && value > 0 && value < maxKeys {
+		maxKeys = value
+	}
+	if f.pageSize > 0 && f.pageSize < maxKeys {
+		maxKeys = f.pageSize
+	}
+	prefix, after := query.Get("prefix"), query.Get("continuation-token")
+
+	type content struct {
+		Key          string `xml:"Key"`
+		LastModified string `xml:"LastModified"`
+		ETag         string `xml:"ETag"`
+		Size         int    `xml:"Size"`
+	}
+	result := struct {
+		XMLName               xml.Name  `xml:"ListBucketResult"`
+		Name                  string    `xml:"Name"`
+		Prefix                string    `xml:"Prefix"`
+		KeyCount              int       `xml:"KeyCount"`
+		IsTruncated           bool      `xml:"IsTruncated"`
+		NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+		Contents              []content `xml:"Contents"`
+	}{Name: bucket, Prefix: prefix}
+
+	f.mu.Lock()
+	var keys []string
+	for name := range f.objects {
+		if key, ok := strings.CutPrefix(name, bucket+"/"); ok && strings.HasPrefix(key, prefix) && key > after {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if len(keys) > maxKeys {
+		keys = keys[:maxKeys]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		object := f.objects[bucket+"/"+key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: object.lastModified.Format(time.RFC3339),
+			ETag:         object.etag,
+			Size:         len(object.data),
+		})
+	}
+	f.mu.Unlock()
+	result.KeyCount = len(result.Contents)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	xml.NewEncoder(&buf).Encode(result)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// Keys returns the stored "bucket/key" names.
+func (f *fakeS3) Keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.objects))
+	for name := range f.objects {
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string, message string) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	xml.NewEncoder(&buf).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: message})
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
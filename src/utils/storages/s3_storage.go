@@ -0,0 +1,363 @@
+package storages
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+	PublicURL string
+}
+
+// LoadS3Config reads the S3 settings from the environment variables. S3_ENDPOINT defaults to
+// https://s3.<S3_REGION>.amazonaws.com and S3_REGION to us-east-1. Set S3_PATH_STYLE=true for
+// MinIO and other stand-ins that do not support bucket subdomains. S3_PUBLIC_URL is the base
+// URL of the files for clients, for example a CDN; by default the bucket URL is used.
+func LoadS3Config() S3Config {
+	region := os.Getenv("S3_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := os.Getenv("S3_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+
+	return S3Config{
+		Endpoint:  strings.TrimRight(endpoint, "/"),
+		Region:    region,
+		Bucket:    os.Getenv("S3_BUCKET"),
+		AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_SECRET_KEY"),
+		PathStyle: os.Getenv("S3_PATH_STYLE") == "true",
+		PublicURL: os.Getenv("S3_PUBLIC_URL"),
+	}
+}
+
+// S3Storage keeps the files in a bucket of an S3 compatible service. Requests are signed with
+// AWS Signature Version 4, so it works with AWS S3, MinIO and the fake S3 server of the tests alike.
+type S3Storage struct {
+	config S3Config
+	client *http.Client
+}
+
+func NewS3Storage(config S3Config) *S3Storage {
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	return &S3Storage{config: config, client: &http.Client{Timeout: 5 * time.Minute}}
+}
+
+// bucketURL returns the URL of the bucket, as a path of the endpoint or as its subdomain.
+func (s *S3Storage) bucketURL() string {
+	if s.config.PathStyle {
+		return s.config.Endpoint + "/" + s.config.Bucket
+	}
+	endpoint, err := url.Parse(s.config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return s.config.Endpoint + "/" + s.config.Bucket
+	}
+	endpoint.Host = s.config.Bucket + "." + endpoint.Host
+	return strings.TrimRight(endpoint.String(), "/")
+}
+
+func (s *S3Storage) newRequest(ctx context.Context, method string, key string, body io.Reader) (*http.Request, error) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, joinURL(s.bucketURL(), cleaned), body)
+}
+
+// do signs and sends the request. Error responses are turned into errors, 404 into ErrNotFound.
+func (s *S3Storage) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	signRequest(req, payloadHash, s.config.AccessKey, s.config.SecretKey, s.config.Region, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s request failed: %w", req.Method, err)
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(body, &s3Err) == nil && s3Err.Code != "" {
+		return nil, fmt.Errorf("s3 %s request failed with %d %s: %s", req.Method, resp.StatusCode, s3Err.Code, s3Err.Message)
+	}
+	return nil, fmt.Errorf("s3 %s request failed with status %d", req.Method, resp.StatusCode)
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	payload, size, payloadHash, cleanup, err := spool(body)
+	if err != nil {
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+	defer cleanup()
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, io.NopCloser(payload))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req, payloadHash)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	object := objectFromHeaders(key, resp)
+	return &s3Reader{storage: s, ctx: ctx, key: key, size: object.Size, body: resp.Body}, object, nil
+}
+
+// s3Reader reads an object and seeks by requesting the rest of it from the new offset with a
+// Range header. The body is only requested again when the reading position really changed.
+type s3Reader struct {
+	storage    *S3Storage
+	ctx        context.Context
+	key        string
+	size       int64
+	offset     int64
+	body       io.ReadCloser
+	bodyOffset int64
+}
+
+func (r *s3Reader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+	if r.body != nil && r.bodyOffset != r.offset {
+		r.body.Close()
+		r.body = nil
+	}
+	if r.body == nil {
+		req, err := r.storage.newRequest(r.ctx, http.MethodGet, r.key, nil)
+		if err != nil {
+			return 0, err
+		}
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+		resp, err := r.storage.do(req, emptyPayloadHash)
+		if err != nil {
+			return 0, err
+		}
+		if resp.StatusCode != http.StatusPartialContent {
+			resp.Body.Close()
+			return 0, fmt.Errorf("s3 GET request ignored the range, status %d", resp.StatusCode)
+		}
+		r.body, r.bodyOffset = resp.Body, r.offset
+	}
+
+	n, err := r.body.Read(p)
+	r.offset += int64(n)
+	r.bodyOffset += int64(n)
+	return n, err
+}
+
+func (r *s3Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position %d", offset)
+	}
+	r.offset = offset
+	return offset, nil
+}
+
+func (r *s3Reader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	err := r.body.Close()
+	r.body = nil
+	return err
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Stat(ctx context.Context, key string) (*Object, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return objectFromHeaders(key, resp), nil
+}
+
+// List pages through ListObjectsV2 until the listing is no longer truncated.
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.bucketURL()+"/?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req, emptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			IsTruncated           bool   `xml:"IsTruncated"`
+			NextContinuationToken string `xml:"NextContinuationToken"`
+			Contents              []struct {
+				Key          string    `xml:"Key"`
+				Size         int64     `xml:"Size"`
+				ETag         string    `xml:"ETag"`
+				LastModified time.Time `xml:"LastModified"`
+			} `xml:"Contents"`
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read s3 listing: %w", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{
+				Key:          content.Key,
+				Size:         content.Size,
+				ETag:         content.ETag,
+				LastModified: content.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func (s *S3Storage) URL(key string) string {
+	if s.config.PublicURL != "" {
+		return fileURL(s.config.PublicURL, key)
+	}
+	return fileURL(s.bucketURL(), key)
+}
+
+func objectFromHeaders(key string, resp *http.Response) *Object {
+	cleaned, _ := CleanKey(key)
+	size, _ := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	lastModified, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &Object{
+		Key:          cleaned,
+		Size:         size,
+		ContentType:  resp.Header.Get("Content-Type"),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: lastModified,
+	}
+}
+
+// spool returns the body as a seekable reader with its size and SHA-256, which the signature
+// needs before the upload starts. Bodies that cannot seek are copied to a temporary file,
+// the returned cleanup removes it.
+func spool(body io.Reader) (io.ReadSeeker, int64, string, func(), error) {
+	cleanup := func() {}
+	fail := func(err error) (io.ReadSeeker, int64, string, func(), error) {
+		cleanup()
+		return nil, 0, "", func() {}, err
+	}
+
+	seeker, ok := body.(io.ReadSeeker)
+	if !ok {
+		tmp, err := os.CreateTemp("", "s3-upload-*")
+		if err != nil {
+			return fail(err)
+		}
+		cleanup = func() {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+		if _, err := io.Copy(tmp, body); err != nil {
+			return fail(err)
+		}
+		if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+			return fail(err)
+		}
+		seeker = tmp
+	}
+
+	// Body dibaca mulai dari posisinya sekarang, seperti io.Copy biasa
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fail(err)
+	}
+	hash := sha256.New()
+	size, err := io.Copy(hash, seeker)
+	if err != nil {
+		return fail(err)
+	}
+	if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+		return fail(err)
+	}
+	return seeker, size, hex.EncodeToString(hash.Sum(nil)), cleanup, nil
+}
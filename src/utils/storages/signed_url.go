@@ -0,0 +1,61 @@
+package storages
+
+import (
+	"gin/src/configs/security"
+	"gin/src/utils/signers"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrivatePrefix marks the keys of private files. They are only served with a signed link
+// that expires, see SignedURL; URL returns such a link for them.
+const PrivatePrefix = "private/"
+
+// IsPrivate reports whether the key belongs to a private file.
+func IsPrivate(key string) bool {
+	cleaned, err := CleanKey(key)
+	return err == nil && strings.HasPrefix(cleaned, PrivatePrefix)
+}
+
+// SignedURL returns a link to the file on the /files endpoint (STORAGE_URL) that works until
+// the ttl has passed, signed with APP_KEY.
+func SignedURL(key string, ttl time.Duration) string {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return ""
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", signers.Sign("file", cleaned, expires))
+	return joinURL(storageURL(), cleaned) + "?" + query.Encode()
+}
+
+// VerifySignedURL checks the expires and signature parameters of a link made by SignedURL
+// and returns when the link expires.
+func VerifySignedURL(key string, expires string, signature string) (time.Time, bool) {
+	cleaned, err := CleanKey(key)
+	if err != nil {
+		return time.Time{}, false
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return time.Time{}, false
+	}
+	if !signers.Verify(signature, "file", cleaned, expires) {
+		return time.Time{}, false
+	}
+	return time.Unix(expiresAt, 0), true
+}
+
+// fileURL returns the public URL of the key below baseURL, or a signed link to the /files
+// endpoint when the file is private.
+func fileURL(baseURL string, key string) string {
+	if IsPrivate(key) {
+		return SignedURL(key, security.LoadFileConfig().SignedURLTTL)
+	}
+	return joinURL(baseURL, key)
+}
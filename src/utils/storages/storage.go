@@ -0,0 +1,105 @@
+package storages
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Get and Stat when there is no object under the key.
+var ErrNotFound = errors.New("file not found")
+
+// Object describes a stored file.
+type Object struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// Storage keeps uploaded files under slash separated keys such as "avatars/<uuid>-small.jpg".
+// Implementations must be safe for concurrent use.
+type Storage interface {
+	// Put stores the content under the key, replacing an existing object.
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Get opens the object, the caller closes the reader. The reader can seek, so a part of
+	// the object can be read without downloading all of it.
+	Get(ctx context.Context, key string) (io.ReadSeekCloser, *Object, error)
+	// Delete removes the object, a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// Stat returns the metadata of the object without reading it.
+	Stat(ctx context.Context, key string) (*Object, error)
+	// List returns the objects whose key starts with the prefix, sorted by key. An empty
+	// prefix lists every object.
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// URL returns the address clients fetch the object from, a signed link for private files.
+	URL(key string) string
+}
+
+// NewStorage returns the Storage selected by the STORAGE_DRIVER environment variable:
+//   - s3: an S3 compatible bucket (AWS S3, MinIO, ...) configured by the S3_* variables.
+//   - memory: keeps the files in memory, useful for tests.
+//   - local (default): writes the files into src/storage/files, served under STORAGE_URL (default /files).
+func NewStorage() Storage {
+	switch strings.ToLower(os.Getenv("STORAGE_DRIVER")) {
+	case "s3":
+		return NewS3Storage(LoadS3Config())
+	case "memory":
+		return NewMemoryStorage(storageURL())
+	default:
+		return NewLocalStorage(LocalPath, storageURL())
+	}
+}
+
+var (
+	defaultStorage Storage
+	defaultOnce    sync.Once
+)
+
+// Default returns the shared Storage, created from the environment on first use.
+func Default() Storage {
+	defaultOnce.Do(func() {
+		defaultStorage = NewStorage()
+	})
+	return defaultStorage
+}
+
+// LocalPath is the folder the local driver stores files in.
+const LocalPath = "src/storage/files"
+
+func storageURL() string {
+	baseURL := os.Getenv("STORAGE_URL")
+	if baseURL == "" {
+		baseURL = "/files"
+	}
+	return baseURL
+}
+
+// CleanKey validates a key and returns it in canonical form. Keys are relative, slash
+// separated and may not leave the storage root, so "../.env" or "/etc/passwd" are refused.
+func CleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid storage key: %q", key)
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("invalid storage key: %q", key)
+	}
+	return cleaned, nil
+}
+
+// joinURL appends the escaped key to the base URL.
+func joinURL(baseURL string, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = escapePathSegment(segment)
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.Join(segments, "/")
+}
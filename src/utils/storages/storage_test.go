@@ -0,0 +1,217 @@
+package storages
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// drivers returns a fresh instance of every driver, the S3 driver talks to a fakeS3 server.
+func drivers(t *testing.T) map[string]Storage {
+	t.Helper()
+
+	fake := newFakeS3("access", "secret", "us-east-1")
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return map[string]Storage{
+		"local":  NewLocalStorage(t.TempDir(), "/files"),
+		"memory": NewMemoryStorage("/files"),
+		"s3": NewS3Storage(S3Config{
+			Endpoint:  server.URL,
+			Region:    "us-east-1",
+			Bucket:    "uploads",
+			AccessKey: "access",
+			SecretKey: "secret",
+			PathStyle: true,
+		}),
+	}
+}
+
+func put(t *testing.T, storage Storage, key string, content string) {
+	t.Helper()
+	if err := storage.Put(context.Background(), key, strings.NewReader(content), "text/plain"); err != nil {
+		t.Fatalf("Put %q failed: %v", key, err)
+	}
+}
+
+func TestStoragePutGet(t *testing.T) {
+	for name, storage := range drivers(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			put(t, storage, "docs/readme.txt", "hello world")
+
+			reader, object, err := storage.Get(ctx, "docs/readme.txt")
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			defer reader.Close()
+
+			data, err := io.ReadAll(reader)
+			if err != nil || string(data) != "hello world" {
+				t.Fatalf("expected %q, got %q (%v)", "hello world", data, err)
+			}
+			if object.Key != "docs/readme.txt" || object.Size != 11 || object.ETag == "" {
+				t.Errorf("unexpected object %+v", object)
+			}
+
+			// Isi lama diganti oleh Put berikutnya
+			put(t, storage, "docs/readme.txt", "replaced")
+			replaced, _, err := storage.Get(ctx, "docs/readme.txt")
+			if err != nil {
+				t.Fatalf("Get after replace failed: %v", err)
+			}
+			defer replaced.Close()
+			if data, _ := io.ReadAll(replaced); string(data) != "replaced" {
+				t.Errorf("expected the replaced content, got %q", data)
+			}
+		})
+	}
+}
+
+func TestStorageGetSeek(t *testing.T) {
+	for name, storage := range drivers(t) {
+		t.Run(name, func(t *testing.T) {
+			put(t, storage, "video.bin", "0123456789")
+
+			reader, _, err := storage.Get(context.Background(), "video.bin")
+			if err != nil {
+				t.Fatalf("Get failed: %v", err)
+			}
+			defer reader.Close()
+
+			steps := []struct {
+				offset int64
+				whence int
+				read   int
+				want   string
+			}{
+				{offset: 6, whence: io.SeekStart, read: 2, want: "67"},
+				{offset: -4, whence: io.SeekCurrent, read: 3, want: "456"},
+				{offset: -2, whence: io.SeekEnd, read: 10, want: "89"},
+				{offset: 0, whence: io.SeekStart, read: 10, want: "0123456789"},
+			}
+			for _, step := range steps {
+				if _, err := reader.Seek(step.offset, step.whence); err != nil {
+					t.Fatalf("Seek(%d, %d) failed: %v", step.offset, step.whence, err)
+				}
+				data, err := io.ReadAll(io.LimitReader(reader, int64(step.read)))
+				if err != nil || string(data) != step.want {
+					t.Errorf("after Seek(%d, %d) expected %q, got %q (%v)", step.offset, step.whence, step.want, data, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStorageStatDelete(t *testing.T) {
+	for name, storage := range drivers(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			put(t, storage, "avatars/a.txt", "avatar")
+
+			object, err := storage.Stat(ctx, "avatars/a.txt")
+			if err != nil {
+				t.Fatalf("Stat failed: %v", err)
+			}
+			if object.Size != 6 || object.ETag == "" || object.LastModified.IsZero() {
+				t.Errorf("unexpected object %+v", object)
+			}
+
+			if err := storage.Delete(ctx, "avatars/a.txt"); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if _, err := storage.Stat(ctx, "avatars/a.txt"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Stat after Delete: expected ErrNotFound, got %v", err)
+			}
+			if _, _, err := storage.Get(ctx, "avatars/a.txt"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get after Delete: expected ErrNotFound, got %v", err)
+			}
+			if err := storage.Delete(ctx, "avatars/a.txt"); err != nil {
+				t.Errorf("deleting a missing object should not fail, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStorageList(t *testing.T) {
+	for name, storage := range drivers(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"docs/b.txt", "docs/a.txt", "docs/nested/c.txt", "other/d.txt"} {
+				put(t, storage, key, key)
+			}
+
+			objects, err := storage.List(context.Background(), "docs/")
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			var keys []string
+			for _, object := range objects {
+				keys = append(keys, object.Key)
+			}
+			want := []string{"docs/a.txt", "docs/b.txt", "docs/nested/c.txt"}
+			if strings.Join(keys, ",") != strings.Join(want, ",") {
+				t.Errorf("expected %v, got %v", want, keys)
+			}
+
+			all, err := storage.List(context.Background(), "")
+			if err != nil || len(all) != 4 {
+				t.Errorf("expected 4 objects without prefix, got %d (%v)", len(all), err)
+			}
+		})
+	}
+}
+
+func TestStorageRefusesInvalidKeys(t *testing.T) {
+	for name, storage := range drivers(t) {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"", "../.env", "/etc/passwd", "a/../../b", `a\b`} {
+				if err := storage.Put(context.Background(), key, strings.NewReader("x"), ""); err == nil {
+					t.Errorf("Put %q should be refused", key)
+				}
+			}
+		})
+	}
+}
+
+func TestS3StorageListPages(t *testing.T) {
+	fake := newFakeS3("access", "secret", "us-east-1")
+	fake.pageSize = 2
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	storage := NewS3Storage(S3Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "uploads", AccessKey: "access", SecretKey: "secret", PathStyle: true})
+	for _, key := range []string{"e.txt", "a.txt", "d.txt", "b.txt", "c.txt"} {
+		put(t, storage, key, key)
+	}
+
+	objects, err := storage.List(context.Background(), "")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	if !sort.StringsAreSorted(keys) || len(keys) != 5 {
+		t.Errorf("expected all 5 keys over several pages, got %v", keys)
+	}
+	if stored := fake.Keys(); len(stored) != 5 {
+		t.Errorf("expected 5 stored objects, got %v", stored)
+	}
+}
+
+func TestS3StorageRejectsWrongCredentials(t *testing.T) {
+	server := httptest.NewServer(newFakeS3("access", "secret", "us-east-1"))
+	defer server.Close()
+
+	storage := NewS3Storage(S3Config{Endpoint: server.URL, Region: "us-east-1", Bucket: "uploads", AccessKey: "access", SecretKey: "wrong", PathStyle: true})
+	err := storage.Put(context.Background(), "a.txt", strings.NewReader("x"), "")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("expected SignatureDoesNotMatch, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package tenants
+
+import "context"
+
+// Column marks a table as owned by an organization, the tenants of the application. The generic
+// helpers scope every query on such a table to a single organization: the one of the request
+// context, or one named with helpers.ForOrganization.
+const Column = "organization_id"
+
+type contextKey struct{}
+
+// WithOrganization returns a copy of ctx that carries the current organization of the request.
+func WithOrganization(ctx context.Context, organizationID int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, organizationID)
+}
+
+// FromContext returns the organization stored by WithOrganization and whether there is one.
+func FromContext(ctx context.Context) (int64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	organizationID, ok := ctx.Value(contextKey{}).(int64)
+	return organizationID, ok && organizationID != 0
+}
@@ -0,0 +1,52 @@
+package throttles
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle allows one action per key within the given interval, for example one
+// verification email per address per minute. State is kept in memory, so limits are
+// per instance and reset when the application restarts.
+type Throttle struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+func NewThrottle(interval time.Duration) *Throttle {
+	return &Throttle{
+		interval: interval,
+		last:     make(map[string]time.Time),
+	}
+}
+
+// Allow reports whether the action for the key may run now and records it if so.
+// When it is not allowed, the remaining wait time is returned.
+func (t *Throttle) Allow(key string) (bool, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := t.last[key]; ok {
+		if wait := t.interval - now.Sub(last); wait > 0 {
+			return false, wait
+		}
+	}
+
+	t.last[key] = now
+	t.cleanup(now)
+	return true, 0
+}
+
+// cleanup removes keys whose interval has passed so the map does not grow forever.
+func (t *Throttle) cleanup(now time.Time) {
+	if len(t.last) < 1024 {
+		return
+	}
+	for key, last := range t.last {
+		if now.Sub(last) >= t.interval {
+			delete(t.last, key)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package useragents
+
+import "strings"
+
+type uaToken struct {
+	match string
+	name  string
+}
+
+// Urutan penting: token yang lebih spesifik harus dicek lebih dulu,
+// contoh Edge dan Opera juga mengandung "Chrome", Chrome juga mengandung "Safari".
+var browsers = []uaToken{
+	{"Edg", "Edge"},
+	{"OPR", "Opera"},
+	{"Firefox", "Firefox"},
+	{"Chrome", "Chrome"},
+	{"Safari", "Safari"},
+	{"okhttp", "Android App"},
+	{"Dart", "Mobile App"},
+	{"PostmanRuntime", "Postman"},
+	{"curl", "curl"},
+}
+
+var platforms = []uaToken{
+	{"iPhone", "iPhone"},
+	{"iPad", "iPad"},
+	{"Android", "Android"},
+	{"Windows", "Windows"},
+	{"Macintosh", "macOS"},
+	{"CrOS", "ChromeOS"},
+	{"Linux", "Linux"},
+}
+
+// DeviceName returns an approximate, human readable device name for the given
+// User-Agent header, for example "Chrome on Windows" or "Safari on iPhone".
+// It returns "Unknown device" when nothing in the header is recognised.
+func DeviceName(userAgent string) string {
+	browser := findToken(userAgent, browsers)
+	platform := findToken(userAgent, platforms)
+
+	switch {
+	case browser != "" && platform != "":
+		return browser + " on " + platform
+	case browser != "":
+		return browser
+	case platform != "":
+		return platform
+	default:
+		return "Unknown device"
+	}
+}
+
+func findToken(userAgent string, tokens []uaToken) string {
+	for _, t := range tokens {
+		if strings.Contains(userAgent, t.match) {
+			return t.name
+		}
+	}
+	return ""
+}